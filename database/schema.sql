@@ -9,6 +9,9 @@ CREATE TABLE IF NOT EXISTS users (
     full_name VARCHAR(100) NOT NULL,
     role_id UUID NOT NULL,
     is_active BOOLEAN DEFAULT true,
+    must_change_password BOOLEAN DEFAULT false,
+    auth_source VARCHAR(20), -- NULL = ikut roles.auth_source
+    permission_version INTEGER NOT NULL DEFAULT 1, -- Naik saat role_id / is_active berubah
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
@@ -18,6 +21,9 @@ CREATE TABLE IF NOT EXISTS roles (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(50) UNIQUE NOT NULL,
     description TEXT,
+    mfa_required BOOLEAN DEFAULT false,
+    auth_source VARCHAR(20) NOT NULL DEFAULT 'local',
+    permission_version INTEGER NOT NULL DEFAULT 1, -- Naik saat role_permissions role ini berubah
     created_at TIMESTAMP DEFAULT NOW()
 );
 
@@ -62,7 +68,7 @@ CREATE TABLE IF NOT EXISTS achievement_references (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
     mongo_achievement_id VARCHAR(24) NOT NULL,
-    status VARCHAR(20) CHECK (status IN ('draft', 'submitted', 'verified', 'rejected')) DEFAULT 'draft',
+    status VARCHAR(20) DEFAULT 'draft', -- foreign key ke achievement_statuses (3.1.31)
     submitted_at TIMESTAMP,
     verified_at TIMESTAMP,
     verified_by UUID REFERENCES users(id),
@@ -90,6 +96,284 @@ CREATE TABLE IF NOT EXISTS notifications (
     created_at TIMESTAMP DEFAULT NOW()
 );
 
+-- 3.1.9 Tabel refresh_tokens
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP,
+    replaced_by UUID,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.10 Tabel revoked_tokens (revocation list access token berdasarkan jti)
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.11 Tabel user_token_revocations (semua token user yang terbit sebelum revoked_before tidak berlaku)
+CREATE TABLE IF NOT EXISTS user_token_revocations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    revoked_before TIMESTAMP NOT NULL
+);
+
+-- 3.1.12 Tabel password_reset_tokens (token reset password sekali pakai, hanya hash yang disimpan)
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.13 Tabel mail_outbox (antrian email, dikirim oleh worker lewat mailer)
+CREATE TABLE IF NOT EXISTS mail_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.14 Tabel login_attempts (percobaan login gagal per akun dan per IP untuk delay progresif dan lockout)
+CREATE TABLE IF NOT EXISTS login_attempts (
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('account', 'ip')),
+    key VARCHAR(255) NOT NULL,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL,
+    next_attempt_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+-- 3.1.15 Tabel user_mfa (secret TOTP per user, aktif setelah dikonfirmasi dengan kode pertama)
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.16 Tabel mfa_recovery_codes (recovery code sekali pakai, hanya hash yang disimpan)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.17 Tabel mfa_challenges (langkah kedua login setelah password benar)
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('verify', 'enroll')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.18 Tabel user_sessions (satu baris per login, id = family_id refresh token)
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_seen_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+-- 3.1.19 Tabel password_history (hash password terakhir untuk mencegah pemakaian ulang)
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.20 Tabel email_change_tokens (ganti email setelah dikonfirmasi di alamat baru)
+CREATE TABLE IF NOT EXISTS email_change_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    new_email VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.21 Tabel personal_access_tokens (token API untuk script / integrasi, hanya hash yang disimpan)
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.22 Tabel oidc_auth_requests (state, nonce, dan PKCE verifier login SSO, dipakai sekali saat callback)
+CREATE TABLE IF NOT EXISTS oidc_auth_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    state_hash VARCHAR(64) UNIQUE NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.23 Tabel user_identities (akun identity provider SSO yang terhubung ke user)
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_login_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (issuer, subject)
+);
+
+-- 3.1.24 Tabel impersonation_audit_log (jejak "view as user" oleh admin, tanpa foreign key supaya tetap ada walaupun user dihapus)
+CREATE TABLE IF NOT EXISTS impersonation_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    token_id VARCHAR(64) NOT NULL, -- jti token impersonation
+    event VARCHAR(20) NOT NULL, -- start, request, blocked
+    method VARCHAR(10) NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.25 Tabel user_invitations (undangan aktivasi akun, satu per user; user tetap nonaktif sampai undangan dipakai)
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL, -- diganti setiap undangan dikirim ulang
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 3.1.26 Tabel user_roles (semua role user; users.role_id adalah role utama dan selalu ikut tercatat di sini)
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- 3.1.27 Tabel verification_delegations (dosen wali melimpahkan hak verifikasi ke dosen lain untuk rentang waktu tertentu)
+CREATE TABLE IF NOT EXISTS verification_delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delegator_id UUID NOT NULL REFERENCES lecturers(id) ON DELETE CASCADE,
+    delegate_id UUID NOT NULL REFERENCES lecturers(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (delegator_id <> delegate_id),
+    CHECK (ends_at > starts_at)
+);
+
+-- 3.1.28 Tabel verification_delegation_students (subset mahasiswa bimbingan; tanpa baris = semua mahasiswa bimbingan)
+CREATE TABLE IF NOT EXISTS verification_delegation_students (
+    delegation_id UUID NOT NULL REFERENCES verification_delegations(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    PRIMARY KEY (delegation_id, student_id)
+);
+
+-- 3.1.29 Tabel admin_scopes (admin ter-scope hanya mengelola program studi tertentu; admin tanpa baris = admin global)
+CREATE TABLE IF NOT EXISTS admin_scopes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    program_study VARCHAR(100) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, program_study)
+);
+
+-- Keputusan verifikasi yang diambil dosen pengganti mencatat delegasi yang dipakai
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS delegation_id UUID REFERENCES verification_delegations(id) ON DELETE SET NULL;
+
+-- 3.1.30 Tabel achievement_status_history (setiap perubahan status prestasi, ditulis dalam transaksi yang sama dengan perubahannya)
+-- from_status NULL = prestasi baru dibuat, to_status 'deleted' = soft delete. actor_user_id NULL = data migrasi.
+CREATE TABLE IF NOT EXISTS achievement_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    actor_role VARCHAR(50) NOT NULL,
+    note TEXT,
+    delegation_id UUID REFERENCES verification_delegations(id) ON DELETE SET NULL, -- Delegasi yang dipakai dosen pengganti
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Migrasi: prestasi yang sudah ada sebelum riwayat status dicatat
+INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, actor_role, note, created_at)
+SELECT ar.id, NULL, 'draft', 'system', 'Recorded before status history was available', ar.created_at
+FROM achievement_references ar
+WHERE NOT EXISTS (SELECT 1 FROM achievement_status_history h WHERE h.achievement_ref_id = ar.id);
+
+INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, actor_role, note, created_at)
+SELECT ar.id, 'draft', ar.status, 'system', ar.rejection_note, COALESCE(ar.verified_at, ar.submitted_at, ar.updated_at)
+FROM achievement_references ar
+WHERE ar.status <> 'draft'
+  AND NOT EXISTS (SELECT 1 FROM achievement_status_history h WHERE h.achievement_ref_id = ar.id AND h.from_status IS NOT NULL);
+
+-- 3.1.31 Tabel achievement_statuses (status prestasi yang valid; transisi antar status diatur state machine di package workflow)
+-- Status baru (mis. needs_revision, under_review) cukup ditambah sebagai baris di sini dan di workflow.Achievement
+CREATE TABLE IF NOT EXISTS achievement_statuses (
+    name VARCHAR(20) PRIMARY KEY,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+INSERT INTO achievement_statuses (name, description) VALUES
+('draft', 'Disimpan mahasiswa, belum diajukan'),
+('submitted', 'Diajukan, menunggu verifikasi dosen wali'),
+('verified', 'Diverifikasi dosen wali'),
+('rejected', 'Ditolak dosen wali, bisa diperbaiki lalu diajukan ulang')
+ON CONFLICT (name) DO NOTHING;
+
+-- Migrasi: CHECK constraint status lama diganti foreign key ke achievement_statuses
+ALTER TABLE achievement_references DROP CONSTRAINT IF EXISTS achievement_references_status_check;
+ALTER TABLE achievement_references DROP CONSTRAINT IF EXISTS fk_achievement_references_status;
+ALTER TABLE achievement_references ADD CONSTRAINT fk_achievement_references_status
+    FOREIGN KEY (status) REFERENCES achievement_statuses(name);
+
 -- Create indexes for better performance
 CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
@@ -108,3 +392,96 @@ CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permission
 CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
 CREATE INDEX IF NOT EXISTS idx_notifications_is_read ON notifications(is_read);
 CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_pending ON mail_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failed_at ON login_attempts(last_failed_at);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires_at ON mfa_challenges(expires_at);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_change_tokens_user_id ON email_change_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_oidc_auth_requests_expires_at ON oidc_auth_requests(expires_at);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_log_admin_id ON impersonation_audit_log(admin_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_log_user_id ON impersonation_audit_log(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles(role_id);
+CREATE INDEX IF NOT EXISTS idx_verification_delegations_delegate_id ON verification_delegations(delegate_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_verification_delegations_delegator_id ON verification_delegations(delegator_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_students_program_study ON students(LOWER(program_study));
+CREATE INDEX IF NOT EXISTS idx_lecturers_department ON lecturers(LOWER(department));
+CREATE INDEX IF NOT EXISTS idx_achievement_status_history_ref_id ON achievement_status_history(achievement_ref_id, created_at);
+
+-- Versi permission: access token menyimpan versi user dan role saat diterbitkan, middleware
+-- me-resolve ulang permissions jika versinya sudah berubah (termasuk perubahan lewat SQL manual)
+CREATE OR REPLACE FUNCTION bump_role_permission_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE roles SET permission_version = permission_version + 1 WHERE id = NEW.role_id;
+    END IF;
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE roles SET permission_version = permission_version + 1 WHERE id = OLD.role_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_role_permissions_version ON role_permissions;
+CREATE TRIGGER trg_role_permissions_version
+    AFTER INSERT OR UPDATE OR DELETE ON role_permissions
+    FOR EACH ROW EXECUTE FUNCTION bump_role_permission_version();
+
+CREATE OR REPLACE FUNCTION bump_user_permission_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.role_id IS DISTINCT FROM OLD.role_id OR NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        NEW.permission_version := OLD.permission_version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_users_permission_version ON users;
+CREATE TRIGGER trg_users_permission_version
+    BEFORE UPDATE OF role_id, is_active ON users
+    FOR EACH ROW EXECUTE FUNCTION bump_user_permission_version();
+
+-- Role utama (users.role_id) selalu ada di user_roles: ganti role utama memindahkan entry-nya
+CREATE OR REPLACE FUNCTION sync_primary_user_role() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.role_id IS DISTINCT FROM OLD.role_id THEN
+        DELETE FROM user_roles WHERE user_id = OLD.id AND role_id = OLD.role_id;
+    END IF;
+    INSERT INTO user_roles (user_id, role_id) VALUES (NEW.id, NEW.role_id) ON CONFLICT DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_users_primary_role ON users;
+CREATE TRIGGER trg_users_primary_role
+    AFTER INSERT OR UPDATE OF role_id ON users
+    FOR EACH ROW EXECUTE FUNCTION sync_primary_user_role();
+
+-- Tambah/lepas role tambahan menaikkan versi permission user
+CREATE OR REPLACE FUNCTION bump_user_roles_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET permission_version = permission_version + 1 WHERE id = NEW.user_id;
+    ELSE
+        UPDATE users SET permission_version = permission_version + 1 WHERE id = OLD.user_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_user_roles_version ON user_roles;
+CREATE TRIGGER trg_user_roles_version
+    AFTER INSERT OR DELETE ON user_roles
+    FOR EACH ROW EXECUTE FUNCTION bump_user_roles_version();
+
+-- Migrasi: role utama user yang sudah ada sebelum user_roles dibuat
+INSERT INTO user_roles (user_id, role_id)
+SELECT id, role_id FROM users
+ON CONFLICT DO NOTHING;
@@ -1,10 +1,11 @@
 -- Seed data untuk testing
 
 -- Insert roles
-INSERT INTO roles (id, name, description) VALUES
-    ('550e8400-e29b-41d4-a716-446655440001', 'admin', 'Administrator dengan akses penuh'),
-    ('550e8400-e29b-41d4-a716-446655440002', 'lecturer', 'Dosen yang dapat mengelola mahasiswa'),
-    ('550e8400-e29b-41d4-a716-446655440003', 'student', 'Mahasiswa dengan akses terbatas')
+-- admin dan lecturer wajib MFA (TOTP), enrollment diminta saat login pertama
+INSERT INTO roles (id, name, description, mfa_required) VALUES
+    ('550e8400-e29b-41d4-a716-446655440001', 'admin', 'Administrator dengan akses penuh', true),
+    ('550e8400-e29b-41d4-a716-446655440002', 'lecturer', 'Dosen yang dapat mengelola mahasiswa', true),
+    ('550e8400-e29b-41d4-a716-446655440003', 'student', 'Mahasiswa dengan akses terbatas', false)
 ON CONFLICT (name) DO NOTHING;
 
 -- Insert permissions
@@ -18,21 +19,13 @@ INSERT INTO permissions (id, name, resource, action, description) VALUES
     ('660e8400-e29b-41d4-a716-446655440007', 'lecturer.write', 'lecturer', 'write', 'Menulis data dosen'),
     ('660e8400-e29b-41d4-a716-446655440008', 'achievement.read', 'achievement', 'read', 'Membaca data prestasi'),
     ('660e8400-e29b-41d4-a716-446655440009', 'achievement.write', 'achievement', 'write', 'Menulis data prestasi'),
-    ('660e8400-e29b-41d4-a716-446655440010', 'achievement.verify', 'achievement', 'verify', 'Verifikasi prestasi')
+    ('660e8400-e29b-41d4-a716-446655440010', 'achievement.verify', 'achievement', 'verify', 'Verifikasi prestasi'),
+    ('660e8400-e29b-41d4-a716-446655440011', '*.*', '*', '*', 'Semua permission (wildcard)')
 ON CONFLICT (name) DO NOTHING;
 
--- Assign permissions to admin role (all permissions)
+-- Assign permissions to admin role (wildcard "*.*" mencakup semua permission, termasuk yang ditambah kemudian)
 INSERT INTO role_permissions (role_id, permission_id) VALUES
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440001'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440002'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440003'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440004'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440005'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440006'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440007'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440008'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440009'),
-    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440010')
+    ('550e8400-e29b-41d4-a716-446655440001', '660e8400-e29b-41d4-a716-446655440011')
 ON CONFLICT DO NOTHING;
 
 -- Assign permissions to lecturer role
@@ -49,7 +42,8 @@ INSERT INTO role_permissions (role_id, permission_id) VALUES
 ON CONFLICT DO NOTHING;
 
 -- Insert test users (password: "password123" untuk semua)
-INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active) VALUES
+-- Password seed lemah dan ada di daftar password bocor, jadi wajib diganti saat login pertama
+INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, must_change_password) VALUES
     (
         '770e8400-e29b-41d4-a716-446655440001',
         'admin',
@@ -57,6 +51,7 @@ INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_ac
         '$2a$10$xhkEhXN/7qZlAF.H368dXeO9sNgJzBpGn3MUDlUB1JqZxhCeo2v9W',
         'Administrator',
         '550e8400-e29b-41d4-a716-446655440001',
+        true,
         true
     ),
     (
@@ -66,6 +61,7 @@ INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_ac
         '$2a$10$xhkEhXN/7qZlAF.H368dXeO9sNgJzBpGn3MUDlUB1JqZxhCeo2v9W',
         'Dr. John Doe',
         '550e8400-e29b-41d4-a716-446655440002',
+        true,
         true
     ),
     (
@@ -75,6 +71,7 @@ INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_ac
         '$2a$10$xhkEhXN/7qZlAF.H368dXeO9sNgJzBpGn3MUDlUB1JqZxhCeo2v9W',
         'Jane Smith',
         '550e8400-e29b-41d4-a716-446655440003',
+        true,
         true
     )
 ON CONFLICT (username) DO NOTHING;
@@ -5,13 +5,49 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	_ "github.com/lib/pq"
 )
 
 type Config struct {
-	DB        *sql.DB
-	SecretKey string
+	DB                 *sql.DB
+	JWTKeysDir         string // Direktori private/public key JWT (<kid>.pem / <kid>.pub.pem)
+	JWTActiveKID       string // kid untuk signing token baru, kosong = kid terbaru
+	SMTPHost           string // Kosong = email hanya ditulis ke log
+	SMTPPort           string
+	SMTPUsername       string // Kosong = tanpa AUTH (mis. MailHog/Mailpit lokal)
+	SMTPPassword       string
+	MailFrom           string
+	MailLogBody        bool   // Tulis body email (berisi token) ke log saat SMTP_HOST kosong, hanya untuk development
+	PasswordResetURL   string // Halaman reset password di frontend
+	LoginMaxFailures   int    // Gagal login per akun sebelum akun dikunci sementara
+	LoginMaxIPFails    int    // Gagal login per IP sebelum IP dikunci sementara
+	LoginLockMinutes   int    // Lama lockout
+	MFAIssuer          string // Nama issuer yang tampil di authenticator app
+	PasswordMinLen     int    // Panjang minimal password
+	PasswordClasses    int    // Jumlah minimal jenis karakter (huruf kecil, huruf besar, angka, simbol)
+	PasswordHistory    int    // Jumlah password terakhir yang tidak boleh dipakai ulang
+	BreachedPwdDir     string // Folder daftar hash prefix password bocor (format k-anonymity HIBP), kosong = nonaktif
+	EmailChangeURL     string // Halaman konfirmasi ganti email di frontend
+	InvitationURL      string // Halaman aktivasi akun dari undangan di frontend
+	InvitationTTLHours int    // Masa berlaku link undangan
+	OIDCIssuerURL      string // Issuer identity provider SSO kampus, kosong = login SSO nonaktif
+	OIDCClientID       string
+	OIDCClientSecret   string // Kosong untuk public client (hanya PKCE)
+	OIDCRedirectURL    string // Harus sama dengan redirect URI yang terdaftar di identity provider
+	OIDCScopes         string // Dipisah spasi
+	OIDCDefaultRole    string // Role untuk user baru dari SSO
+	OIDCProvision      bool   // Buat user baru jika belum ada akun dengan email yang sama
+	LDAPURL            string // ldap://host:389 atau ldaps://host:636, kosong = backend LDAP nonaktif
+	LDAPStartTLS       bool
+	LDAPBindDN         string // Service account untuk mencari DN user
+	LDAPBindPassword   string
+	LDAPBaseDN         string
+	LDAPUserFilter     string // %s diganti username
+	LDAPFullNameAttr   string
+	LDAPEmailAttr      string
+	LDAPLecturerIDAttr string
 }
 
 func LoadConfig() (*Config, error) {
@@ -37,8 +73,43 @@ func LoadConfig() (*Config, error) {
 	log.Println("Database connected successfully")
 
 	return &Config{
-		DB:        db,
-		SecretKey: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		DB:                 db,
+		JWTKeysDir:         getEnv("JWT_KEYS_DIR", "./keys"),
+		JWTActiveKID:       getEnv("JWT_ACTIVE_KID", ""),
+		SMTPHost:           getEnv("SMTP_HOST", ""),
+		SMTPPort:           getEnv("SMTP_PORT", "1025"),
+		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
+		MailFrom:           getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailLogBody:        getEnvBool("MAIL_LOG_BODY", false),
+		PasswordResetURL:   getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		LoginMaxFailures:   getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginMaxIPFails:    getEnvInt("LOGIN_MAX_IP_FAILURES", 50),
+		LoginLockMinutes:   getEnvInt("LOGIN_LOCK_MINUTES", 15),
+		MFAIssuer:          getEnv("MFA_ISSUER", "UAS Backend"),
+		PasswordMinLen:     getEnvInt("PASSWORD_MIN_LENGTH", 10),
+		PasswordClasses:    getEnvInt("PASSWORD_MIN_CHAR_CLASSES", 3),
+		PasswordHistory:    getEnvInt("PASSWORD_HISTORY", 5),
+		BreachedPwdDir:     getEnv("BREACHED_PASSWORDS_DIR", "./data/breached-passwords"),
+		EmailChangeURL:     getEnv("EMAIL_CHANGE_URL", "http://localhost:3000/confirm-email"),
+		InvitationURL:      getEnv("INVITATION_URL", "http://localhost:3000/accept-invite"),
+		InvitationTTLHours: getEnvInt("INVITATION_TTL_HOURS", 72),
+		OIDCIssuerURL:      getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:       getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:   getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:    getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+		OIDCScopes:         getEnv("OIDC_SCOPES", "openid email profile"),
+		OIDCDefaultRole:    getEnv("OIDC_DEFAULT_ROLE", "student"),
+		OIDCProvision:      getEnvBool("OIDC_AUTO_PROVISION", true),
+		LDAPURL:            getEnv("LDAP_URL", ""),
+		LDAPStartTLS:       getEnvBool("LDAP_START_TLS", false),
+		LDAPBindDN:         getEnv("LDAP_BIND_DN", ""),
+		LDAPBindPassword:   getEnv("LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:         getEnv("LDAP_BASE_DN", ""),
+		LDAPUserFilter:     getEnv("LDAP_USER_FILTER", "(uid=%s)"),
+		LDAPFullNameAttr:   getEnv("LDAP_FULL_NAME_ATTR", "cn"),
+		LDAPEmailAttr:      getEnv("LDAP_EMAIL_ATTR", "mail"),
+		LDAPLecturerIDAttr: getEnv("LDAP_LECTURER_ID_ATTR", "employeeNumber"),
 	}, nil
 }
 
@@ -48,3 +119,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,99 @@
+package policy
+
+import "strings"
+
+// Wildcard - Segmen permission yang cocok dengan resource/action apa pun: "achievement.*", "*.read", "*.*"
+const Wildcard = "*"
+
+// ImpliedPermissions - Hierarki permission: memiliki key berarti juga memiliki semua value-nya (transitif)
+var ImpliedPermissions = map[string][]string{
+	"achievement.verify": {"achievement.read"},
+	"achievement.write":  {"achievement.read"},
+	"student.write":      {"student.read"},
+	"lecturer.write":     {"lecturer.read"},
+	"user.write":         {"user.read"},
+	"user.delete":        {"user.read"},
+}
+
+// ExpandPermissions - Permission yang dimiliki ditambah semua permission turunannya menurut ImpliedPermissions
+func ExpandPermissions(granted []string) []string {
+	seen := make(map[string]bool, len(granted))
+	expanded := make([]string, 0, len(granted))
+
+	queue := append([]string{}, granted...)
+	for len(queue) > 0 {
+		perm := queue[0]
+		queue = queue[1:]
+		if seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		expanded = append(expanded, perm)
+		queue = append(queue, ImpliedPermissions[perm]...)
+	}
+
+	return expanded
+}
+
+// PermissionGranted - Cek apakah required tercakup oleh salah satu permission yang dimiliki,
+// memperhitungkan wildcard dan hierarki. required boleh berisi wildcard (mis. scope personal
+// access token "achievement.*"), yang hanya tercakup oleh wildcard yang sama atau lebih luas.
+func PermissionGranted(granted []string, required string) bool {
+	for _, perm := range ExpandPermissions(granted) {
+		if permissionCovers(perm, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantingPermission - Permission di granted yang mencakup required (langsung, wildcard, atau lewat
+// hierarki), string kosong jika tidak ada. Dipakai untuk menjelaskan kenapa akses diberikan.
+func GrantingPermission(granted []string, required string) string {
+	for _, perm := range granted {
+		if PermissionGranted([]string{perm}, required) {
+			return perm
+		}
+	}
+	return ""
+}
+
+// MissingPermissions - Permission di required yang tidak tercakup oleh granted
+func MissingPermissions(granted []string, required []string) []string {
+	expanded := ExpandPermissions(granted)
+
+	missing := []string{}
+	for _, req := range required {
+		covered := false
+		for _, perm := range expanded {
+			if permissionCovers(perm, req) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, req)
+		}
+	}
+
+	return missing
+}
+
+// permissionCovers - Cek apakah pola permission (boleh wildcard) mencakup required
+func permissionCovers(pattern, required string) bool {
+	if pattern == required {
+		return true
+	}
+
+	patternResource, patternAction, ok := strings.Cut(pattern, ".")
+	if !ok {
+		return false
+	}
+	resource, action, ok := strings.Cut(required, ".")
+	if !ok {
+		return false
+	}
+
+	return (patternResource == Wildcard || patternResource == resource) &&
+		(patternAction == Wildcard || patternAction == action)
+}
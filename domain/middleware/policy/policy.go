@@ -0,0 +1,227 @@
+// Package policy berisi aturan otorisasi berbasis resource (pemilik, dosen wali, program studi).
+// Tidak bergantung pada service maupun database supaya bisa dipakai dari middleware dan service,
+// dan definisinya bisa di-unit test langsung.
+package policy
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrDenied = errors.New("forbidden: access denied by policy")
+
+// Tipe resource yang dievaluasi policy
+const (
+	ResourceStudent     = "student"
+	ResourceAchievement = "achievement"
+	ResourceUser        = "user"
+)
+
+// Subject - User yang meminta akses beserta profil mahasiswa/dosennya (nil jika tidak punya).
+// Delegations = delegasi verifikasi yang sedang diterima dosen dari dosen wali lain.
+// AdminScope = program studi admin ter-scope (kosong = admin global).
+type Subject struct {
+	UserID      uuid.UUID
+	Roles       []string
+	Permissions []string
+	Student     *model.Student
+	Lecturer    *model.Lecturer
+	Delegations []model.VerificationDelegation
+	AdminScope  []string
+}
+
+// Resource - Resource yang diakses. OwnerID = ID mahasiswa pemilik, Owner diisi jika
+// kondisi butuh data mahasiswa (dosen wali, program studi). ProgramStudies = program studi
+// tambahan resource, mis. department untuk user dosen.
+type Resource struct {
+	Type           string
+	ID             uuid.UUID
+	OwnerID        uuid.UUID
+	Owner          *model.Student
+	ProgramStudies []string
+}
+
+// programStudies - Semua program studi tempat resource berada
+func (r *Resource) programStudies() []string {
+	programStudies := r.ProgramStudies
+	if r.Owner != nil {
+		programStudies = append([]string{r.Owner.ProgramStudy}, programStudies...)
+	}
+	return programStudies
+}
+
+// Condition - Satu syarat akses terhadap resource
+type Condition struct {
+	Name  string
+	Check func(sub *Subject, res *Resource) bool
+}
+
+// Policy - Akses diberikan jika salah satu kondisi terpenuhi
+type Policy struct {
+	Name  string
+	AnyOf []Condition
+}
+
+// Decision - Hasil evaluasi policy. Matched = nama kondisi yang terpenuhi
+type Decision struct {
+	Policy  string `json:"policy"`
+	Allowed bool   `json:"allowed"`
+	Matched string `json:"matched,omitempty"`
+}
+
+// IsOwner - Subject adalah mahasiswa pemilik resource
+var IsOwner = Condition{
+	Name: "owner",
+	Check: func(sub *Subject, res *Resource) bool {
+		return sub.Student != nil && res.OwnerID != uuid.Nil && sub.Student.ID == res.OwnerID
+	},
+}
+
+// IsAdvisor - Subject adalah dosen wali mahasiswa pemilik resource
+var IsAdvisor = Condition{
+	Name: "advisor",
+	Check: func(sub *Subject, res *Resource) bool {
+		return sub.Lecturer != nil && res.Owner != nil && res.Owner.AdvisorID == sub.Lecturer.ID
+	},
+}
+
+// IsDelegate - Subject adalah dosen pengganti yang sedang menerima delegasi dari dosen wali
+// mahasiswa pemilik resource, dan delegasinya mencakup mahasiswa tersebut
+var IsDelegate = Condition{
+	Name: "delegate",
+	Check: func(sub *Subject, res *Resource) bool {
+		return ActiveDelegation(sub, res, time.Now()) != nil
+	},
+}
+
+// ActiveDelegation - Delegasi subject yang berlaku pada waktu at untuk mahasiswa pemilik resource (nil jika tidak ada)
+func ActiveDelegation(sub *Subject, res *Resource, at time.Time) *model.VerificationDelegation {
+	if sub.Lecturer == nil || res.Owner == nil {
+		return nil
+	}
+
+	for i := range sub.Delegations {
+		delegation := &sub.Delegations[i]
+		if delegation.DelegateID == sub.Lecturer.ID &&
+			delegation.DelegatorID == res.Owner.AdvisorID &&
+			delegation.Covers(res.Owner.ID, at) {
+			return delegation
+		}
+	}
+
+	return nil
+}
+
+// SameProgramStudy - Subject adalah dosen dengan department sama dengan program studi mahasiswa pemilik resource
+var SameProgramStudy = Condition{
+	Name: "same_program_study",
+	Check: func(sub *Subject, res *Resource) bool {
+		if sub.Lecturer == nil || res.Owner == nil || sub.Lecturer.Department == "" {
+			return false
+		}
+		return strings.EqualFold(strings.TrimSpace(sub.Lecturer.Department), strings.TrimSpace(res.Owner.ProgramStudy))
+	},
+}
+
+// InAdminScope - Subject adalah admin global, atau admin ter-scope dan resource berada di salah satu program studinya.
+// Admin = role admin (/api/admin) atau permission admin.manage (/api/v1).
+var InAdminScope = Condition{
+	Name: "admin_scope",
+	Check: func(sub *Subject, res *Resource) bool {
+		if !HasRole("admin").Check(sub, res) && !HasPermission("admin.manage").Check(sub, res) {
+			return false
+		}
+		scope := &model.ProgramStudyScope{ProgramStudies: sub.AdminScope}
+		if scope.IsGlobal() {
+			return true
+		}
+		for _, programStudy := range res.programStudies() {
+			if scope.Allows(programStudy) {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// HasRole - Subject memiliki role tertentu (role utama maupun tambahan)
+func HasRole(roleName string) Condition {
+	return Condition{
+		Name: "role:" + roleName,
+		Check: func(sub *Subject, res *Resource) bool {
+			for _, role := range sub.Roles {
+				if role == roleName {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// HasPermission - Subject memiliki permission tertentu (termasuk wildcard dan hierarki)
+func HasPermission(permName string) Condition {
+	return Condition{
+		Name: "permission:" + permName,
+		Check: func(sub *Subject, res *Resource) bool {
+			return PermissionGranted(sub.Permissions, permName)
+		},
+	}
+}
+
+// StudentRecord - Data dan prestasi seorang mahasiswa: mahasiswa itu sendiri, dosen walinya,
+// dosen pengganti dosen walinya, dosen di program studinya, atau admin (admin ter-scope hanya program studinya)
+var StudentRecord = Policy{
+	Name:  "student.record",
+	AnyOf: []Condition{IsOwner, IsAdvisor, IsDelegate, SameProgramStudy, InAdminScope},
+}
+
+// AdminUser - Kelola user lewat /api/admin/users/:id: admin global, atau admin ter-scope untuk
+// mahasiswa/dosen di program studinya
+var AdminUser = Policy{
+	Name:  "admin.user",
+	AnyOf: []Condition{InAdminScope},
+}
+
+// AchievementOwner - Ubah, hapus, dan ajukan verifikasi prestasi: hanya mahasiswa pemiliknya
+var AchievementOwner = Policy{
+	Name:  "achievement.owner",
+	AnyOf: []Condition{IsOwner},
+}
+
+// AchievementVerifier - Verifikasi/tolak prestasi: dosen wali mahasiswa pemiliknya, atau dosen
+// pengganti selama delegasinya berlaku
+var AchievementVerifier = Policy{
+	Name:  "achievement.verifier",
+	AnyOf: []Condition{IsAdvisor, IsDelegate},
+}
+
+// Evaluate - Evaluasi kondisi policy secara berurutan, berhenti di kondisi pertama yang terpenuhi
+func (p Policy) Evaluate(sub *Subject, res *Resource) Decision {
+	decision := Decision{Policy: p.Name}
+	if sub == nil || res == nil {
+		return decision
+	}
+
+	for _, cond := range p.AnyOf {
+		if cond.Check(sub, res) {
+			decision.Allowed = true
+			decision.Matched = cond.Name
+			return decision
+		}
+	}
+
+	return decision
+}
+
+// Authorize - Evaluate untuk dipakai di service: ErrDenied jika tidak ada kondisi yang terpenuhi
+func (p Policy) Authorize(sub *Subject, res *Resource) error {
+	if !p.Evaluate(sub, res).Allowed {
+		return ErrDenied
+	}
+	return nil
+}
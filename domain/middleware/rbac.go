@@ -2,28 +2,47 @@ package middleware
 
 import (
 	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/service"
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// passwordChangeAllowedPaths - Endpoint yang tetap bisa diakses selama user wajib ganti password
+var passwordChangeAllowedPaths = map[string]bool{
+	"/api/v1/auth/change-password": true,
+	"/api/v1/me/password":          true,
+	"/api/v1/auth/logout":          true,
+}
+
 // RBACMiddleware - Middleware untuk RBAC (Role-Based Access Control)
 type RBACMiddleware struct {
-	AuthService *service.AuthService
-	RBACService *service.RBACService
+	AuthService          *service.AuthService
+	RBACService          *service.RBACService
+	AccessTokenService   *service.AccessTokenService
+	ImpersonationService *service.ImpersonationService
+	PolicyService        *service.PolicyService
+	Routes               *RouteRegistry
 }
 
-func NewRBACMiddleware(authService *service.AuthService, rbacService *service.RBACService) *RBACMiddleware {
+func NewRBACMiddleware(authService *service.AuthService, rbacService *service.RBACService, accessTokenService *service.AccessTokenService, impersonationService *service.ImpersonationService, policyService *service.PolicyService) *RBACMiddleware {
 	return &RBACMiddleware{
-		AuthService: authService,
-		RBACService: rbacService,
+		AuthService:          authService,
+		RBACService:          rbacService,
+		AccessTokenService:   accessTokenService,
+		ImpersonationService: impersonationService,
+		PolicyService:        policyService,
+		Routes:               NewRouteRegistry(),
 	}
 }
 
-// Authenticate - Middleware untuk autentikasi (ekstrak dan validasi JWT)
+// Authenticate - Middleware untuk autentikasi (ekstrak dan validasi JWT atau personal access token)
 func (m *RBACMiddleware) Authenticate() fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementAuth})
+
 	return func(c *fiber.Ctx) error {
 		// 1. Ekstrak JWT dari header
 		authHeader := c.Get("Authorization")
@@ -39,6 +58,23 @@ func (m *RBACMiddleware) Authenticate() fiber.Handler {
 			tokenStr = strings.TrimPrefix(authHeader, "Bearer ")
 		}
 
+		// Personal access token tidak punya sesi dan tidak masuk revocation list JWT,
+		// status cabut dan kadaluarsanya dicek langsung di tabelnya
+		if service.IsPersonalAccessToken(tokenStr) {
+			claims, err := m.AccessTokenService.Authenticate(tokenStr)
+			if err != nil {
+				if errors.Is(err, service.ErrInvalidAccessToken) {
+					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+						"error": "Invalid or expired token",
+					})
+				}
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to validate token",
+				})
+			}
+			return m.setClaims(c, claims)
+		}
+
 		// 2. Validasi token
 		claims, err := m.AuthService.ParseToken(tokenStr)
 		if err != nil {
@@ -47,18 +83,116 @@ func (m *RBACMiddleware) Authenticate() fiber.Handler {
 			})
 		}
 
-		// Simpan claims di context untuk digunakan di handler
-		c.Locals("user_id", claims.UserID)
-		c.Locals("role_id", claims.RoleID)
-		c.Locals("permissions", claims.Permissions)
-		c.Locals("claims", claims)
+		// 3. Tolak token yang sudah dicabut (logout / revoke oleh admin)
+		revoked, err := m.AuthService.IsTokenRevoked(claims)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to validate token",
+			})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token has been revoked",
+			})
+		}
 
-		return c.Next()
+		// 4. Tolak token dari sesi yang sudah dicabut, sekaligus perbarui last-seen sesi
+		if err := m.AuthService.CheckSession(claims); err != nil {
+			if errors.Is(err, service.ErrSessionRevoked) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Session has been revoked",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to validate session",
+			})
+		}
+
+		// 5. Permission di token bisa basi: bandingkan versinya dengan state RBAC terbaru
+		if err := m.RBACService.RefreshClaims(claims); err != nil {
+			if errors.Is(err, service.ErrUserInactive) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "User account is inactive",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to resolve permissions",
+			})
+		}
+
+		if claims.Actor != nil {
+			return m.impersonatedRequest(c, claims)
+		}
+
+		return m.setClaims(c, claims)
 	}
 }
 
-// RequirePermission - Middleware untuk check permission spesifik
+// impersonatedRequest - Request dengan token impersonation: cek admin asli, tolak aksi selain baca,
+// lalu catat request beserta status response-nya di audit log
+func (m *RBACMiddleware) impersonatedRequest(c *fiber.Ctx, claims *model.CustomClaims) error {
+	// Group /api legacy ikut menjalankan Authenticate, request yang sama cukup dicatat sekali
+	if audited, _ := c.Locals("impersonation_audited").(bool); audited {
+		return m.setClaims(c, claims)
+	}
+	c.Locals("impersonation_audited", true)
+
+	client := model.ClientInfo{IPAddress: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+
+	if err := m.ImpersonationService.CheckActor(claims); err != nil {
+		m.ImpersonationService.RecordRequest(claims, model.ImpersonationEventBlocked, c.Method(), c.Path(), fiber.StatusUnauthorized, client)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": service.ErrImpersonationNotActive.Error(),
+		})
+	}
+
+	if !service.ImpersonationAllows(c.Method(), c.Path()) {
+		m.ImpersonationService.RecordRequest(claims, model.ImpersonationEventBlocked, c.Method(), c.Path(), fiber.StatusForbidden, client)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Action not allowed while impersonating a user",
+		})
+	}
+
+	err := m.setClaims(c, claims)
+
+	status := c.Response().StatusCode()
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+	} else if err != nil {
+		status = fiber.StatusInternalServerError
+	}
+	m.ImpersonationService.RecordRequest(claims, model.ImpersonationEventRequest, c.Method(), c.Path(), status, client)
+
+	return err
+}
+
+// setClaims - Simpan claims di context untuk digunakan di handler, lalu lanjut ke handler berikutnya
+func (m *RBACMiddleware) setClaims(c *fiber.Ctx, claims *model.CustomClaims) error {
+	// 6. Token dari akun yang wajib ganti password hanya boleh dipakai untuk ganti password / logout
+	if claims.PasswordChangeRequired && !passwordChangeAllowedPaths[c.Path()] {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Password change required",
+		})
+	}
+
+	c.Locals("user_id", claims.UserID)
+	c.Locals("role_id", claims.RoleID)
+	c.Locals("permissions", claims.Permissions)
+	c.Locals("claims", claims)
+
+	return c.Next()
+}
+
+// RequireAuth - Alias Authenticate yang dipakai oleh route v1
+func (m *RBACMiddleware) RequireAuth() fiber.Handler {
+	return m.Authenticate()
+}
+
+// RequirePermission - Middleware untuk check permission spesifik (wildcard "resource.*" dan permission turunan ikut dihitung)
 func (m *RBACMiddleware) RequirePermission(requiredPermission string) fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementPermission, Names: []string{requiredPermission}})
+
 	return func(c *fiber.Ctx) error {
 		// Get permissions dari context (sudah di-set oleh Authenticate middleware)
 		permissions, ok := c.Locals("permissions").([]string)
@@ -68,17 +202,9 @@ func (m *RBACMiddleware) RequirePermission(requiredPermission string) fiber.Hand
 			})
 		}
 
-		// 4. Check apakah user memiliki permission yang diperlukan
-		hasPermission := false
-		for _, perm := range permissions {
-			if perm == requiredPermission {
-				hasPermission = true
-				break
-			}
-		}
-
+		// 4. Check apakah user memiliki permission yang diperlukan (termasuk wildcard dan hierarki)
 		// 5. Allow/deny request
-		if !hasPermission {
+		if !policy.PermissionGranted(permissions, requiredPermission) {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error":    "Forbidden: Insufficient permissions",
 				"required": requiredPermission,
@@ -91,6 +217,8 @@ func (m *RBACMiddleware) RequirePermission(requiredPermission string) fiber.Hand
 
 // RequireAnyPermission - Middleware untuk check salah satu dari beberapa permissions
 func (m *RBACMiddleware) RequireAnyPermission(requiredPermissions ...string) fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementAnyPermission, Names: requiredPermissions})
+
 	return func(c *fiber.Ctx) error {
 		permissions, ok := c.Locals("permissions").([]string)
 		if !ok {
@@ -101,14 +229,9 @@ func (m *RBACMiddleware) RequireAnyPermission(requiredPermissions ...string) fib
 
 		// Check apakah user memiliki salah satu permission
 		hasPermission := false
-		for _, userPerm := range permissions {
-			for _, reqPerm := range requiredPermissions {
-				if userPerm == reqPerm {
-					hasPermission = true
-					break
-				}
-			}
-			if hasPermission {
+		for _, reqPerm := range requiredPermissions {
+			if policy.PermissionGranted(permissions, reqPerm) {
+				hasPermission = true
 				break
 			}
 		}
@@ -126,6 +249,8 @@ func (m *RBACMiddleware) RequireAnyPermission(requiredPermissions ...string) fib
 
 // RequireAllPermissions - Middleware untuk check semua permissions harus ada
 func (m *RBACMiddleware) RequireAllPermissions(requiredPermissions ...string) fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementAllPermissions, Names: requiredPermissions})
+
 	return func(c *fiber.Ctx) error {
 		permissions, ok := c.Locals("permissions").([]string)
 		if !ok {
@@ -135,18 +260,7 @@ func (m *RBACMiddleware) RequireAllPermissions(requiredPermissions ...string) fi
 		}
 
 		// Check apakah user memiliki semua permissions
-		permMap := make(map[string]bool)
-		for _, perm := range permissions {
-			permMap[perm] = true
-		}
-
-		missingPermissions := []string{}
-		for _, reqPerm := range requiredPermissions {
-			if !permMap[reqPerm] {
-				missingPermissions = append(missingPermissions, reqPerm)
-			}
-		}
-
+		missingPermissions := policy.MissingPermissions(permissions, requiredPermissions)
 		if len(missingPermissions) > 0 {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error":   "Forbidden: Missing required permissions",
@@ -158,36 +272,179 @@ func (m *RBACMiddleware) RequireAllPermissions(requiredPermissions ...string) fi
 	}
 }
 
-// RequireRole - Middleware untuk check role spesifik
-func (m *RBACMiddleware) RequireRole(roleName string) fiber.Handler {
+// RequireRole - Middleware untuk check role: lolos jika salah satu role user (utama maupun tambahan) ada di roleNames.
+// Personal access token selalu ditolak karena hanya membawa permission yang dipilih saat token dibuat.
+func (m *RBACMiddleware) RequireRole(roleNames ...string) fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementRole, Names: roleNames})
+
 	return func(c *fiber.Ctx) error {
-		roleID, ok := c.Locals("role_id").(uuid.UUID)
+		userID, ok := c.Locals("user_id").(uuid.UUID)
 		if !ok {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Unauthorized: No role found",
 			})
 		}
 
-		// Get role name dari database
-		role, err := m.RBACService.GetRoleByID(roleID)
+		if isPersonalAccessToken(c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":    "Forbidden: Personal access tokens cannot use role-based endpoints",
+				"required": roleNames,
+			})
+		}
+
+		// Semua role user (role utama dan tambahan) dari cache RBAC
+		hasRole, err := m.RBACService.UserHasRole(userID, roleNames...)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to get role information",
 			})
 		}
 
-		if role.Name != roleName {
+		if !hasRole {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error":    "Forbidden: Insufficient role",
-				"required": roleName,
-				"current":  role.Name,
+				"required": roleNames,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireGlobalAdmin - Middleware untuk endpoint konfigurasi seluruh sistem (role, permission, scope admin):
+// admin ter-scope program studi ditolak. Dipasang setelah RequireRole("admin").
+func (m *RBACMiddleware) RequireGlobalAdmin() fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementGlobalAdmin})
+
+	return func(c *fiber.Ctx) error {
+		if isPersonalAccessToken(c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Forbidden: Personal access tokens cannot use role-based endpoints",
+			})
+		}
+
+		scope, err := m.AdminScope(c)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get admin scope",
+			})
+		}
+
+		if !scope.IsGlobal() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Forbidden: Requires an admin without program study scope",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// isPersonalAccessToken - Request memakai personal access token (bukan JWT login)
+func isPersonalAccessToken(c *fiber.Ctx) bool {
+	claims, ok := c.Locals("claims").(*model.CustomClaims)
+	return ok && claims.AccessTokenID != uuid.Nil
+}
+
+// AdminScope - Scope program studi admin yang sedang login (admin global = scope kosong)
+func (m *RBACMiddleware) AdminScope(c *fiber.Ctx) (*model.ProgramStudyScope, error) {
+	userID, err := GetUserID(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.RBACService.AdminScope(userID)
+}
+
+// ResourceLoader - Memuat resource yang diakses request dari route param untuk dievaluasi policy.
+// Berupa data (bukan closure atas fiber.Ctx) supaya endpoint explain bisa memuat resource dari path saja.
+type ResourceLoader struct {
+	Param string
+	Type  string
+	Load  func(id uuid.UUID) (*policy.Resource, error)
+}
+
+// resource - Parse nilai route param lalu muat resource-nya
+func (l ResourceLoader) resource(value string) (*policy.Resource, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid "+l.Type+" ID format")
+	}
+	return l.Load(id)
+}
+
+// RequirePolicy - Middleware untuk check policy berbasis resource (pemilik, dosen wali, program studi).
+// Dipasang setelah check permission: permission menentukan boleh memakai endpoint, policy menentukan resource mana.
+func (m *RBACMiddleware) RequirePolicy(p policy.Policy, load ResourceLoader) fiber.Handler {
+	m.Routes.require(RouteRequirement{Type: RequirementPolicy, Names: []string{p.Name}, policy: &p, loader: &load})
+
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(uuid.UUID)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized: No user found",
+			})
+		}
+		permissions, _ := c.Locals("permissions").([]string)
+
+		res, err := load.resource(c.Params(load.Param))
+		if err != nil {
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				return c.Status(fiberErr.Code).JSON(fiber.Map{
+					"error": fiberErr.Message,
+				})
+			}
+			if errors.Is(err, service.ErrPolicyResourceNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Resource not found",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load resource",
 			})
 		}
 
+		sub, err := m.PolicyService.Subject(userID, permissions)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get user information",
+			})
+		}
+		// Kondisi berbasis role (mis. admin_scope) tidak berlaku untuk personal access token, hanya permission-nya
+		if isPersonalAccessToken(c) {
+			sub.Roles = nil
+		}
+
+		decision := p.Evaluate(sub, res)
+		if !decision.Allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":  "Forbidden: Access denied by policy",
+				"policy": p.Name,
+			})
+		}
+
+		c.Locals("policy_decision", decision)
+
 		return c.Next()
 	}
 }
 
+// StudentParam - ResourceLoader mahasiswa dari route param berisi user ID mahasiswa
+func (m *RBACMiddleware) StudentParam(param string) ResourceLoader {
+	return ResourceLoader{Param: param, Type: policy.ResourceStudent, Load: m.PolicyService.StudentResource}
+}
+
+// AchievementParam - ResourceLoader prestasi dari route param berisi ID achievement reference
+func (m *RBACMiddleware) AchievementParam(param string) ResourceLoader {
+	return ResourceLoader{Param: param, Type: policy.ResourceAchievement, Load: m.PolicyService.AchievementResource}
+}
+
+// UserParam - ResourceLoader user dari route param berisi user ID (mahasiswa, dosen, atau user lain)
+func (m *RBACMiddleware) UserParam(param string) ResourceLoader {
+	return ResourceLoader{Param: param, Type: policy.ResourceUser, Load: m.PolicyService.UserResource}
+}
+
 // GetUserID - Helper untuk mendapatkan user ID dari context
 func GetUserID(c *fiber.Ctx) (uuid.UUID, error) {
 	userID, ok := c.Locals("user_id").(uuid.UUID)
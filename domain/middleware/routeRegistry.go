@@ -0,0 +1,381 @@
+package middleware
+
+import (
+	"UAS_BACKEND/domain/middleware/policy"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var ErrRouteNotMatched = errors.New("no route matches the given method and path")
+
+// Jenis syarat akses yang dicatat registry
+const (
+	RequirementAuth           = "auth"
+	RequirementPermission     = "permission"
+	RequirementAnyPermission  = "any_permission"
+	RequirementAllPermissions = "all_permissions"
+	RequirementRole           = "role"
+	RequirementGlobalAdmin    = "global_admin"
+	RequirementPolicy         = "policy"
+)
+
+// RouteRequirement - Satu syarat akses pada route. Source = route/grup tempat middleware-nya dipasang.
+type RouteRequirement struct {
+	Type   string   `json:"type"`
+	Names  []string `json:"names,omitempty"`
+	Source string   `json:"source,omitempty"`
+
+	policy *policy.Policy
+	loader *ResourceLoader
+}
+
+// RouteInfo - Route beserta semua syarat aksesnya, termasuk yang diwarisi dari middleware grup
+type RouteInfo struct {
+	Method       string             `json:"method"`
+	Path         string             `json:"path"`
+	Requirements []RouteRequirement `json:"requirements"`
+}
+
+// AccessCheck - Hasil evaluasi satu syarat untuk user tertentu
+type AccessCheck struct {
+	RouteRequirement
+	Passed    bool     `json:"passed"`
+	Matched   string   `json:"matched,omitempty"`
+	GrantedBy string   `json:"granted_by,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+// AccessExplanation - Jawaban "kenapa user ini boleh/tidak boleh mengakses route ini"
+type AccessExplanation struct {
+	UserID  uuid.UUID     `json:"user_id"`
+	Method  string        `json:"method"`
+	Path    string        `json:"path"`
+	Route   string        `json:"route"`
+	Roles   []string      `json:"roles"`
+	Allowed bool          `json:"allowed"`
+	Checks  []AccessCheck `json:"checks"`
+}
+
+// registeredRoute - Satu pemanggilan registrasi fiber (Get, Post, Use, Group dengan handler)
+type registeredRoute struct {
+	key          *fiber.Handler
+	path         string
+	methods      []string
+	requirements []RouteRequirement
+}
+
+// isMiddleware - Use mendaftarkan route untuk semua method sekaligus, Get hanya HEAD+GET
+func (r *registeredRoute) isMiddleware() bool {
+	return len(r.methods) > 2
+}
+
+// source - Label tempat syarat dipasang, mis. "USE /api/v1/students" atau "GET /api/v1/students/:id"
+func (r *registeredRoute) source(method string) string {
+	if r.isMiddleware() {
+		return "USE " + r.path
+	}
+	return method + " " + r.path
+}
+
+// RouteRegistry - Mencatat syarat akses setiap route saat registrasi. Middleware Require* mencatat
+// syaratnya sebagai pending, lalu hook OnRoute fiber memberikannya ke route yang sedang didaftarkan.
+// Karena itu middleware otorisasi harus dibuat langsung di pemanggilan Get/Post/Use/Group, bukan
+// disimpan di variabel lalu dipakai ulang di beberapa route.
+type RouteRegistry struct {
+	mutex   sync.Mutex
+	pending []RouteRequirement
+	routes  []*registeredRoute
+}
+
+func NewRouteRegistry() *RouteRegistry {
+	return &RouteRegistry{}
+}
+
+// Track - Pasang hook registrasi route di app. Dipanggil sebelum route apa pun didaftarkan.
+func (r *RouteRegistry) Track(app *fiber.App) {
+	app.Hooks().OnRoute(r.onRoute)
+}
+
+// require - Catat syarat dari middleware yang baru dibuat, menunggu route berikutnya
+func (r *RouteRegistry) require(req RouteRequirement) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pending = append(r.pending, req)
+}
+
+// onRoute - Hook fiber untuk setiap route yang didaftarkan. Satu pemanggilan Get/Use memicu hook
+// beberapa kali (HEAD+GET, Use untuk setiap method) dengan slice handler yang sama, sehingga
+// dikenali dari alamat handler pertamanya.
+func (r *RouteRegistry) onRoute(route fiber.Route) error {
+	if len(route.Handlers) == 0 {
+		return nil
+	}
+	key := &route.Handlers[0]
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if n := len(r.routes); n > 0 && r.routes[n-1].key == key {
+		r.routes[n-1].methods = append(r.routes[n-1].methods, route.Method)
+		return nil
+	}
+
+	r.routes = append(r.routes, &registeredRoute{
+		key:          key,
+		path:         route.Path,
+		methods:      []string{route.Method},
+		requirements: r.pending,
+	})
+	r.pending = nil
+
+	return nil
+}
+
+// Routes - Semua route (tanpa middleware grup dan HEAD otomatis) beserta syarat aksesnya
+func (r *RouteRegistry) Routes() []RouteInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	routes := []RouteInfo{}
+	for i, route := range r.routes {
+		if route.isMiddleware() {
+			continue
+		}
+		for _, method := range route.methods {
+			if method == fiber.MethodHead && len(route.methods) > 1 {
+				continue
+			}
+			routes = append(routes, RouteInfo{
+				Method:       method,
+				Path:         route.path,
+				Requirements: r.requirementsOf(i, method),
+			})
+		}
+	}
+
+	return routes
+}
+
+// Match - Route pertama yang cocok dengan method dan path request (urutan sama dengan router fiber)
+func (r *RouteRegistry) Match(method, path string) (*RouteInfo, map[string]string, error) {
+	method = strings.ToUpper(method)
+	if method == fiber.MethodHead {
+		method = fiber.MethodGet
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, route := range r.routes {
+		if route.isMiddleware() || !containsString(route.methods, method) {
+			continue
+		}
+		params, ok := matchPath(route.path, path, false)
+		if !ok {
+			continue
+		}
+		return &RouteInfo{
+			Method:       method,
+			Path:         route.path,
+			Requirements: r.requirementsOf(i, method),
+		}, params, nil
+	}
+
+	return nil, nil, ErrRouteNotMatched
+}
+
+// requirementsOf - Syarat middleware grup yang didaftarkan sebelum route ke-index dan mencakup
+// path-nya, ditambah syarat route itu sendiri. Syarat yang sama dari grup yang sama cukup sekali.
+func (r *RouteRegistry) requirementsOf(index int, method string) []RouteRequirement {
+	route := r.routes[index]
+	requirements := []RouteRequirement{}
+	seen := map[string]bool{}
+
+	add := func(owner *registeredRoute, req RouteRequirement) {
+		req.Source = owner.source(method)
+		key := req.Source + "|" + req.Type + "|" + strings.Join(req.Names, ",")
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		requirements = append(requirements, req)
+	}
+
+	for _, mw := range r.routes[:index] {
+		if !mw.isMiddleware() || !containsString(mw.methods, method) {
+			continue
+		}
+		if _, ok := matchPath(mw.path, route.path, true); !ok {
+			continue
+		}
+		for _, req := range mw.requirements {
+			add(mw, req)
+		}
+	}
+	for _, req := range route.requirements {
+		add(route, req)
+	}
+
+	return requirements
+}
+
+// matchPath - Cocokkan path dengan pola route fiber (":param", "*"). prefix = pola middleware
+// (Use/Group) yang juga mencakup semua path di bawahnya.
+func matchPath(pattern, path string, prefix bool) (map[string]string, bool) {
+	patternSegments := splitPath(pattern)
+	pathSegments := splitPath(path)
+	params := map[string]string{}
+
+	for i, segment := range patternSegments {
+		if segment == "*" || segment == "+" {
+			return params, true
+		}
+		if i >= len(pathSegments) {
+			return nil, false
+		}
+		if strings.HasPrefix(segment, ":") {
+			params[strings.TrimSuffix(segment[1:], "?")] = pathSegments[i]
+			continue
+		}
+		if !strings.EqualFold(segment, pathSegments[i]) {
+			return nil, false
+		}
+	}
+
+	if !prefix && len(pathSegments) != len(patternSegments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// splitPath - Segmen path tanpa slash di awal/akhir ("/" menjadi kosong)
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Explain - Evaluasi syarat route untuk user dengan role dan permission terbarunya (bukan scope token),
+// menjelaskan role/permission/kondisi policy yang terpenuhi atau yang kurang
+func (m *RBACMiddleware) Explain(userID uuid.UUID, method, path string) (*AccessExplanation, error) {
+	route, params, err := m.Routes.Match(method, path)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := m.RBACService.GetPermissionState(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &AccessExplanation{
+		UserID:  userID,
+		Method:  route.Method,
+		Path:    path,
+		Route:   route.Path,
+		Roles:   state.RoleNames,
+		Allowed: true,
+		Checks:  []AccessCheck{},
+	}
+
+	for _, req := range route.Requirements {
+		check := AccessCheck{RouteRequirement: req}
+
+		switch req.Type {
+		case RequirementAuth:
+			check.Passed = state.IsActive
+			if !check.Passed {
+				check.Reason = "User account is inactive"
+			}
+
+		case RequirementPermission, RequirementAnyPermission:
+			for _, name := range req.Names {
+				if grantedBy := policy.GrantingPermission(state.Permissions, name); grantedBy != "" {
+					check.Passed = true
+					check.Matched = name
+					check.GrantedBy = grantedBy
+					break
+				}
+			}
+			if !check.Passed {
+				check.Missing = req.Names
+			}
+
+		case RequirementAllPermissions:
+			check.Missing = policy.MissingPermissions(state.Permissions, req.Names)
+			check.Passed = len(check.Missing) == 0
+
+		case RequirementRole:
+			for _, name := range req.Names {
+				if containsString(state.RoleNames, name) {
+					check.Passed = true
+					check.Matched = name
+					break
+				}
+			}
+			if !check.Passed {
+				check.Missing = req.Names
+			}
+
+		case RequirementGlobalAdmin:
+			check.Passed = len(state.AdminScope) == 0
+			if !check.Passed {
+				check.Reason = "Admin is scoped to program studies: " + strings.Join(state.AdminScope, ", ")
+			}
+
+		case RequirementPolicy:
+			m.explainPolicy(&check, userID, state.Permissions, params)
+		}
+
+		if !check.Passed {
+			explanation.Allowed = false
+		}
+		explanation.Checks = append(explanation.Checks, check)
+	}
+
+	return explanation, nil
+}
+
+// explainPolicy - Muat resource dari param path lalu evaluasi policy seperti RequirePolicy
+func (m *RBACMiddleware) explainPolicy(check *AccessCheck, userID uuid.UUID, permissions []string, params map[string]string) {
+	res, err := check.loader.resource(params[check.loader.Param])
+	if err != nil {
+		check.Reason = "Failed to load " + check.loader.Type + ": " + err.Error()
+		return
+	}
+
+	sub, err := m.PolicyService.Subject(userID, permissions)
+	if err != nil {
+		check.Reason = "Failed to get user information"
+		return
+	}
+
+	decision := check.policy.Evaluate(sub, res)
+	check.Passed = decision.Allowed
+	check.Matched = decision.Matched
+	if !decision.Allowed {
+		check.Reason = "No condition of the policy is satisfied"
+	}
+}
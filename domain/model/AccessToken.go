@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessToken - Tabel personal_access_tokens (PostgreSQL)
+// Token API untuk script/integrasi. Hanya hash token yang disimpan, token mentah ditampilkan sekali saat dibuat.
+type PersonalAccessToken struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Label       string     `json:"label" db:"label"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"` // Awal token mentah untuk dikenali user, mis. "uas_pat_Ab3d"
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Permissions []string   `json:"permissions" db:"permissions"` // Subset permission role pemilik saat token dibuat
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateAccessTokenRequest - Request membuat personal access token
+type CreateAccessTokenRequest struct {
+	Label         string   `json:"label"`
+	Permissions   []string `json:"permissions"`
+	ExpiresInDays int      `json:"expires_in_days"` // Default 30, maksimal 365
+}
+
+// CreateAccessTokenResponse - Token mentah hanya dikembalikan sekali di sini
+type CreateAccessTokenResponse struct {
+	Token       string               `json:"token"`
+	AccessToken *PersonalAccessToken `json:"access_token"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusDeleted - to_status riwayat untuk soft delete (status reference sendiri tidak berubah)
+const StatusDeleted = "deleted"
+
+// AchievementStatusHistory - Tabel achievement_status_history (PostgreSQL): satu perubahan status prestasi.
+// FromStatus nil = prestasi baru dibuat, ActorUserID nil = data migrasi sebelum riwayat dicatat.
+type AchievementStatusHistory struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	AchievementRefID uuid.UUID  `json:"achievement_ref_id" db:"achievement_ref_id"`
+	FromStatus       *string    `json:"from_status" db:"from_status"`
+	ToStatus         string     `json:"to_status" db:"to_status"`
+	ActorUserID      *uuid.UUID `json:"actor_user_id" db:"actor_user_id"`
+	ActorName        string     `json:"actor_name,omitempty" db:"-"` // users.full_name, kosong jika user sudah dihapus
+	ActorRole        string     `json:"actor_role" db:"actor_role"`
+	Note             *string    `json:"note,omitempty" db:"note"`
+	DelegationID     *uuid.UUID `json:"delegation_id,omitempty" db:"delegation_id"` // Diisi jika diputuskan dosen pengganti
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// StatusActor - User yang memicu perubahan status prestasi dan perannya saat itu (student, lecturer, ...).
+// DelegationID diisi jika keputusan diambil dosen pengganti atas delegasi dosen wali.
+type StatusActor struct {
+	UserID       uuid.UUID
+	Role         string
+	DelegationID *uuid.UUID
+}
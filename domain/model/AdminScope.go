@@ -0,0 +1,60 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminScope - Tabel admin_scopes (PostgreSQL): satu program studi yang dikelola admin ter-scope.
+// Program studi dicocokkan dengan students.program_study dan lecturers.department tanpa membedakan huruf besar/kecil.
+type AdminScope struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	ProgramStudy string     `json:"program_study" db:"program_study"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AdminScopeRequest - Body PUT /api/admin/users/:id/scope, kosong = jadikan admin global
+type AdminScopeRequest struct {
+	ProgramStudies []string `json:"program_studies"`
+}
+
+// ProgramStudyScope - Batas program studi admin yang sedang login. nil atau tanpa program studi = admin global.
+type ProgramStudyScope struct {
+	ProgramStudies []string `json:"program_studies"`
+}
+
+// IsGlobal - Admin tidak dibatasi program studi
+func (s *ProgramStudyScope) IsGlobal() bool {
+	return s == nil || len(s.ProgramStudies) == 0
+}
+
+// Allows - Program studi (atau department dosen) termasuk scope admin
+func (s *ProgramStudyScope) Allows(programStudy string) bool {
+	if s.IsGlobal() {
+		return true
+	}
+
+	programStudy = strings.TrimSpace(programStudy)
+	for _, allowed := range s.ProgramStudies {
+		if programStudy != "" && strings.EqualFold(strings.TrimSpace(allowed), programStudy) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lowered - Program studi dalam huruf kecil untuk dibandingkan dengan LOWER(...) di query
+func (s *ProgramStudyScope) Lowered() []string {
+	if s.IsGlobal() {
+		return nil
+	}
+
+	lowered := make([]string, 0, len(s.ProgramStudies))
+	for _, programStudy := range s.ProgramStudies {
+		lowered = append(lowered, strings.ToLower(strings.TrimSpace(programStudy)))
+	}
+	return lowered
+}
@@ -2,11 +2,13 @@ package model
 
 // AdminAchievementFilter - DTO untuk filter
 type AdminAchievementFilter struct {
-	Status          string `json:"status,omitempty"`          // 'draft', 'submitted', 'verified', 'rejected'
+	Status          string `json:"status,omitempty"`           // 'draft', 'submitted', 'verified', 'rejected'
 	AchievementType string `json:"achievement_type,omitempty"` // 'academic', 'competition', etc.
-	StudentID       string `json:"student_id,omitempty"`      // Filter by student
-	AdvisorID       string `json:"advisor_id,omitempty"`      // Filter by advisor
-	ProgramStudy    string `json:"program_study,omitempty"`   // Filter by program study
+	StudentID       string `json:"student_id,omitempty"`       // Filter by student
+	AdvisorID       string `json:"advisor_id,omitempty"`       // Filter by advisor
+	ProgramStudy    string `json:"program_study,omitempty"`    // Filter by program study
+
+	ScopeProgramStudies []string `json:"-"` // Scope admin ter-scope (huruf kecil), diisi service, bukan dari request
 }
 
 // AdminAchievementSort - DTO untuk sorting
@@ -22,4 +24,4 @@ type AchievementSummary struct {
 	Submitted int `json:"submitted"`
 	Verified  int `json:"verified"`
 	Rejected  int `json:"rejected"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationDelegation - Tabel verification_delegations (PostgreSQL): dosen wali (delegator)
+// melimpahkan hak verifikasi prestasi mahasiswa bimbingannya ke dosen lain (delegate) untuk
+// rentang waktu tertentu, mis. selama cuti atau sabbatical.
+type VerificationDelegation struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	DelegatorID uuid.UUID   `json:"delegator_id" db:"delegator_id"` // lecturers.id dosen wali
+	DelegateID  uuid.UUID   `json:"delegate_id" db:"delegate_id"`   // lecturers.id dosen pengganti
+	StartsAt    time.Time   `json:"starts_at" db:"starts_at"`
+	EndsAt      time.Time   `json:"ends_at" db:"ends_at"`
+	StudentIDs  []uuid.UUID `json:"student_ids"` // Tabel verification_delegation_students, kosong = semua mahasiswa bimbingan
+	Reason      string      `json:"reason,omitempty" db:"reason"`
+	CreatedBy   *uuid.UUID  `json:"created_by" db:"created_by"` // users.id dosen atau admin yang membuat
+	RevokedAt   *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+}
+
+// IsActive - Delegasi berlaku pada waktu at (sudah mulai, belum berakhir, tidak dicabut)
+func (d *VerificationDelegation) IsActive(at time.Time) bool {
+	return d.RevokedAt == nil && !at.Before(d.StartsAt) && at.Before(d.EndsAt)
+}
+
+// Covers - Delegasi berlaku pada waktu at untuk mahasiswa studentID
+func (d *VerificationDelegation) Covers(studentID uuid.UUID, at time.Time) bool {
+	if !d.IsActive(at) {
+		return false
+	}
+	if len(d.StudentIDs) == 0 {
+		return true
+	}
+	for _, id := range d.StudentIDs {
+		if id == studentID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateDelegationRequest - Body POST /api/v1/delegations
+type CreateDelegationRequest struct {
+	DelegatorID *uuid.UUID  `json:"delegator_id,omitempty"` // Hanya admin; dosen selalu mendelegasikan mahasiswa bimbingannya sendiri
+	DelegateID  uuid.UUID   `json:"delegate_id"`
+	StartsAt    time.Time   `json:"starts_at"`
+	EndsAt      time.Time   `json:"ends_at"`
+	StudentIDs  []uuid.UUID `json:"student_ids,omitempty"` // students.id, kosong = semua mahasiswa bimbingan
+	Reason      string      `json:"reason"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailChangeToken - Tabel email_change_tokens (PostgreSQL)
+// Email user baru diganti setelah link konfirmasi di email baru dibuka.
+type EmailChangeToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	NewEmail  string     `json:"new_email" db:"new_email"`
+	TokenHash string     `json:"-" db:"token_hash"` // SHA-256 dari token yang dikirim ke email baru
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DTO untuk update profil sendiri, hanya field yang aman diubah oleh user
+type UpdateProfileRequest struct {
+	FullName string `json:"full_name"`
+}
+
+// DTO untuk permintaan ganti email
+type EmailChangeRequest struct {
+	NewEmail        string `json:"new_email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// DTO untuk konfirmasi ganti email
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
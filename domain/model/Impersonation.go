@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationActor - Claim "act" (RFC 8693) di token impersonation: admin asli di balik token
+type ImpersonationActor struct {
+	UserID uuid.UUID `json:"sub"`
+}
+
+// Event audit log impersonation
+const (
+	ImpersonationEventStart   = "start"   // Admin memulai impersonation
+	ImpersonationEventRequest = "request" // Request yang dijalankan dengan token impersonation
+	ImpersonationEventBlocked = "blocked" // Request yang ditolak karena tidak boleh dilakukan saat impersonation
+)
+
+// ImpersonationLog - Tabel impersonation_audit_log (PostgreSQL)
+// Tanpa foreign key supaya jejak audit tetap ada walaupun admin/user dihapus.
+type ImpersonationLog struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	AdminID    uuid.UUID `json:"admin_id" db:"admin_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	TokenID    string    `json:"token_id" db:"token_id"` // jti token impersonation
+	Event      string    `json:"event" db:"event"`
+	Method     string    `json:"method,omitempty" db:"method"`
+	Path       string    `json:"path,omitempty" db:"path"`
+	StatusCode int       `json:"status_code,omitempty" db:"status_code"`
+	Reason     string    `json:"reason,omitempty" db:"reason"` // Alasan dari admin, hanya di event start
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// StartImpersonationRequest - Body POST /api/admin/users/:id/impersonate
+type StartImpersonationRequest struct {
+	Reason string `json:"reason"` // Wajib, mis. nomor tiket laporan user
+}
+
+// ImpersonationResponse - Token impersonation (tanpa refresh token)
+type ImpersonationResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      Users     `json:"user"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserInvitation - Tabel user_invitations (PostgreSQL), satu undangan aktif per user
+type UserInvitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash  string     `json:"-" db:"token_hash"` // SHA-256 dari token di link aktivasi
+	InvitedBy  *uuid.UUID `json:"invited_by" db:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at" db:"accepted_at"` // Diisi saat user mengaktifkan akun, token hanya berlaku sekali
+	SentAt     time.Time  `json:"sent_at" db:"sent_at"`         // Diperbarui setiap undangan dikirim ulang
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DTO untuk Input Aktivasi Akun dari link undangan
+type AcceptInvitationRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
@@ -0,0 +1,19 @@
+package model
+
+// JWK - Public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`           // 'RSA', 'OKP' (Ed25519), atau 'EC' (hanya dari identity provider)
+	KID string `json:"kid"`           // ID key, sama dengan header kid di token
+	Use string `json:"use"`           // Selalu 'sig'
+	Alg string `json:"alg"`           // 'RS256' atau 'EdDSA'; JWKS identity provider bisa kosong
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Kurva OKP 'Ed25519', atau kurva EC 'P-256' / 'P-384' / 'P-521'
+	X   string `json:"x,omitempty"`   // Public key Ed25519, atau koordinat x EC
+	Y   string `json:"y,omitempty"`   // Koordinat y EC
+}
+
+// JWKS - JSON Web Key Set untuk endpoint /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// LoginAttempt - Tabel login_attempts (PostgreSQL)
+// Satu baris per akun (scope 'account', key = user ID atau identifier jika user tidak ada)
+// dan per alamat IP (scope 'ip').
+type LoginAttempt struct {
+	Scope         string     `json:"scope" db:"scope"` // 'account', 'ip'
+	Key           string     `json:"key" db:"key"`
+	FailedCount   int        `json:"failed_count" db:"failed_count"` // Jumlah gagal berturut-turut dalam window
+	LastFailedAt  time.Time  `json:"last_failed_at" db:"last_failed_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" db:"next_attempt_at"` // Delay progresif: login ditolak sebelum waktu ini
+	LockedUntil   *time.Time `json:"locked_until" db:"locked_until"`       // Lockout sementara setelah melewati threshold
+}
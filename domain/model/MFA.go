@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA - Tabel user_mfa (PostgreSQL)
+type UserMFA struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`                  // Secret TOTP base32
+	Enabled      bool       `json:"enabled" db:"enabled"`           // false = enrollment belum dikonfirmasi
+	ConfirmedAt  *time.Time `json:"confirmed_at" db:"confirmed_at"` // Waktu enrollment dikonfirmasi dengan kode pertama
+	LastUsedStep int64      `json:"-" db:"last_used_step"`          // Periode TOTP terakhir yang dipakai, mencegah replay kode
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MFAChallenge - Tabel mfa_challenges (PostgreSQL)
+// Dibuat saat password benar tapi user masih harus memasukkan kode TOTP (atau enroll dulu).
+type MFAChallenge struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	Purpose   string     `json:"purpose" db:"purpose"`   // 'verify', 'enroll'
+	Attempts  int        `json:"attempts" db:"attempts"` // Kode salah yang sudah dicoba
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DTO untuk Output Login yang masih butuh MFA
+type MFAChallengeResponse struct {
+	MFAToken           string    `json:"mfa_token"`
+	EnrollmentRequired bool      `json:"enrollment_required"` // true = role mewajibkan MFA tapi user belum enroll
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+// DTO untuk Output Enrollment MFA
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth://, dirender sebagai QR code oleh frontend
+}
+
+// DTO untuk Input verifikasi kode MFA (kode TOTP 6 digit atau recovery code)
+type MFACodeRequest struct {
+	MFAToken string `json:"mfa_token,omitempty"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MailMessage - Tabel mail_outbox (PostgreSQL)
+type MailMessage struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	To            string     `json:"to" db:"recipient"`
+	Subject       string     `json:"subject" db:"subject"`
+	Body          string     `json:"body" db:"body"`             // Plain text
+	Status        string     `json:"status" db:"status"`         // 'pending', 'sent', 'failed'
+	Attempts      int        `json:"attempts" db:"attempts"`     // Jumlah percobaan kirim
+	LastError     *string    `json:"last_error" db:"last_error"` // Error dari percobaan terakhir
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at" db:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
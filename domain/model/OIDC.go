@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OIDCAuthRequest - Tabel oidc_auth_requests (PostgreSQL)
+// Satu baris per redirect ke identity provider, dipakai sekali saat callback.
+type OIDCAuthRequest struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	StateHash    string    `json:"-" db:"state_hash"`    // SHA-256 dari parameter state
+	CodeVerifier string    `json:"-" db:"code_verifier"` // PKCE code verifier, dikirim ke token endpoint saat callback
+	Nonce        string    `json:"-" db:"nonce"`         // Harus sama dengan claim nonce di ID token
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// UserIdentity - Tabel user_identities (PostgreSQL)
+// Menghubungkan akun identity provider (issuer + subject) dengan user lokal.
+type UserIdentity struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	Issuer      string    `json:"issuer" db:"issuer"`
+	Subject     string    `json:"subject" db:"subject"`
+	Email       string    `json:"email" db:"email"` // Email dari IdP saat terakhir login, hanya informasi
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at" db:"last_login_at"`
+}
+
+// OIDCIdentity - Claim dari ID token yang sudah diverifikasi
+type OIDCIdentity struct {
+	Issuer            string `json:"iss"`
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken - Tabel password_reset_tokens (PostgreSQL)
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"` // SHA-256 dari token yang dikirim lewat email
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"` // Diisi saat token dipakai, token hanya berlaku sekali
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DTO untuk Input Forgot Password
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// DTO untuk Input Reset Password
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
@@ -12,3 +12,27 @@ type Permission struct {
 	Action      string    `json:"action" db:"action"`
 	Description string    `json:"description" db:"description"`
 }
+
+// DTO untuk Input Create/Update Permission, name selalu "<resource>.<action>"
+type PermissionRequest struct {
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// DTO untuk Input Attach Permission ke Role
+type RolePermissionRequest struct {
+	PermissionID uuid.UUID `json:"permission_id"`
+}
+
+// PermissionState - Permission efektif user saat ini beserta versinya, dibandingkan dengan versi di token
+type PermissionState struct {
+	RoleID      uuid.UUID   // Role utama (users.role_id)
+	RoleIDs     []uuid.UUID // Semua role user (user_roles), termasuk role utama
+	RoleNames   []string
+	IsActive    bool
+	UserVersion int      // users.permission_version, naik saat role atau status aktif user berubah
+	RoleVersion int      // Jumlah roles.permission_version semua role user, naik saat permission salah satu role berubah
+	Permissions []string // Gabungan permissions semua role
+	AdminScope  []string // admin_scopes: program studi yang dikelola admin ter-scope, kosong = admin global
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken - Tabel refresh_tokens (PostgreSQL)
+type RefreshToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	FamilyID   uuid.UUID  `json:"family_id" db:"family_id"` // Semua token hasil rotasi dari satu login berbagi family yang sama
+	TokenHash  string     `json:"-" db:"token_hash"`        // SHA-256 dari token opaque, token asli tidak pernah disimpan
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RotatedAt  *time.Time `json:"rotated_at" db:"rotated_at"`   // Diisi saat token sudah ditukar dengan token baru
+	ReplacedBy *uuid.UUID `json:"replaced_by" db:"replaced_by"` // ID token pengganti hasil rotasi
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken - Tabel revoked_tokens (PostgreSQL), daftar jti access token yang sudah dicabut
+type RevokedToken struct {
+	JTI       string    `json:"jti" db:"jti"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"` // Sama dengan exp token, setelah lewat baris boleh dihapus
+	RevokedAt time.Time `json:"revoked_at" db:"revoked_at"`
+}
@@ -9,3 +9,8 @@ type RolePermission struct {
 	RoleID       uuid.UUID `json:"role_id" db:"role_id"`
 	PermissionID uuid.UUID `json:"permission_id" db:"permission_id"`
 }
+
+// DTO untuk Input Tambah Role ke User
+type UserRoleRequest struct {
+	RoleID uuid.UUID `json:"role_id"`
+}
@@ -11,5 +11,20 @@ type Roles struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"`
 	Description string    `json:"description" db:"description"`
+	MFARequired bool      `json:"mfa_required" db:"mfa_required"` // User dengan role ini wajib memakai MFA
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
+
+// DTO untuk Input Create/Update Role
+type RoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MFARequired *bool  `json:"mfa_required,omitempty"`
+}
+
+// RoleDetail - Role beserta permissions dan jumlah user yang memakainya
+type RoleDetail struct {
+	Roles
+	Permissions []Permission `json:"permissions"`
+	UserCount   int          `json:"user_count"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession - Tabel user_sessions (PostgreSQL)
+// Satu baris per login yang berhasil. ID sesi sama dengan family_id refresh token,
+// dan setiap access token membawa ID sesi di claim "sid".
+type UserSession struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"` // Diperbarui setiap request terautentikasi dan refresh
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`     // Mengikuti masa berlaku refresh token terakhir
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+
+	Current bool `json:"current" db:"-"` // Sesi yang dipakai oleh request saat ini
+}
+
+// ClientInfo - Informasi perangkat yang login, disimpan di sesi
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
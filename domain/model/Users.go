@@ -18,6 +18,9 @@ type Users struct {
 	IsActive     bool      `json:"is_active" db:"is_active"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Password dibuat/direset oleh admin, user wajib mengganti password setelah login
+	MustChangePassword bool `json:"must_change_password" db:"must_change_password"`
 }
 
 // DTO untuk Input Login
@@ -28,14 +31,45 @@ type LoginRequest struct {
 
 // DTO untuk Output Login
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  Users  `json:"user"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	User         Users     `json:"user"`
+
+	// Diisi (tanpa token) jika login masih butuh kode MFA
+	MFA *MFAChallengeResponse `json:"mfa,omitempty"`
+
+	// Token hanya bisa dipakai untuk ganti password sampai password diganti
+	PasswordChangeRequired bool `json:"password_change_required"`
+}
+
+// DirectoryProfile - Atribut user dari direktori eksternal (LDAP) yang disinkronkan saat login.
+// Field kosong berarti atribut tidak ada di direktori dan data lokal tidak diubah.
+type DirectoryProfile struct {
+	FullName   string
+	Email      string
+	LecturerID string
+}
+
+// DTO untuk ganti password oleh user sendiri
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
 }
 
 // Claims untuk JWT
+// ID token (jti) disimpan di RegisteredClaims.ID dan dipakai untuk revocation list
 type CustomClaims struct {
 	UserID      uuid.UUID `json:"user_id"`
 	RoleID      uuid.UUID `json:"role_id"`
 	Permissions []string  `json:"permissions"`
+	SessionID   uuid.UUID `json:"sid"` // Sesi login asal token (user_sessions.id), uuid.Nil untuk token tanpa sesi
+
+	UserPermVersion int `json:"upv"` // users.permission_version saat token diterbitkan
+	RolePermVersion int `json:"rpv"` // roles.permission_version saat token diterbitkan
+
+	PasswordChangeRequired bool                `json:"pwd_change,omitempty"` // Token terbatas: hanya boleh dipakai untuk ganti password
+	AccessTokenID          uuid.UUID           `json:"-"`                    // Diisi jika request memakai personal access token, bukan JWT
+	Actor                  *ImpersonationActor `json:"act,omitempty"`        // Diisi jika token hasil impersonation oleh admin
 	jwt.RegisteredClaims
-}
\ No newline at end of file
+}
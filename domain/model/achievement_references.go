@@ -11,13 +11,14 @@ type AchievementReference struct {
 	ID                 uuid.UUID  `json:"id" db:"id"`
 	StudentID          uuid.UUID  `json:"student_id" db:"student_id"`
 	MongoAchievementID string     `json:"mongo_achievement_id" db:"mongo_achievement_id"`
-	Status             string     `json:"status" db:"status"` // ENUM: 'draft', 'submitted', 'verified', 'rejected'
+	Status             string     `json:"status" db:"status"` // Tabel achievement_statuses, transisinya diatur package workflow
 	SubmittedAt        *time.Time `json:"submitted_at" db:"submitted_at"`
 	VerifiedAt         *time.Time `json:"verified_at" db:"verified_at"`
 	VerifiedBy         *uuid.UUID `json:"verified_by" db:"verified_by"`
 	RejectionNote      *string    `json:"rejection_note" db:"rejection_note"`
-	IsDeleted          bool       `json:"is_deleted" db:"is_deleted"`     // Soft delete flag
-	DeletedAt          *time.Time `json:"deleted_at" db:"deleted_at"`     // Soft delete timestamp
+	DelegationID       *uuid.UUID `json:"delegation_id,omitempty" db:"delegation_id"` // Diisi jika diverifikasi/ditolak dosen pengganti
+	IsDeleted          bool       `json:"is_deleted" db:"is_deleted"`                 // Soft delete flag
+	DeletedAt          *time.Time `json:"deleted_at" db:"deleted_at"`                 // Soft delete timestamp
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AccessTokenRepository struct {
+	DB *sql.DB
+}
+
+func NewAccessTokenRepository(db *sql.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{DB: db}
+}
+
+// CreateAccessToken - Simpan personal access token baru (hash saja)
+func (r *AccessTokenRepository) CreateAccessToken(token *model.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (id, user_id, label, token_prefix, token_hash, permissions, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	token.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		token.ID,
+		token.UserID,
+		token.Label,
+		token.TokenPrefix,
+		token.TokenHash,
+		pq.Array(token.Permissions),
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// GetAccessTokenByHash - Ambil token berdasarkan hash, termasuk yang sudah dicabut atau kadaluarsa
+func (r *AccessTokenRepository) GetAccessTokenByHash(tokenHash string) (*model.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, label, token_prefix, token_hash, permissions, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1
+	`
+
+	token, err := scanAccessToken(r.DB.QueryRow(query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("access token not found")
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// GetUserAccessTokens - Ambil semua token milik user yang belum dibersihkan, terbaru dulu
+func (r *AccessTokenRepository) GetUserAccessTokens(userID uuid.UUID) ([]model.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, label, token_prefix, token_hash, permissions, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []model.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevokeUserAccessToken - Cabut token milik user tertentu.
+// Return false jika token bukan milik user atau sudah dicabut.
+func (r *AccessTokenRepository) RevokeUserAccessToken(tokenID, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), tokenID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RevokeAccessToken - Cabut token milik siapa pun (admin). Return false jika tidak ada atau sudah dicabut.
+func (r *AccessTokenRepository) RevokeAccessToken(tokenID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// TouchAccessToken - Perbarui last_used_at, paling sering sekali per menit agar tidak menulis di setiap request
+func (r *AccessTokenRepository) TouchAccessToken(tokenID uuid.UUID) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+
+	now := time.Now()
+	_, err := r.DB.Exec(query, now, tokenID, now.Add(-time.Minute))
+	return err
+}
+
+// accessTokenScanner - Dipenuhi oleh *sql.Row dan *sql.Rows
+type accessTokenScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccessToken(row accessTokenScanner) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Label,
+		&token.TokenPrefix,
+		&token.TokenHash,
+		pq.Array(&token.Permissions),
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/workflow"
 	"context"
 	"database/sql"
 	"errors"
@@ -9,11 +10,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// ErrAchievementReferenceNotFound - Baris achievement_references tidak ada
+var ErrAchievementReferenceNotFound = errors.New("achievement reference not found")
+
 type AchievementRepository struct {
 	PostgresDB *sql.DB
 	MongoDB    *mongo.Database
@@ -48,8 +53,8 @@ func (r *AchievementRepository) CreateAchievement(ctx context.Context, achieveme
 	return objectID, nil
 }
 
-// CreateAchievementReference - Simpan reference ke PostgreSQL
-func (r *AchievementRepository) CreateAchievementReference(ref *model.AchievementReference) error {
+// CreateAchievementReference - Simpan reference ke PostgreSQL beserta riwayat status awalnya dalam satu transaksi
+func (r *AchievementRepository) CreateAchievementReference(ref *model.AchievementReference, actor model.StatusActor) error {
 	query := `
 		INSERT INTO achievement_references 
 		(id, student_id, mongo_achievement_id, status, created_at, updated_at)
@@ -60,7 +65,13 @@ func (r *AchievementRepository) CreateAchievementReference(ref *model.Achievemen
 	ref.CreatedAt = now
 	ref.UpdatedAt = now
 
-	_, err := r.PostgresDB.Exec(query,
+	tx, err := r.PostgresDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(query,
 		ref.ID,
 		ref.StudentID,
 		ref.MongoAchievementID,
@@ -68,10 +79,88 @@ func (r *AchievementRepository) CreateAchievementReference(ref *model.Achievemen
 		ref.CreatedAt,
 		ref.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertStatusHistory(tx, ref.ID, nil, ref.Status, actor, nil, now); err != nil {
+		return err
+	}
 
+	return tx.Commit()
+}
+
+// insertStatusHistory - Catat satu perubahan status prestasi di dalam transaksi perubahannya
+func insertStatusHistory(tx *sql.Tx, refID uuid.UUID, fromStatus *string, toStatus string, actor model.StatusActor, note *string, at time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO achievement_status_history
+		(id, achievement_ref_id, from_status, to_status, actor_user_id, actor_role, note, delegation_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, uuid.New(), refID, fromStatus, toStatus, actor.UserID, actor.Role, note, actor.DelegationID, at)
 	return err
 }
 
+// lockReferenceStatus - Kunci baris reference sampai transaksi selesai dan pastikan statusnya masih fromStatus
+// (status yang divalidasi workflow di service). Jika sudah berubah/dihapus oleh request lain, ErrInvalidTransition.
+func lockReferenceStatus(tx *sql.Tx, id uuid.UUID, fromStatus string) error {
+	var status string
+	var isDeleted bool
+	err := tx.QueryRow(`SELECT status, is_deleted FROM achievement_references WHERE id = $1 FOR UPDATE`, id).Scan(&status, &isDeleted)
+	if err == sql.ErrNoRows {
+		return ErrAchievementReferenceNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if isDeleted {
+		return fmt.Errorf("%w: achievement was deleted", workflow.ErrInvalidTransition)
+	}
+	if status != fromStatus {
+		return fmt.Errorf("%w: achievement status changed from '%s' to '%s'", workflow.ErrInvalidTransition, fromStatus, status)
+	}
+	return nil
+}
+
+// GetAchievementStatusHistory - Riwayat status prestasi, urut dari yang paling lama
+func (r *AchievementRepository) GetAchievementStatusHistory(refID uuid.UUID) ([]model.AchievementStatusHistory, error) {
+	rows, err := r.PostgresDB.Query(`
+		SELECT h.id, h.achievement_ref_id, h.from_status, h.to_status, h.actor_user_id,
+		       COALESCE(u.full_name, ''), h.actor_role, h.note, h.delegation_id, h.created_at
+		FROM achievement_status_history h
+		LEFT JOIN users u ON u.id = h.actor_user_id
+		WHERE h.achievement_ref_id = $1
+		ORDER BY h.created_at, h.id
+	`, refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []model.AchievementStatusHistory{}
+	for rows.Next() {
+		var entry model.AchievementStatusHistory
+		err := rows.Scan(
+			&entry.ID,
+			&entry.AchievementRefID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.ActorUserID,
+			&entry.ActorName,
+			&entry.ActorRole,
+			&entry.Note,
+			&entry.DelegationID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
+
 // GetAchievementByID - Ambil achievement dari MongoDB
 func (r *AchievementRepository) GetAchievementByID(ctx context.Context, id primitive.ObjectID) (*model.Achievement, error) {
 	collection := r.MongoDB.Collection("achievements")
@@ -92,8 +181,8 @@ func (r *AchievementRepository) GetAchievementByID(ctx context.Context, id primi
 func (r *AchievementRepository) GetAchievementReferenceByID(id uuid.UUID) (*model.AchievementReference, error) {
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status, 
-		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       submitted_at, verified_at, verified_by, rejection_note, delegation_id,
+		       is_deleted, deleted_at, created_at, updated_at
 		FROM achievement_references
 		WHERE id = $1
 	`
@@ -108,13 +197,16 @@ func (r *AchievementRepository) GetAchievementReferenceByID(id uuid.UUID) (*mode
 		&ref.VerifiedAt,
 		&ref.VerifiedBy,
 		&ref.RejectionNote,
+		&ref.DelegationID,
+		&ref.IsDeleted,
+		&ref.DeletedAt,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("achievement reference not found")
+			return nil, ErrAchievementReferenceNotFound
 		}
 		return nil, err
 	}
@@ -203,26 +295,73 @@ func (r *AchievementRepository) UpdateAchievement(ctx context.Context, id primit
 	return nil
 }
 
-// UpdateAchievementReferenceStatus - Update status reference di PostgreSQL
-func (r *AchievementRepository) UpdateAchievementReferenceStatus(id uuid.UUID, status string, verifiedBy *uuid.UUID, rejectionNote *string) error {
+// UpdateAchievementIfStatus - Update achievement di MongoDB jika reference masih berstatus fromStatus. Baris reference
+// dikunci selama update supaya prestasi tidak bisa diajukan atau dihapus di tengah edit, updated_at ikut diperbarui.
+func (r *AchievementRepository) UpdateAchievementIfStatus(ctx context.Context, refID uuid.UUID, fromStatus string, id primitive.ObjectID, achievement *model.Achievement) error {
+	tx, err := r.PostgresDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockReferenceStatus(tx, refID, fromStatus); err != nil {
+		return err
+	}
+
+	if err := r.UpdateAchievement(ctx, id, achievement); err != nil {
+		return errors.New("failed to update achievement in MongoDB: " + err.Error())
+	}
+
+	if _, err := tx.Exec(`UPDATE achievement_references SET updated_at = $1 WHERE id = $2`, achievement.UpdatedAt, refID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateAchievementReferenceStatus - Update status reference di PostgreSQL dan catat riwayatnya (note) dalam satu
+// transaksi. Validasi transisi dilakukan workflow di service, di sini status hanya ditulis jika masih fromStatus
+// (compare-and-set terhadap baris yang dikunci). submitted_at diisi setiap kali prestasi (ulang) diajukan
+// ke status 'submitted', verified_at diisi jika status diputuskan dosen (verifiedBy tidak nil). delegation_id reference
+// mengikuti keputusan terakhir (actor.DelegationID), riwayatnya menyimpan delegasi per keputusan.
+func (r *AchievementRepository) UpdateAchievementReferenceStatus(id uuid.UUID, fromStatus, status string, verifiedBy *uuid.UUID, rejectionNote *string, actor model.StatusActor, note *string) error {
 	query := `
 		UPDATE achievement_references
 		SET status = $1, 
 		    verified_at = $2,
 		    verified_by = $3,
 		    rejection_note = $4,
+		    delegation_id = $6,
+		    submitted_at = CASE WHEN $1 = 'submitted' THEN $5 ELSE submitted_at END,
 		    updated_at = $5
-		WHERE id = $6
+		WHERE id = $7
 	`
 
 	now := time.Now()
 	var verifiedAt *time.Time
-	if status == "verified" || status == "rejected" {
+	if verifiedBy != nil {
 		verifiedAt = &now
 	}
 
-	_, err := r.PostgresDB.Exec(query, status, verifiedAt, verifiedBy, rejectionNote, now, id)
-	return err
+	tx, err := r.PostgresDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockReferenceStatus(tx, id, fromStatus); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(query, status, verifiedAt, verifiedBy, rejectionNote, now, actor.DelegationID, id); err != nil {
+		return err
+	}
+
+	if err := insertStatusHistory(tx, id, &fromStatus, status, actor, note, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetStudentByUserID - Ambil data student berdasarkan user_id
@@ -337,8 +476,10 @@ func (r *AchievementRepository) SoftDeleteAchievement(ctx context.Context, id pr
 	return nil
 }
 
-// SoftDeleteAchievementReference - Soft delete reference di PostgreSQL
-func (r *AchievementRepository) SoftDeleteAchievementReference(id uuid.UUID) error {
+// SoftDeleteAchievementReference - Soft delete prestasi jika reference masih berstatus fromStatus. Dokumen MongoDB
+// di-soft delete selagi baris reference dikunci, lalu reference di PostgreSQL ditandai dihapus dan dicatat di riwayat
+// status sebagai 'deleted' dalam transaksi yang sama.
+func (r *AchievementRepository) SoftDeleteAchievementReference(ctx context.Context, id uuid.UUID, fromStatus string, mongoID primitive.ObjectID, actor model.StatusActor) error {
 	query := `
 		UPDATE achievement_references
 		SET is_deleted = true,
@@ -347,9 +488,30 @@ func (r *AchievementRepository) SoftDeleteAchievementReference(id uuid.UUID) err
 		WHERE id = $3
 	`
 
+	tx, err := r.PostgresDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockReferenceStatus(tx, id, fromStatus); err != nil {
+		return err
+	}
+
+	if err := r.SoftDeleteAchievement(ctx, mongoID); err != nil {
+		return errors.New("failed to delete achievement in MongoDB: " + err.Error())
+	}
+
 	now := time.Now()
-	_, err := r.PostgresDB.Exec(query, now, now, id)
-	return err
+	if _, err := tx.Exec(query, now, now, id); err != nil {
+		return err
+	}
+
+	if err := insertStatusHistory(tx, id, &fromStatus, model.StatusDeleted, actor, nil, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // HardDeleteAchievement - Hard delete achievement di MongoDB (untuk cleanup)
@@ -419,7 +581,7 @@ func (r *AchievementRepository) GetAchievementReferencesByStudentIDs(studentIDs
 
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status, 
-		       submitted_at, verified_at, verified_by, rejection_note,
+		       submitted_at, verified_at, verified_by, rejection_note, delegation_id,
 		       is_deleted, deleted_at, created_at, updated_at
 		FROM achievement_references
 		WHERE student_id = ANY($1) AND is_deleted = false
@@ -445,6 +607,7 @@ func (r *AchievementRepository) GetAchievementReferencesByStudentIDs(studentIDs
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.DelegationID,
 			&ref.IsDeleted,
 			&ref.DeletedAt,
 			&ref.CreatedAt,
@@ -485,7 +648,7 @@ func (r *AchievementRepository) GetAchievementsByIDs(ctx context.Context, ids []
 	collection := r.MongoDB.Collection("achievements")
 
 	filter := bson.M{
-		"_id": bson.M{"$in": ids},
+		"_id":       bson.M{"$in": ids},
 		"isDeleted": false,
 	}
 
@@ -558,6 +721,11 @@ func (r *AchievementRepository) GetAllAchievementReferencesAdmin(limit, offset i
 			args = append(args, filter.AdvisorID)
 			argIndex++
 		}
+		if len(filter.ScopeProgramStudies) > 0 {
+			whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+			args = append(args, pq.Array(filter.ScopeProgramStudies))
+			argIndex++
+		}
 	}
 
 	// Count total
@@ -587,7 +755,7 @@ func (r *AchievementRepository) GetAllAchievementReferencesAdmin(limit, offset i
 		case "student_name":
 			orderClause = "ORDER BY u.full_name"
 		}
-		
+
 		if sort.Order == "asc" {
 			orderClause += " ASC"
 		} else {
@@ -664,6 +832,11 @@ func (r *AchievementRepository) GetAchievementSummaryAdmin(filter *model.AdminAc
 			args = append(args, filter.AdvisorID)
 			argIndex++
 		}
+		if len(filter.ScopeProgramStudies) > 0 {
+			whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+			args = append(args, pq.Array(filter.ScopeProgramStudies))
+			argIndex++
+		}
 	}
 
 	query := `
@@ -4,6 +4,7 @@ import (
 	model "UAS_BACKEND/domain/Model"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,7 +20,7 @@ func NewAuthRepository(db *sql.DB) *AuthRepository {
 // GetUserByIdentifier mencari user berdasarkan username atau email
 func (r *AuthRepository) GetUserByIdentifier(identifier string) (*model.Users, error) {
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at
 		FROM users
 		WHERE (username = $1 OR email = $1)
 		LIMIT 1
@@ -34,6 +35,7 @@ func (r *AuthRepository) GetUserByIdentifier(identifier string) (*model.Users, e
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -48,16 +50,50 @@ func (r *AuthRepository) GetUserByIdentifier(identifier string) (*model.Users, e
 	return &user, nil
 }
 
-// GetUserPermissions mengambil permissions berdasarkan role_id
-func (r *AuthRepository) GetUserPermissions(roleID uuid.UUID) ([]string, error) {
+// GetUserByID mencari user berdasarkan ID
+func (r *AuthRepository) GetUserByID(userID uuid.UUID) (*model.Users, error) {
 	query := `
-		SELECT p.name
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+
+	var user model.Users
+	err := r.DB.QueryRow(query, userID).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FullName,
+		&user.RoleID,
+		&user.IsActive,
+		&user.MustChangePassword,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetUserPermissions mengambil gabungan permissions dari semua role user (user_roles)
+func (r *AuthRepository) GetUserPermissions(userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT p.name
 		FROM permissions p
 		INNER JOIN role_permissions rp ON p.id = rp.permission_id
-		WHERE rp.role_id = $1
+		INNER JOIN user_roles ur ON ur.role_id = rp.role_id
+		WHERE ur.user_id = $1
+		ORDER BY p.name
 	`
 
-	rows, err := r.DB.Query(query, roleID)
+	rows, err := r.DB.Query(query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -74,3 +110,90 @@ func (r *AuthRepository) GetUserPermissions(roleID uuid.UUID) ([]string, error)
 
 	return permissions, nil
 }
+
+// GetPermissionVersions - Versi permission user dan jumlah versi semua role-nya untuk distempel ke access token
+func (r *AuthRepository) GetPermissionVersions(userID uuid.UUID) (int, int, error) {
+	query := `
+		SELECT u.permission_version, COALESCE(SUM(r.permission_version), 0)
+		FROM users u
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		LEFT JOIN roles r ON r.id = ur.role_id
+		WHERE u.id = $1
+		GROUP BY u.id
+	`
+
+	var userVersion, roleVersion int
+	err := r.DB.QueryRow(query, userID).Scan(&userVersion, &roleVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, errors.New("user not found")
+		}
+		return 0, 0, err
+	}
+
+	return userVersion, roleVersion, nil
+}
+
+// UpdatePassword - Ganti password oleh user sendiri dan hapus kewajiban ganti password
+func (r *AuthRepository) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, must_change_password = false, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := r.DB.Exec(query, passwordHash, time.Now(), userID)
+	return err
+}
+
+// GetAuthSource - Backend autentikasi user: users.auth_source, jika kosong ikut role
+func (r *AuthRepository) GetAuthSource(userID uuid.UUID) (string, error) {
+	query := `
+		SELECT COALESCE(NULLIF(u.auth_source, ''), r.auth_source, 'local')
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE u.id = $1
+	`
+
+	var source string
+	err := r.DB.QueryRow(query, userID).Scan(&source)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errors.New("user not found")
+		}
+		return "", err
+	}
+
+	return source, nil
+}
+
+// SyncDirectoryProfile - Salin nama dan email dari direktori eksternal, password lokal tidak dipakai
+func (r *AuthRepository) SyncDirectoryProfile(userID uuid.UUID, fullName, email string) error {
+	query := `
+		UPDATE users
+		SET full_name = $1, email = $2, must_change_password = false, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.DB.Exec(query, fullName, email, time.Now(), userID)
+	return err
+}
+
+// SyncLecturerID - Set NIP dosen dari direktori, buat data lecturer jika belum ada
+func (r *AuthRepository) SyncLecturerID(userID uuid.UUID, lecturerID string) error {
+	result, err := r.DB.Exec(`UPDATE lecturers SET lecturer_id = $1 WHERE user_id = $2`, lecturerID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	_, err = r.DB.Exec(`INSERT INTO lecturers (user_id, lecturer_id) VALUES ($1, $2)`, userID, lecturerID)
+	return err
+}
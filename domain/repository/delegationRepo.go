@@ -0,0 +1,189 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type DelegationRepository struct {
+	DB *sql.DB
+}
+
+func NewDelegationRepository(db *sql.DB) *DelegationRepository {
+	return &DelegationRepository{DB: db}
+}
+
+// delegationColumns - Kolom delegasi beserta subset mahasiswanya (array kosong = semua mahasiswa bimbingan)
+const delegationColumns = `
+	d.id, d.delegator_id, d.delegate_id, d.starts_at, d.ends_at,
+	ARRAY(SELECT ds.student_id FROM verification_delegation_students ds WHERE ds.delegation_id = d.id),
+	COALESCE(d.reason, ''), d.created_by, d.revoked_at, d.created_at
+`
+
+// CreateDelegation - Simpan delegasi beserta subset mahasiswanya dalam satu transaksi
+func (r *DelegationRepository) CreateDelegation(delegation *model.VerificationDelegation) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	delegation.ID = uuid.New()
+	delegation.CreatedAt = time.Now()
+
+	_, err = tx.Exec(`
+		INSERT INTO verification_delegations
+		(id, delegator_id, delegate_id, starts_at, ends_at, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		delegation.ID,
+		delegation.DelegatorID,
+		delegation.DelegateID,
+		delegation.StartsAt,
+		delegation.EndsAt,
+		delegation.Reason,
+		delegation.CreatedBy,
+		delegation.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, studentID := range delegation.StudentIDs {
+		_, err = tx.Exec(`
+			INSERT INTO verification_delegation_students (delegation_id, student_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, delegation.ID, studentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetDelegationByID - Ambil delegasi berdasarkan ID
+func (r *DelegationRepository) GetDelegationByID(id uuid.UUID) (*model.VerificationDelegation, error) {
+	query := `SELECT ` + delegationColumns + ` FROM verification_delegations d WHERE d.id = $1`
+
+	delegation, err := scanDelegation(r.DB.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("delegation not found")
+		}
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// GetDelegations - Delegasi di mana dosen menjadi delegator atau delegate (uuid.Nil = semua), terbaru dulu
+func (r *DelegationRepository) GetDelegations(lecturerID uuid.UUID) ([]model.VerificationDelegation, error) {
+	if lecturerID == uuid.Nil {
+		query := `SELECT ` + delegationColumns + ` FROM verification_delegations d ORDER BY d.starts_at DESC`
+		return r.queryDelegations(query)
+	}
+
+	query := `
+		SELECT ` + delegationColumns + `
+		FROM verification_delegations d
+		WHERE d.delegator_id = $1 OR d.delegate_id = $1
+		ORDER BY d.starts_at DESC
+	`
+
+	return r.queryDelegations(query, lecturerID)
+}
+
+// GetActiveDelegationsForDelegate - Delegasi yang sedang berlaku untuk dosen pengganti
+func (r *DelegationRepository) GetActiveDelegationsForDelegate(delegateID uuid.UUID, at time.Time) ([]model.VerificationDelegation, error) {
+	query := `
+		SELECT ` + delegationColumns + `
+		FROM verification_delegations d
+		WHERE d.delegate_id = $1 AND d.revoked_at IS NULL AND d.starts_at <= $2 AND d.ends_at > $2
+		ORDER BY d.starts_at
+	`
+
+	return r.queryDelegations(query, delegateID, at)
+}
+
+// GetActiveDelegationsForDelegator - Delegasi yang sedang berlaku dari dosen wali
+func (r *DelegationRepository) GetActiveDelegationsForDelegator(delegatorID uuid.UUID, at time.Time) ([]model.VerificationDelegation, error) {
+	query := `
+		SELECT ` + delegationColumns + `
+		FROM verification_delegations d
+		WHERE d.delegator_id = $1 AND d.revoked_at IS NULL AND d.starts_at <= $2 AND d.ends_at > $2
+		ORDER BY d.starts_at
+	`
+
+	return r.queryDelegations(query, delegatorID, at)
+}
+
+// RevokeDelegation - Cabut delegasi. Return false jika sudah dicabut sebelumnya.
+func (r *DelegationRepository) RevokeDelegation(id uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE verification_delegations
+		SET revoked_at = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *DelegationRepository) queryDelegations(query string, args ...interface{}) ([]model.VerificationDelegation, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delegations := []model.VerificationDelegation{}
+	for rows.Next() {
+		delegation, err := scanDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, *delegation)
+	}
+
+	return delegations, rows.Err()
+}
+
+// delegationScanner - Dipenuhi oleh *sql.Row dan *sql.Rows
+type delegationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDelegation(row delegationScanner) (*model.VerificationDelegation, error) {
+	var delegation model.VerificationDelegation
+	err := row.Scan(
+		&delegation.ID,
+		&delegation.DelegatorID,
+		&delegation.DelegateID,
+		&delegation.StartsAt,
+		&delegation.EndsAt,
+		pq.Array(&delegation.StudentIDs),
+		&delegation.Reason,
+		&delegation.CreatedBy,
+		&delegation.RevokedAt,
+		&delegation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delegation, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailChangeRepository struct {
+	DB *sql.DB
+}
+
+func NewEmailChangeRepository(db *sql.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{DB: db}
+}
+
+// CreateEmailChange - Simpan permintaan ganti email baru (hanya hash token-nya).
+// Permintaan lain dari user yang belum dipakai dibatalkan, hanya link terbaru yang berlaku.
+func (r *EmailChangeRepository) CreateEmailChange(token *model.EmailChangeToken) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	token.ID = uuid.New()
+	token.CreatedAt = time.Now()
+
+	_, err = tx.Exec(`
+		UPDATE email_change_tokens
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL
+	`, token.CreatedAt, token.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO email_change_tokens (id, user_id, new_email, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		token.ID,
+		token.UserID,
+		token.NewEmail,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetEmailChangeByHash - Ambil permintaan ganti email berdasarkan hash token
+func (r *EmailChangeRepository) GetEmailChangeByHash(tokenHash string) (*model.EmailChangeToken, error) {
+	query := `
+		SELECT id, user_id, new_email, token_hash, expires_at, used_at, created_at
+		FROM email_change_tokens
+		WHERE token_hash = $1
+	`
+
+	var token model.EmailChangeToken
+	err := r.DB.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.NewEmail,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("email change token not found")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkEmailChangeUsed - Tandai token sudah dipakai. Return false jika sudah dipakai atau kadaluarsa.
+func (r *EmailChangeRepository) MarkEmailChangeUsed(id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE email_change_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL AND expires_at > $1
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
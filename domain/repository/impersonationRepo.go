@@ -0,0 +1,111 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImpersonationRepository struct {
+	DB *sql.DB
+}
+
+func NewImpersonationRepository(db *sql.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{DB: db}
+}
+
+// CreateLog - Tambah satu baris audit log impersonation
+func (r *ImpersonationRepository) CreateLog(entry *model.ImpersonationLog) error {
+	query := `
+		INSERT INTO impersonation_audit_log
+			(id, admin_id, user_id, token_id, event, method, path, status_code, reason, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		entry.ID,
+		entry.AdminID,
+		entry.UserID,
+		entry.TokenID,
+		entry.Event,
+		entry.Method,
+		entry.Path,
+		entry.StatusCode,
+		entry.Reason,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.CreatedAt,
+	)
+
+	return err
+}
+
+// GetLogs - Audit log terbaru dulu, filter admin/user opsional (uuid.Nil = semua)
+func (r *ImpersonationRepository) GetLogs(adminID, userID uuid.UUID, limit, offset int) ([]model.ImpersonationLog, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if adminID != uuid.Nil {
+		args = append(args, adminID)
+		conditions = append(conditions, fmt.Sprintf("admin_id = $%d", len(args)))
+	}
+	if userID != uuid.Nil {
+		args = append(args, userID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM impersonation_audit_log `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, admin_id, user_id, token_id, event, method, path, status_code, reason, ip_address, user_agent, created_at
+		FROM impersonation_audit_log
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.DB.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []model.ImpersonationLog{}
+	for rows.Next() {
+		var entry model.ImpersonationLog
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.AdminID,
+			&entry.UserID,
+			&entry.TokenID,
+			&entry.Event,
+			&entry.Method,
+			&entry.Path,
+			&entry.StatusCode,
+			&entry.Reason,
+			&entry.IPAddress,
+			&entry.UserAgent,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, entry)
+	}
+
+	return logs, total, rows.Err()
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvitationRepository struct {
+	DB *sql.DB
+}
+
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	return &InvitationRepository{DB: db}
+}
+
+// SaveInvitation - Simpan undangan user. Kirim ulang mengganti token dan masa berlaku
+// undangan sebelumnya, sehingga link lama otomatis tidak berlaku.
+func (r *InvitationRepository) SaveInvitation(invitation *model.UserInvitation) error {
+	query := `
+		INSERT INTO user_invitations (id, user_id, token_hash, invited_by, expires_at, sent_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash,
+			invited_by = EXCLUDED.invited_by,
+			expires_at = EXCLUDED.expires_at,
+			sent_at = EXCLUDED.sent_at
+		WHERE user_invitations.accepted_at IS NULL
+		RETURNING id, created_at
+	`
+
+	invitation.SentAt = time.Now()
+
+	err := r.DB.QueryRow(query,
+		uuid.New(),
+		invitation.UserID,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+		invitation.SentAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("invitation already accepted")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetInvitationByHash - Ambil undangan berdasarkan hash token
+func (r *InvitationRepository) GetInvitationByHash(tokenHash string) (*model.UserInvitation, error) {
+	query := `
+		SELECT id, user_id, token_hash, invited_by, expires_at, accepted_at, sent_at, created_at
+		FROM user_invitations
+		WHERE token_hash = $1
+	`
+
+	var invitation model.UserInvitation
+	err := r.DB.QueryRow(query, tokenHash).Scan(
+		&invitation.ID,
+		&invitation.UserID,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.SentAt,
+		&invitation.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// GetInvitationByUserID - Ambil undangan milik user
+func (r *InvitationRepository) GetInvitationByUserID(userID uuid.UUID) (*model.UserInvitation, error) {
+	query := `
+		SELECT id, user_id, token_hash, invited_by, expires_at, accepted_at, sent_at, created_at
+		FROM user_invitations
+		WHERE user_id = $1
+	`
+
+	var invitation model.UserInvitation
+	err := r.DB.QueryRow(query, userID).Scan(
+		&invitation.ID,
+		&invitation.UserID,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.SentAt,
+		&invitation.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// AcceptInvitation - Pakai undangan, set password, dan aktifkan user dalam satu transaksi.
+// Return false jika undangan sudah dipakai atau kadaluarsa.
+func (r *InvitationRepository) AcceptInvitation(invitationID, userID uuid.UUID, passwordHash string) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	result, err := tx.Exec(`
+		UPDATE user_invitations
+		SET accepted_at = $1
+		WHERE id = $2 AND accepted_at IS NULL AND expires_at > $1
+	`, now, invitationID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET password_hash = $1, is_active = true, must_change_password = false, updated_at = $2
+		WHERE id = $3
+	`, passwordHash, now, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
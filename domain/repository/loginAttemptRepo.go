@@ -0,0 +1,97 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type LoginAttemptRepository struct {
+	DB *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+// GetLoginAttempt - Ambil status percobaan login. Return nil jika belum pernah gagal.
+func (r *LoginAttemptRepository) GetLoginAttempt(scope, key string) (*model.LoginAttempt, error) {
+	query := `
+		SELECT scope, key, failed_count, last_failed_at, next_attempt_at, locked_until
+		FROM login_attempts
+		WHERE scope = $1 AND key = $2
+	`
+
+	var attempt model.LoginAttempt
+	err := r.DB.QueryRow(query, scope, key).Scan(
+		&attempt.Scope,
+		&attempt.Key,
+		&attempt.FailedCount,
+		&attempt.LastFailedAt,
+		&attempt.NextAttemptAt,
+		&attempt.LockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordFailure - Tambah counter gagal secara atomic, return jumlah gagal terbaru.
+// Counter mulai dari 1 lagi jika gagal terakhir lebih lama dari window.
+func (r *LoginAttemptRepository) RecordFailure(scope, key string, window time.Duration) (int, error) {
+	query := `
+		INSERT INTO login_attempts (scope, key, failed_count, last_failed_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failed_count = CASE
+				WHEN login_attempts.last_failed_at < $4 THEN 1
+				ELSE login_attempts.failed_count + 1
+			END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING failed_count
+	`
+
+	now := time.Now()
+
+	var failedCount int
+	err := r.DB.QueryRow(query, scope, key, now, now.Add(-window)).Scan(&failedCount)
+	return failedCount, err
+}
+
+// SetBlock - Simpan waktu paling cepat untuk login berikutnya dan lockout (nil = tidak dikunci)
+func (r *LoginAttemptRepository) SetBlock(scope, key string, nextAttemptAt time.Time, lockedUntil *time.Time) error {
+	query := `
+		UPDATE login_attempts
+		SET next_attempt_at = $1, locked_until = $2
+		WHERE scope = $3 AND key = $4
+	`
+
+	_, err := r.DB.Exec(query, nextAttemptAt, lockedUntil, scope, key)
+	return err
+}
+
+// ClearLoginAttempts - Reset counter (login berhasil atau unlock oleh admin)
+func (r *LoginAttemptRepository) ClearLoginAttempts(scope, key string) error {
+	_, err := r.DB.Exec(`DELETE FROM login_attempts WHERE scope = $1 AND key = $2`, scope, key)
+	return err
+}
+
+// DeleteStaleLoginAttempts - Hapus baris yang gagal terakhirnya sudah lama dan tidak sedang dikunci
+func (r *LoginAttemptRepository) DeleteStaleLoginAttempts(olderThan time.Duration) error {
+	now := time.Now()
+
+	query := `
+		DELETE FROM login_attempts
+		WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < $2)
+	`
+
+	_, err := r.DB.Exec(query, now.Add(-olderThan), now)
+	return err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MailRepository struct {
+	DB *sql.DB
+}
+
+func NewMailRepository(db *sql.DB) *MailRepository {
+	return &MailRepository{DB: db}
+}
+
+// EnqueueMail - Masukkan email ke outbox untuk dikirim oleh worker
+func (r *MailRepository) EnqueueMail(msg *model.MailMessage) error {
+	query := `
+		INSERT INTO mail_outbox (id, recipient, subject, body, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	msg.ID = uuid.New()
+	msg.Status = "pending"
+	msg.Attempts = 0
+	msg.CreatedAt = time.Now()
+	msg.NextAttemptAt = msg.CreatedAt
+
+	_, err := r.DB.Exec(query,
+		msg.ID,
+		msg.To,
+		msg.Subject,
+		msg.Body,
+		msg.Status,
+		msg.Attempts,
+		msg.NextAttemptAt,
+		msg.CreatedAt,
+	)
+
+	return err
+}
+
+// GetPendingMails - Ambil email pending yang sudah waktunya dikirim (paling lama dulu)
+func (r *MailRepository) GetPendingMails(limit int) ([]model.MailMessage, error) {
+	query := `
+		SELECT id, recipient, subject, body, status, attempts, last_error, next_attempt_at, sent_at, created_at
+		FROM mail_outbox
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.DB.Query(query, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mails []model.MailMessage
+	for rows.Next() {
+		var msg model.MailMessage
+		err := rows.Scan(
+			&msg.ID,
+			&msg.To,
+			&msg.Subject,
+			&msg.Body,
+			&msg.Status,
+			&msg.Attempts,
+			&msg.LastError,
+			&msg.NextAttemptAt,
+			&msg.SentAt,
+			&msg.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		mails = append(mails, msg)
+	}
+
+	return mails, rows.Err()
+}
+
+// MarkMailSent - Tandai email sudah terkirim
+func (r *MailRepository) MarkMailSent(id uuid.UUID) error {
+	query := `
+		UPDATE mail_outbox
+		SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = $1
+		WHERE id = $2
+	`
+
+	_, err := r.DB.Exec(query, time.Now(), id)
+	return err
+}
+
+// MarkMailFailed - Catat percobaan kirim yang gagal.
+// Jika giveUp true status menjadi 'failed' dan email tidak dicoba lagi.
+func (r *MailRepository) MarkMailFailed(id uuid.UUID, lastError string, nextAttemptAt time.Time, giveUp bool) error {
+	status := "pending"
+	if giveUp {
+		status = "failed"
+	}
+
+	query := `
+		UPDATE mail_outbox
+		SET status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.DB.Exec(query, status, lastError, nextAttemptAt, id)
+	return err
+}
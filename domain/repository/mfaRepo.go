@@ -0,0 +1,295 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository struct {
+	DB *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) *MFARepository {
+	return &MFARepository{DB: db}
+}
+
+// GetUserMFA - Ambil konfigurasi MFA user. Return nil jika user belum pernah enroll.
+func (r *MFARepository) GetUserMFA(userID uuid.UUID) (*model.UserMFA, error) {
+	query := `
+		SELECT user_id, secret, enabled, confirmed_at, last_used_step, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var mfa model.UserMFA
+	err := r.DB.QueryRow(query, userID).Scan(
+		&mfa.UserID,
+		&mfa.Secret,
+		&mfa.Enabled,
+		&mfa.ConfirmedAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+		&mfa.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &mfa, nil
+}
+
+// SavePendingMFA - Simpan secret baru yang belum dikonfirmasi.
+// Return false jika MFA user sudah aktif (secret aktif tidak boleh ditimpa).
+func (r *MFARepository) SavePendingMFA(userID uuid.UUID, secret string) (bool, error) {
+	query := `
+		INSERT INTO user_mfa (user_id, secret, enabled, last_used_step, created_at, updated_at)
+		VALUES ($1, $2, false, 0, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			updated_at = EXCLUDED.updated_at
+		WHERE user_mfa.enabled = false
+	`
+
+	result, err := r.DB.Exec(query, userID, secret, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// EnableMFA - Aktifkan MFA dan ganti semua recovery code dalam satu transaksi
+func (r *MFARepository) EnableMFA(userID uuid.UUID, usedStep int64, recoveryCodeHashes []string) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	result, err := tx.Exec(`
+		UPDATE user_mfa
+		SET enabled = true, confirmed_at = $1, last_used_step = $2, updated_at = $1
+		WHERE user_id = $3 AND enabled = false
+	`, now, usedStep, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes, now); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// MarkStepUsed - Simpan periode TOTP yang baru dipakai.
+// Return false jika periode ini (atau yang lebih baru) sudah pernah dipakai (replay).
+func (r *MFARepository) MarkStepUsed(userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $1, updated_at = $2
+		WHERE user_id = $3 AND enabled = true AND last_used_step < $1
+	`
+
+	result, err := r.DB.Exec(query, step, time.Now(), userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// UseRecoveryCode - Pakai recovery code (sekali pakai). Return false jika tidak ada atau sudah dipakai.
+func (r *MFARepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// ReplaceRecoveryCodes - Hapus recovery code lama dan simpan yang baru
+func (r *MFARepository) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteUserMFA - Nonaktifkan MFA: hapus secret dan recovery code
+func (r *MFARepository) DeleteUserMFA(userID uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// IsMFARequiredForUser - Cek apakah salah satu role user mewajibkan MFA
+func (r *MFARepository) IsMFARequiredForUser(userID uuid.UUID) (bool, error) {
+	query := `
+		SELECT COALESCE(bool_or(r.mfa_required), false)
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+	`
+
+	var required bool
+	err := r.DB.QueryRow(query, userID).Scan(&required)
+	return required, err
+}
+
+// CreateChallenge - Simpan challenge MFA baru (hanya hash token-nya)
+func (r *MFARepository) CreateChallenge(challenge *model.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, token_hash, purpose, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6)
+	`
+
+	challenge.ID = uuid.New()
+	challenge.Attempts = 0
+	challenge.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		challenge.ID,
+		challenge.UserID,
+		challenge.TokenHash,
+		challenge.Purpose,
+		challenge.ExpiresAt,
+		challenge.CreatedAt,
+	)
+
+	return err
+}
+
+// GetChallengeByHash - Ambil challenge MFA berdasarkan hash token
+func (r *MFARepository) GetChallengeByHash(tokenHash string) (*model.MFAChallenge, error) {
+	query := `
+		SELECT id, user_id, token_hash, purpose, attempts, expires_at, used_at, created_at
+		FROM mfa_challenges
+		WHERE token_hash = $1
+	`
+
+	var challenge model.MFAChallenge
+	err := r.DB.QueryRow(query, tokenHash).Scan(
+		&challenge.ID,
+		&challenge.UserID,
+		&challenge.TokenHash,
+		&challenge.Purpose,
+		&challenge.Attempts,
+		&challenge.ExpiresAt,
+		&challenge.UsedAt,
+		&challenge.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("mfa challenge not found")
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts - Catat kode salah pada challenge
+func (r *MFARepository) IncrementChallengeAttempts(id uuid.UUID) error {
+	_, err := r.DB.Exec(`UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1`, id)
+	return err
+}
+
+// MarkChallengeUsed - Tandai challenge selesai. Return false jika sudah dipakai lebih dulu.
+func (r *MFARepository) MarkChallengeUsed(id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE mfa_challenges
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID uuid.UUID, codeHashes []string, now time.Time) error {
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(`
+			INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, uuid.New(), userID, hash, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OIDCRepository struct {
+	DB *sql.DB
+}
+
+func NewOIDCRepository(db *sql.DB) *OIDCRepository {
+	return &OIDCRepository{DB: db}
+}
+
+// CreateAuthRequest - Simpan state, nonce, dan PKCE verifier sebelum redirect ke identity provider
+func (r *OIDCRepository) CreateAuthRequest(req *model.OIDCAuthRequest) error {
+	query := `
+		INSERT INTO oidc_auth_requests (id, state_hash, code_verifier, nonce, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	req.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		req.ID,
+		req.StateHash,
+		req.CodeVerifier,
+		req.Nonce,
+		req.ExpiresAt,
+		req.CreatedAt,
+	)
+
+	return err
+}
+
+// ConsumeAuthRequest - Ambil dan hapus auth request dalam satu query sehingga state hanya bisa dipakai sekali
+func (r *OIDCRepository) ConsumeAuthRequest(stateHash string) (*model.OIDCAuthRequest, error) {
+	query := `
+		DELETE FROM oidc_auth_requests
+		WHERE state_hash = $1
+		RETURNING id, state_hash, code_verifier, nonce, expires_at, created_at
+	`
+
+	var req model.OIDCAuthRequest
+	err := r.DB.QueryRow(query, stateHash).Scan(
+		&req.ID,
+		&req.StateHash,
+		&req.CodeVerifier,
+		&req.Nonce,
+		&req.ExpiresAt,
+		&req.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("oidc auth request not found")
+		}
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// GetIdentity - Cari identity berdasarkan issuer dan subject identity provider
+func (r *OIDCRepository) GetIdentity(issuer, subject string) (*model.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, issuer, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2
+	`
+
+	var identity model.UserIdentity
+	err := r.DB.QueryRow(query, issuer, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("identity not found")
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// CreateIdentity - Hubungkan akun identity provider dengan user yang sudah ada
+func (r *OIDCRepository) CreateIdentity(identity *model.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`
+
+	identity.CreatedAt = time.Now()
+	identity.LastLoginAt = identity.CreatedAt
+
+	_, err := r.DB.Exec(query,
+		identity.ID,
+		identity.UserID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	)
+
+	return err
+}
+
+// TouchIdentity - Catat waktu login dan email terbaru dari identity provider
+func (r *OIDCRepository) TouchIdentity(identityID uuid.UUID, email string) error {
+	query := `
+		UPDATE user_identities
+		SET last_login_at = $1, email = $2
+		WHERE id = $3
+	`
+
+	_, err := r.DB.Exec(query, time.Now(), email, identityID)
+	return err
+}
+
+// CreateUserWithIdentity - Buat user baru dari identity provider beserta identity-nya dalam satu transaksi
+func (r *OIDCRepository) CreateUserWithIdentity(user *model.Users, identity *model.UserIdentity) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	_, err = tx.Exec(`
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+	`, user.ID, user.Username, user.Email, user.PasswordHash, user.FullName, user.RoleID, user.IsActive, user.MustChangePassword, now)
+	if err != nil {
+		return err
+	}
+
+	identity.UserID = user.ID
+	identity.CreatedAt = now
+	identity.LastLoginAt = now
+
+	_, err = tx.Exec(`
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`, identity.ID, identity.UserID, identity.Issuer, identity.Subject, identity.Email, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRoleIDByName - Ambil ID role berdasarkan nama, untuk role default user hasil provisioning
+func (r *OIDCRepository) GetRoleIDByName(name string) (uuid.UUID, error) {
+	var roleID uuid.UUID
+	err := r.DB.QueryRow(`SELECT id FROM roles WHERE name = $1`, name).Scan(&roleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, errors.New("role not found")
+		}
+		return uuid.Nil, err
+	}
+
+	return roleID, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordHistoryRepository struct {
+	DB *sql.DB
+}
+
+func NewPasswordHistoryRepository(db *sql.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{DB: db}
+}
+
+// GetRecentPasswordHashes - Ambil hash password terakhir user, terbaru lebih dulu
+func (r *PasswordHistoryRepository) GetRecentPasswordHashes(userID uuid.UUID, limit int) ([]string, error) {
+	query := `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.DB.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// AddPasswordHistory - Simpan hash password baru dan hapus riwayat di luar keep entry terbaru
+func (r *PasswordHistoryRepository) AddPasswordHistory(userID uuid.UUID, passwordHash string, keep int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, uuid.New(), userID, passwordHash, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`, userID, keep)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
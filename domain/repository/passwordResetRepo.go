@@ -0,0 +1,117 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepository struct {
+	DB *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{DB: db}
+}
+
+// CreateResetToken - Simpan token reset password baru (hanya hash-nya)
+func (r *PasswordResetRepository) CreateResetToken(token *model.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	token.ID = uuid.New()
+	token.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// GetResetTokenByHash - Ambil token reset password berdasarkan hash
+func (r *PasswordResetRepository) GetResetTokenByHash(tokenHash string) (*model.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+	`
+
+	var token model.PasswordResetToken
+	err := r.DB.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("reset token not found")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ResetPassword - Pakai token lalu ganti password dalam satu transaksi.
+// Return false jika token sudah dipakai atau kadaluarsa (mis. dua request reset paralel).
+// Token reset lain milik user yang belum dipakai ikut dibatalkan.
+func (r *PasswordResetRepository) ResetPassword(tokenID, userID uuid.UUID, passwordHash string) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	result, err := tx.Exec(`
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL AND expires_at > $1
+	`, now, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET password_hash = $1, must_change_password = false, updated_at = $2
+		WHERE id = $3
+	`, passwordHash, now, userID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
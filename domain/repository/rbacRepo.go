@@ -4,6 +4,7 @@ import (
 	model "UAS_BACKEND/domain/Model"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,7 +20,7 @@ func NewRBACRepository(db *sql.DB) *RBACRepository {
 // GetRoleByID - Mendapatkan role berdasarkan ID
 func (r *RBACRepository) GetRoleByID(roleID uuid.UUID) (*model.Roles, error) {
 	query := `
-		SELECT id, name, description, created_at
+		SELECT id, name, description, mfa_required, created_at
 		FROM roles
 		WHERE id = $1
 	`
@@ -29,6 +30,7 @@ func (r *RBACRepository) GetRoleByID(roleID uuid.UUID) (*model.Roles, error) {
 		&role.ID,
 		&role.Name,
 		&role.Description,
+		&role.MFARequired,
 		&role.CreatedAt,
 	)
 
@@ -42,16 +44,18 @@ func (r *RBACRepository) GetRoleByID(roleID uuid.UUID) (*model.Roles, error) {
 	return &role, nil
 }
 
-// GetUserPermissions - Mendapatkan semua permissions berdasarkan role_id
-func (r *RBACRepository) GetUserPermissions(roleID uuid.UUID) ([]string, error) {
+// GetUserPermissions - Mendapatkan gabungan permissions dari semua role user (user_roles)
+func (r *RBACRepository) GetUserPermissions(userID uuid.UUID) ([]string, error) {
 	query := `
-		SELECT p.name
+		SELECT DISTINCT p.name
 		FROM permissions p
 		INNER JOIN role_permissions rp ON p.id = rp.permission_id
-		WHERE rp.role_id = $1
+		INNER JOIN user_roles ur ON ur.role_id = rp.role_id
+		WHERE ur.user_id = $1
+		ORDER BY p.name
 	`
 
-	rows, err := r.DB.Query(query, roleID)
+	rows, err := r.DB.Query(query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +73,82 @@ func (r *RBACRepository) GetUserPermissions(roleID uuid.UUID) ([]string, error)
 	return permissions, nil
 }
 
+// GetUserRoles - Mendapatkan semua role user (role utama dan role tambahan)
+func (r *RBACRepository) GetUserRoles(userID uuid.UUID) ([]model.Roles, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.mfa_required, r.created_at
+		FROM roles r
+		INNER JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name
+	`
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []model.Roles
+	for rows.Next() {
+		var role model.Roles
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.CreatedAt); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// GetPermissionState - Role, status aktif, versi permission, dan permissions user saat ini
+func (r *RBACRepository) GetPermissionState(userID uuid.UUID) (*model.PermissionState, error) {
+	query := `
+		SELECT u.role_id, u.is_active, u.permission_version, COALESCE(SUM(r.permission_version), 0)
+		FROM users u
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		LEFT JOIN roles r ON r.id = ur.role_id
+		WHERE u.id = $1
+		GROUP BY u.id
+	`
+
+	var state model.PermissionState
+	err := r.DB.QueryRow(query, userID).Scan(
+		&state.RoleID,
+		&state.IsActive,
+		&state.UserVersion,
+		&state.RoleVersion,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	roles, err := r.GetUserRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		state.RoleIDs = append(state.RoleIDs, role.ID)
+		state.RoleNames = append(state.RoleNames, role.Name)
+	}
+
+	state.Permissions, err = r.GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state.AdminScope, err = r.GetAdminScope(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // GetPermissionByName - Mendapatkan permission berdasarkan name
 func (r *RBACRepository) GetPermissionByName(name string) (*model.Permission, error) {
 	query := `
@@ -99,7 +179,7 @@ func (r *RBACRepository) GetPermissionByName(name string) (*model.Permission, er
 // GetAllRoles - Mendapatkan semua roles
 func (r *RBACRepository) GetAllRoles() ([]model.Roles, error) {
 	query := `
-		SELECT id, name, description, created_at
+		SELECT id, name, description, mfa_required, created_at
 		FROM roles
 		ORDER BY name
 	`
@@ -113,7 +193,7 @@ func (r *RBACRepository) GetAllRoles() ([]model.Roles, error) {
 	var roles []model.Roles
 	for rows.Next() {
 		var role model.Roles
-		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.CreatedAt); err != nil {
 			return nil, err
 		}
 		roles = append(roles, role)
@@ -175,3 +255,249 @@ func (r *RBACRepository) GetRolePermissions(roleID uuid.UUID) ([]model.Permissio
 
 	return permissions, nil
 }
+
+// GetRoleByName - Mendapatkan role berdasarkan name
+func (r *RBACRepository) GetRoleByName(name string) (*model.Roles, error) {
+	query := `
+		SELECT id, name, description, mfa_required, created_at
+		FROM roles
+		WHERE name = $1
+	`
+
+	var role model.Roles
+	err := r.DB.QueryRow(query, name).Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.MFARequired,
+		&role.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("role not found")
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// CreateRole - Simpan role baru
+func (r *RBACRepository) CreateRole(role *model.Roles) error {
+	query := `
+		INSERT INTO roles (id, name, description, mfa_required, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	role.ID = uuid.New()
+	role.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		role.ID,
+		role.Name,
+		role.Description,
+		role.MFARequired,
+		role.CreatedAt,
+	)
+
+	return err
+}
+
+// UpdateRole - Update name, description, dan mfa_required role
+func (r *RBACRepository) UpdateRole(role *model.Roles) error {
+	query := `
+		UPDATE roles
+		SET name = $1, description = $2, mfa_required = $3
+		WHERE id = $4
+	`
+
+	_, err := r.DB.Exec(query, role.Name, role.Description, role.MFARequired, role.ID)
+	return err
+}
+
+// DeleteRole - Hapus role jika tidak ada user yang memakainya (sebagai role utama maupun tambahan).
+// Return false jika role masih dipakai (dicek di query yang sama supaya tidak race dengan assign role).
+func (r *RBACRepository) DeleteRole(roleID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		DELETE FROM roles
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM users WHERE role_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM user_roles WHERE role_id = $1)
+	`, roleID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// CountRoleUsers - Jumlah user yang memakai role (role utama maupun tambahan)
+func (r *RBACRepository) CountRoleUsers(roleID uuid.UUID) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM user_roles WHERE role_id = $1`, roleID).Scan(&count)
+	return count, err
+}
+
+// GetPermissionByID - Mendapatkan permission berdasarkan ID
+func (r *RBACRepository) GetPermissionByID(permissionID uuid.UUID) (*model.Permission, error) {
+	query := `
+		SELECT id, name, resource, action, description
+		FROM permissions
+		WHERE id = $1
+	`
+
+	var perm model.Permission
+	err := r.DB.QueryRow(query, permissionID).Scan(
+		&perm.ID,
+		&perm.Name,
+		&perm.Resource,
+		&perm.Action,
+		&perm.Description,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("permission not found")
+		}
+		return nil, err
+	}
+
+	return &perm, nil
+}
+
+// CreatePermission - Simpan permission baru
+func (r *RBACRepository) CreatePermission(perm *model.Permission) error {
+	query := `
+		INSERT INTO permissions (id, name, resource, action, description)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	perm.ID = uuid.New()
+
+	_, err := r.DB.Exec(query, perm.ID, perm.Name, perm.Resource, perm.Action, perm.Description)
+	return err
+}
+
+// UpdatePermission - Update permission. Nama permission ada di token, jadi versi semua role
+// yang memiliki permission ini dinaikkan supaya token yang sudah terbit di-resolve ulang.
+func (r *RBACRepository) UpdatePermission(perm *model.Permission) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE permissions
+		SET name = $1, resource = $2, action = $3, description = $4
+		WHERE id = $5
+	`, perm.Name, perm.Resource, perm.Action, perm.Description, perm.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE roles
+		SET permission_version = permission_version + 1
+		WHERE id IN (SELECT role_id FROM role_permissions WHERE permission_id = $1)
+	`, perm.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeletePermission - Hapus permission (role_permissions ikut terhapus, trigger menaikkan versi role)
+func (r *RBACRepository) DeletePermission(permissionID uuid.UUID) error {
+	_, err := r.DB.Exec(`DELETE FROM permissions WHERE id = $1`, permissionID)
+	return err
+}
+
+// GetPermissionRoleIDs - ID semua role yang memiliki permission tertentu
+func (r *RBACRepository) GetPermissionRoleIDs(permissionID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.DB.Query(`SELECT role_id FROM role_permissions WHERE permission_id = $1`, permissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roleIDs []uuid.UUID
+	for rows.Next() {
+		var roleID uuid.UUID
+		if err := rows.Scan(&roleID); err != nil {
+			return nil, err
+		}
+		roleIDs = append(roleIDs, roleID)
+	}
+
+	return roleIDs, nil
+}
+
+// AttachPermission - Tambahkan permission ke role. Return false jika sudah ada.
+func (r *RBACRepository) AttachPermission(roleID, permissionID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		INSERT INTO role_permissions (role_id, permission_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, roleID, permissionID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// DetachPermission - Lepas permission dari role. Return false jika role tidak memiliki permission tersebut.
+func (r *RBACRepository) DetachPermission(roleID, permissionID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		DELETE FROM role_permissions
+		WHERE role_id = $1 AND permission_id = $2
+	`, roleID, permissionID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// GetAdminScope - Program studi yang dikelola admin ter-scope (kosong = admin global)
+func (r *RBACRepository) GetAdminScope(userID uuid.UUID) ([]string, error) {
+	rows, err := r.DB.Query(`
+		SELECT program_study
+		FROM admin_scopes
+		WHERE user_id = $1
+		ORDER BY program_study
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var programStudies []string
+	for rows.Next() {
+		var programStudy string
+		if err := rows.Scan(&programStudy); err != nil {
+			return nil, err
+		}
+		programStudies = append(programStudies, programStudy)
+	}
+
+	return programStudies, rows.Err()
+}
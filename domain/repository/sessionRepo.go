@@ -0,0 +1,163 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionRepository struct {
+	DB *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{DB: db}
+}
+
+// CreateSession - Simpan sesi baru saat login berhasil
+func (r *SessionRepository) CreateSession(session *model.UserSession) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $5, $6)
+	`
+
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+
+	_, err := r.DB.Exec(query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt,
+		session.ExpiresAt,
+	)
+
+	return err
+}
+
+// ExtendSession - Perpanjang sesi saat refresh token dirotasi.
+// Family refresh token lama yang belum punya baris sesi dibuatkan sesinya.
+// Return false jika sesi sudah dicabut.
+func (r *SessionRepository) ExtendSession(sessionID, userID uuid.UUID, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO user_sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, '', '', $3, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			last_seen_at = EXCLUDED.last_seen_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE user_sessions.user_id = EXCLUDED.user_id AND user_sessions.revoked_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, sessionID, userID, time.Now(), expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// TouchSession - Perbarui last_seen_at. Return false jika sesi tidak ada atau sudah dicabut.
+func (r *SessionRepository) TouchSession(sessionID, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE user_sessions
+		SET last_seen_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), sessionID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// GetActiveSessions - Ambil sesi user yang belum dicabut dan belum kadaluarsa
+func (r *SessionRepository) GetActiveSessions(userID uuid.UUID) ([]model.UserSession, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.DB.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.UserSession{}
+	for rows.Next() {
+		var session model.UserSession
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+			&session.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeSession - Cabut satu sesi beserta refresh token-nya.
+// Return false jika sesi bukan milik user atau sudah dicabut.
+func (r *SessionRepository) RevokeSession(sessionID, userID uuid.UUID) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	result, err := tx.Exec(`
+		UPDATE user_sessions
+		SET revoked_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`, now, sessionID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL
+	`, now, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
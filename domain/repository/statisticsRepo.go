@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -44,6 +45,12 @@ func (r *StatisticsRepository) GetAchievementTypeStats(req *service.StatisticsRe
 		argIndex++
 	}
 
+	if len(req.ProgramStudies) > 0 {
+		whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+		args = append(args, pq.Array(req.ProgramStudies))
+		argIndex++
+	}
+
 	if req.StartDate != nil {
 		whereClause += " AND ar.created_at >= $" + fmt.Sprintf("%d", argIndex)
 		args = append(args, *req.StartDate)
@@ -139,7 +146,7 @@ func (r *StatisticsRepository) GetAchievementTypeStats(req *service.StatisticsRe
 	// Build response
 	var stats []service.AchievementTypeStats
 	types := []string{"academic", "competition", "organization", "publication", "certification", "other"}
-	
+
 	for _, t := range types {
 		count := typeMap[t]
 		stats = append(stats, service.AchievementTypeStats{
@@ -171,6 +178,12 @@ func (r *StatisticsRepository) GetAchievementPeriodStats(req *service.Statistics
 		argIndex++
 	}
 
+	if len(req.ProgramStudies) > 0 {
+		whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+		args = append(args, pq.Array(req.ProgramStudies))
+		argIndex++
+	}
+
 	if req.StartDate != nil {
 		whereClause += " AND ar.created_at >= $" + fmt.Sprintf("%d", argIndex)
 		args = append(args, *req.StartDate)
@@ -228,6 +241,12 @@ func (r *StatisticsRepository) GetTopStudentStats(req *service.StatisticsRequest
 		argIndex++
 	}
 
+	if len(req.ProgramStudies) > 0 {
+		whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+		args = append(args, pq.Array(req.ProgramStudies))
+		argIndex++
+	}
+
 	if req.StartDate != nil {
 		whereClause += " AND ar.created_at >= $" + fmt.Sprintf("%d", argIndex)
 		args = append(args, *req.StartDate)
@@ -321,8 +340,8 @@ func (r *StatisticsRepository) GetCompetitionLevelStats(ctx context.Context, req
 		mongoFilter["createdAt"] = dateFilter
 	}
 
-	// If filtering by user or advisor, get student IDs first
-	if req.UserID != nil || req.AdvisorID != nil {
+	// If filtering by user, advisor, or admin scope, get student IDs first
+	if req.UserID != nil || req.AdvisorID != nil || len(req.ProgramStudies) > 0 {
 		studentIDs, err := r.getFilteredStudentIDs(req)
 		if err != nil {
 			return nil, err
@@ -367,7 +386,7 @@ func (r *StatisticsRepository) GetCompetitionLevelStats(ctx context.Context, req
 	// Build response
 	var stats []service.CompetitionLevelStats
 	levels := []string{"international", "national", "regional", "local", "unknown"}
-	
+
 	for _, level := range levels {
 		count := levelMap[level]
 		if count > 0 {
@@ -401,6 +420,12 @@ func (r *StatisticsRepository) GetStatisticsSummary(req *service.StatisticsReque
 		argIndex++
 	}
 
+	if len(req.ProgramStudies) > 0 {
+		whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+		args = append(args, pq.Array(req.ProgramStudies))
+		argIndex++
+	}
+
 	if req.StartDate != nil {
 		whereClause += " AND ar.created_at >= $" + fmt.Sprintf("%d", argIndex)
 		args = append(args, *req.StartDate)
@@ -460,6 +485,12 @@ func (r *StatisticsRepository) GetAchievementTrends(req *service.StatisticsReque
 		argIndex++
 	}
 
+	if len(req.ProgramStudies) > 0 {
+		whereClause += " AND LOWER(s.program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+		args = append(args, pq.Array(req.ProgramStudies))
+		argIndex++
+	}
+
 	query := `
 		SELECT 
 			TO_CHAR(ar.created_at, 'YYYY-MM') as month,
@@ -513,6 +544,12 @@ func (r *StatisticsRepository) getFilteredStudentIDs(req *service.StatisticsRequ
 		argIndex++
 	}
 
+	if len(req.ProgramStudies) > 0 {
+		whereClause += " AND LOWER(program_study) = ANY($" + fmt.Sprintf("%d", argIndex) + ")"
+		args = append(args, pq.Array(req.ProgramStudies))
+		argIndex++
+	}
+
 	query := `SELECT id FROM students ` + whereClause
 
 	rows, err := r.PostgresDB.Query(query, args...)
@@ -549,7 +586,7 @@ func (r *StatisticsRepository) getStudentPointsFromMongo(studentID string) (floa
 
 	pipeline := []bson.M{
 		{"$match": bson.M{
-			"studentId":  studentUUID,
+			"studentId": studentUUID,
 			"isDeleted": false,
 		}},
 		{"$group": bson.M{
@@ -631,4 +668,4 @@ func (r *StatisticsRepository) GetLecturerByUserID(userID uuid.UUID) (*model.Lec
 	}
 
 	return &lecturer, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenRepository struct {
+	DB *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+// CreateRefreshToken - Simpan refresh token baru (hanya hash-nya)
+func (r *TokenRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	token.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// GetRefreshTokenByHash - Ambil refresh token berdasarkan hash
+func (r *TokenRepository) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, rotated_at, replaced_by, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	var token model.RefreshToken
+	err := r.DB.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RotatedAt,
+		&token.ReplacedBy,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkRefreshTokenRotated - Tandai token sudah dirotasi.
+// Return false jika token sudah dirotasi/dicabut lebih dulu (mis. request refresh paralel atau token dicuri).
+func (r *TokenRepository) MarkRefreshTokenRotated(id uuid.UUID, replacedBy uuid.UUID) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET rotated_at = $1, replaced_by = $2
+		WHERE id = $3 AND rotated_at IS NULL AND revoked_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, time.Now(), replacedBy, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RevokeRefreshTokenFamily - Cabut semua refresh token dalam satu family beserta sesinya
+func (r *TokenRepository) RevokeRefreshTokenFamily(familyID uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL
+	`, now, familyID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE user_sessions
+		SET revoked_at = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`, now, familyID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeToken - Masukkan jti access token ke revocation list
+func (r *TokenRepository) RevokeToken(token *model.RevokedToken) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	token.RevokedAt = time.Now()
+
+	_, err := r.DB.Exec(query,
+		token.JTI,
+		token.UserID,
+		token.ExpiresAt,
+		token.RevokedAt,
+	)
+
+	return err
+}
+
+// IsTokenRevoked - Cek apakah access token sudah dicabut, baik per jti
+// maupun karena semua token user yang terbit sebelum waktu tertentu dicabut.
+// iat JWT berpresisi detik, jadi cutoff dibulatkan ke detik agar token yang langsung
+// diterbitkan ulang (mis. setelah ganti password) tidak ikut tertolak. Token lama dari
+// detik yang sama tetap tertolak lewat sesinya yang sudah dicabut.
+func (r *TokenRepository) IsTokenRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND date_trunc('second', revoked_before) > $3)
+	`
+
+	var revoked bool
+	err := r.DB.QueryRow(query, jti, userID, issuedAt).Scan(&revoked)
+	return revoked, err
+}
+
+// RevokeAllUserTokens - Cabut semua access token yang sudah terbit, semua refresh token, semua sesi, dan semua personal access token user
+func (r *TokenRepository) RevokeAllUserTokens(userID uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	_, err = tx.Exec(`
+		INSERT INTO user_token_revocations (user_id, revoked_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before
+	`, userID, now)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE user_sessions
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE personal_access_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteExpiredTokens - Hapus entry revocation list, token reset password, token ganti email, challenge MFA, state login OIDC, sesi, personal access token, dan refresh token yang sudah kadaluarsa.
+// Cutoff per user juga dihapus setelah lebih tua dari TTL access token karena tidak ada lagi token yang terpengaruh.
+func (r *TokenRepository) DeleteExpiredTokens(accessTokenTTL time.Duration) error {
+	now := time.Now()
+
+	if _, err := r.DB.Exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM user_token_revocations WHERE revoked_before < $1`, now.Add(-accessTokenTTL)); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM password_reset_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM email_change_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM mfa_challenges WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM oidc_auth_requests WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM user_sessions WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM personal_access_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	_, err := r.DB.Exec(`DELETE FROM refresh_tokens WHERE expires_at < $1`, now)
+	return err
+}
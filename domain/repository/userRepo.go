@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
@@ -20,8 +21,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 // CreateUser - Create new user
 func (r *UserRepository) CreateUser(user *model.Users) error {
 	query := `
-		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	now := time.Now()
@@ -36,6 +37,7 @@ func (r *UserRepository) CreateUser(user *model.Users) error {
 		user.FullName,
 		user.RoleID,
 		user.IsActive,
+		user.MustChangePassword,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -48,8 +50,8 @@ func (r *UserRepository) UpdateUser(user *model.Users) error {
 	query := `
 		UPDATE users
 		SET username = $1, email = $2, password_hash = $3, full_name = $4, 
-		    role_id = $5, is_active = $6, updated_at = $7
-		WHERE id = $8
+		    role_id = $5, is_active = $6, must_change_password = $7, updated_at = $8
+		WHERE id = $9
 	`
 
 	user.UpdatedAt = time.Now()
@@ -61,6 +63,7 @@ func (r *UserRepository) UpdateUser(user *model.Users) error {
 		user.FullName,
 		user.RoleID,
 		user.IsActive,
+		user.MustChangePassword,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -75,10 +78,108 @@ func (r *UserRepository) DeleteUser(userID uuid.UUID) error {
 	return err
 }
 
+// SetAuthSource - Set backend autentikasi user, string kosong = ikut role
+func (r *UserRepository) SetAuthSource(userID uuid.UUID, source string) error {
+	query := `UPDATE users SET auth_source = NULLIF($1, ''), updated_at = $2 WHERE id = $3`
+	_, err := r.DB.Exec(query, source, time.Now(), userID)
+	return err
+}
+
+// SetRoleAuthSource - Set backend autentikasi default untuk semua user di role
+func (r *UserRepository) SetRoleAuthSource(roleID uuid.UUID, source string) error {
+	query := `UPDATE roles SET auth_source = $1 WHERE id = $2`
+	_, err := r.DB.Exec(query, source, roleID)
+	return err
+}
+
+// GetUserRoles - Semua role user (role utama dan role tambahan)
+func (r *UserRepository) GetUserRoles(userID uuid.UUID) ([]model.Roles, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.mfa_required, r.created_at
+		FROM roles r
+		INNER JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name
+	`
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []model.Roles
+	for rows.Next() {
+		var role model.Roles
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.CreatedAt); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// AddUserRole - Tambah role ke user. Return false jika user sudah memiliki role tersebut.
+func (r *UserRepository) AddUserRole(userID, roleID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		INSERT INTO user_roles (user_id, role_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, userID, roleID, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RemoveUserRole - Lepas role tambahan dari user. Role utama (users.role_id) tidak ikut terhapus;
+// return false jika user tidak memiliki role tersebut sebagai role tambahan.
+func (r *UserRepository) RemoveUserRole(userID, roleID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		DELETE FROM user_roles ur
+		USING users u
+		WHERE ur.user_id = $1 AND ur.role_id = $2 AND u.id = ur.user_id AND u.role_id <> $2
+	`, userID, roleID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// CountOtherActiveAdmins - Jumlah admin global aktif selain user tertentu (role admin utama maupun tambahan).
+// Admin ter-scope program studi tidak dihitung karena tidak bisa mengelola role dan scope admin lain.
+func (r *UserRepository) CountOtherActiveAdmins(userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT u.id)
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = 'admin' AND u.is_active = true AND u.id <> $1
+		  AND NOT EXISTS (SELECT 1 FROM admin_scopes s WHERE s.user_id = u.id)
+	`
+
+	var count int
+	err := r.DB.QueryRow(query, userID).Scan(&count)
+	return count, err
+}
+
 // GetUserByID - Get user by ID
 func (r *UserRepository) GetUserByID(userID uuid.UUID) (*model.Users, error) {
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -92,6 +193,7 @@ func (r *UserRepository) GetUserByID(userID uuid.UUID) (*model.Users, error) {
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -109,7 +211,7 @@ func (r *UserRepository) GetUserByID(userID uuid.UUID) (*model.Users, error) {
 // GetUserByUsername - Get user by username
 func (r *UserRepository) GetUserByUsername(username string) (*model.Users, error) {
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -123,6 +225,7 @@ func (r *UserRepository) GetUserByUsername(username string) (*model.Users, error
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -140,7 +243,7 @@ func (r *UserRepository) GetUserByUsername(username string) (*model.Users, error
 // GetUserByEmail - Get user by email
 func (r *UserRepository) GetUserByEmail(email string) (*model.Users, error) {
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -154,6 +257,7 @@ func (r *UserRepository) GetUserByEmail(email string) (*model.Users, error) {
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -180,7 +284,7 @@ func (r *UserRepository) GetAllUsers(limit, offset int) ([]model.Users, int, err
 
 	// Get users with pagination
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, must_change_password, created_at, updated_at
 		FROM users
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -203,6 +307,62 @@ func (r *UserRepository) GetAllUsers(limit, offset int) ([]model.Users, int, err
 			&user.FullName,
 			&user.RoleID,
 			&user.IsActive,
+			&user.MustChangePassword,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, nil
+}
+
+// GetUsersByProgramStudies - Users dengan profil mahasiswa (program_study) atau dosen (department) di salah satu
+// program studi, dengan pagination. programStudies harus sudah huruf kecil.
+func (r *UserRepository) GetUsersByProgramStudies(programStudies []string, limit, offset int) ([]model.Users, int, error) {
+	scopeClause := `
+		WHERE EXISTS (SELECT 1 FROM students s WHERE s.user_id = u.id AND LOWER(s.program_study) = ANY($1))
+		   OR EXISTS (SELECT 1 FROM lecturers l WHERE l.user_id = u.id AND LOWER(l.department) = ANY($1))
+	`
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM users u ` + scopeClause
+	err := r.DB.QueryRow(countQuery, pq.Array(programStudies)).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get users with pagination
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, u.is_active, u.must_change_password, u.created_at, u.updated_at
+		FROM users u
+		` + scopeClause + `
+		ORDER BY u.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.DB.Query(query, pq.Array(programStudies), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []model.Users
+	for rows.Next() {
+		var user model.Users
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.PasswordHash,
+			&user.FullName,
+			&user.RoleID,
+			&user.IsActive,
+			&user.MustChangePassword,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -218,7 +378,7 @@ func (r *UserRepository) GetAllUsers(limit, offset int) ([]model.Users, int, err
 // GetRoleByID - Get role by ID
 func (r *UserRepository) GetRoleByID(roleID uuid.UUID) (*model.Roles, error) {
 	query := `
-		SELECT id, name, description, created_at
+		SELECT id, name, description, mfa_required, created_at
 		FROM roles
 		WHERE id = $1
 	`
@@ -228,6 +388,7 @@ func (r *UserRepository) GetRoleByID(roleID uuid.UUID) (*model.Roles, error) {
 		&role.ID,
 		&role.Name,
 		&role.Description,
+		&role.MFARequired,
 		&role.CreatedAt,
 	)
 
@@ -407,7 +568,7 @@ func (r *UserRepository) GetLecturerByID(lecturerID uuid.UUID) (*model.Lecturer,
 // GetAllRoles - Get all roles
 func (r *UserRepository) GetAllRoles() ([]model.Roles, error) {
 	query := `
-		SELECT id, name, description, created_at
+		SELECT id, name, description, mfa_required, created_at
 		FROM roles
 		ORDER BY name
 	`
@@ -425,6 +586,7 @@ func (r *UserRepository) GetAllRoles() ([]model.Roles, error) {
 			&role.ID,
 			&role.Name,
 			&role.Description,
+			&role.MFARequired,
 			&role.CreatedAt,
 		)
 		if err != nil {
@@ -435,3 +597,54 @@ func (r *UserRepository) GetAllRoles() ([]model.Roles, error) {
 
 	return roles, nil
 }
+
+// GetAllAdminScopes - Semua baris admin_scopes, dikelompokkan per admin
+func (r *UserRepository) GetAllAdminScopes() ([]model.AdminScope, error) {
+	rows, err := r.DB.Query(`
+		SELECT user_id, program_study, created_by, created_at
+		FROM admin_scopes
+		ORDER BY user_id, program_study
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scopes := []model.AdminScope{}
+	for rows.Next() {
+		var scope model.AdminScope
+		if err := rows.Scan(&scope.UserID, &scope.ProgramStudy, &scope.CreatedBy, &scope.CreatedAt); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, rows.Err()
+}
+
+// SetAdminScope - Ganti seluruh program studi admin dalam satu transaksi (kosong = admin global)
+func (r *UserRepository) SetAdminScope(userID uuid.UUID, programStudies []string, createdBy uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM admin_scopes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, programStudy := range programStudies {
+		_, err := tx.Exec(`
+			INSERT INTO admin_scopes (user_id, program_study, created_by, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, userID, programStudy, createdBy, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
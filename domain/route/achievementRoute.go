@@ -156,6 +156,47 @@ func (h *AchievementHandler) SubmitForVerification(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateAchievement - Handler untuk ubah prestasi draft atau rejected sebelum diajukan (ulang)
+func (h *AchievementHandler) UpdateAchievement(c *fiber.Ctx) error {
+	// Get user ID dari context
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	referenceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid reference ID",
+		})
+	}
+
+	var req service.SubmitAchievementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	response, err := h.AchievementService.UpdateAchievement(ctx, userID, referenceID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Achievement updated successfully",
+		"data":    response,
+	})
+}
+
 // DeleteAchievement - Handler untuk hapus prestasi draft (FR-005)
 func (h *AchievementHandler) DeleteAchievement(c *fiber.Ctx) error {
 	// Get user ID dari context
@@ -225,6 +266,12 @@ func SetupAchievementRoutes(app *fiber.App, handler *AchievementHandler, rbac *m
 			handler.SubmitForVerification,
 		)
 
+		// Update achievement - mahasiswa ubah prestasi draft/rejected
+		achievements.Put("/:id",
+			rbac.RequirePermission("achievement.write"),
+			handler.UpdateAchievement,
+		)
+
 		// Delete achievement - mahasiswa hapus prestasi draft
 		achievements.Delete("/:id",
 			rbac.RequirePermission("achievement.write"),
@@ -0,0 +1,57 @@
+package route
+
+import (
+	"UAS_BACKEND/domain/middleware"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetRouteRequirements - Handler untuk daftar semua route beserta permission/role/policy yang dibutuhkan
+func (h *AdminHandler) GetRouteRequirements(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Route requirements retrieved successfully",
+		"data":    h.RBACMiddleware.Routes.Routes(),
+	})
+}
+
+// ExplainAccess - Handler untuk menjelaskan akses user ke sebuah route (?user_id=&method=&path=)
+func (h *AdminHandler) ExplainAccess(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	path := c.Query("path")
+	if path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Path is required",
+		})
+	}
+
+	if _, err := h.UserService.GetUserByID(userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	explanation, err := h.RBACMiddleware.Explain(userID, c.Query("method", fiber.MethodGet), path)
+	if err != nil {
+		if errors.Is(err, middleware.ErrRouteNotMatched) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Access explained successfully",
+		"data":    explanation,
+	})
+}
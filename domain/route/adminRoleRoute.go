@@ -0,0 +1,265 @@
+package route
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// roleErrorStatus - Status HTTP untuk error RoleService
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound),
+		errors.Is(err, service.ErrPermissionNotFound),
+		errors.Is(err, service.ErrPermissionNotHeld):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrRoleExists),
+		errors.Is(err, service.ErrPermissionExists),
+		errors.Is(err, service.ErrRoleInUse):
+		return fiber.StatusConflict
+	case errors.Is(err, service.ErrProtectedRole):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// GetRole - Handler untuk detail role beserta permissions
+func (h *AdminHandler) GetRole(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	role, err := h.RoleService.GetRole(roleID)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role retrieved successfully",
+		"data":    role,
+	})
+}
+
+// CreateRole - Handler untuk buat role baru
+func (h *AdminHandler) CreateRole(c *fiber.Ctx) error {
+	var req model.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := h.RoleService.CreateRole(&req)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// UpdateRole - Handler untuk update role
+func (h *AdminHandler) UpdateRole(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	var req model.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := h.RoleService.UpdateRole(roleID, &req)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole - Handler untuk hapus role yang tidak dipakai user
+func (h *AdminHandler) DeleteRole(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	if err := h.RoleService.DeleteRole(roleID); err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role deleted successfully",
+	})
+}
+
+// AttachRolePermission - Handler untuk tambah permission ke role
+func (h *AdminHandler) AttachRolePermission(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	var req model.RolePermissionRequest
+	if err := c.BodyParser(&req); err != nil || req.PermissionID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "permission_id is required",
+		})
+	}
+
+	role, err := h.RoleService.AttachPermission(roleID, req.PermissionID)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Permission attached successfully",
+		"data":    role,
+	})
+}
+
+// DetachRolePermission - Handler untuk lepas permission dari role
+func (h *AdminHandler) DetachRolePermission(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	permissionID, err := uuid.Parse(c.Params("permissionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid permission ID",
+		})
+	}
+
+	role, err := h.RoleService.DetachPermission(roleID, permissionID)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Permission detached successfully",
+		"data":    role,
+	})
+}
+
+// GetPermissions - Handler untuk get all permissions
+func (h *AdminHandler) GetPermissions(c *fiber.Ctx) error {
+	permissions, err := h.RoleService.GetAllPermissions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Permissions retrieved successfully",
+		"data":    permissions,
+	})
+}
+
+// CreatePermission - Handler untuk buat permission baru
+func (h *AdminHandler) CreatePermission(c *fiber.Ctx) error {
+	var req model.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	permission, err := h.RoleService.CreatePermission(&req)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Permission created successfully",
+		"data":    permission,
+	})
+}
+
+// UpdatePermission - Handler untuk update permission
+func (h *AdminHandler) UpdatePermission(c *fiber.Ctx) error {
+	permissionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid permission ID",
+		})
+	}
+
+	var req model.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	permission, err := h.RoleService.UpdatePermission(permissionID, &req)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Permission updated successfully",
+		"data":    permission,
+	})
+}
+
+// DeletePermission - Handler untuk hapus permission (ikut dilepas dari semua role)
+func (h *AdminHandler) DeletePermission(c *fiber.Ctx) error {
+	permissionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid permission ID",
+		})
+	}
+
+	if err := h.RoleService.DeletePermission(permissionID); err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Permission deleted successfully",
+	})
+}
@@ -1,10 +1,12 @@
 package route
 
 import (
-	"UAS_BACKEND/domain/middleware"
 	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/service"
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
@@ -14,14 +16,26 @@ import (
 
 type AdminHandler struct {
 	UserService             *service.UserService
+	InvitationService       *service.InvitationService
+	RoleService             *service.RoleService
 	AdminAchievementService *service.AdminAchievementService
+	LockoutService          *service.LockoutService
+	MFAService              *service.MFAService
+	AccessTokenService      *service.AccessTokenService
+	ImpersonationService    *service.ImpersonationService
 	RBACMiddleware          *middleware.RBACMiddleware
 }
 
-func NewAdminHandler(userService *service.UserService, adminAchievementService *service.AdminAchievementService, rbacMiddleware *middleware.RBACMiddleware) *AdminHandler {
+func NewAdminHandler(userService *service.UserService, invitationService *service.InvitationService, roleService *service.RoleService, adminAchievementService *service.AdminAchievementService, lockoutService *service.LockoutService, mfaService *service.MFAService, accessTokenService *service.AccessTokenService, impersonationService *service.ImpersonationService, rbacMiddleware *middleware.RBACMiddleware) *AdminHandler {
 	return &AdminHandler{
 		UserService:             userService,
+		InvitationService:       invitationService,
+		RoleService:             roleService,
 		AdminAchievementService: adminAchievementService,
+		LockoutService:          lockoutService,
+		MFAService:              mfaService,
+		AccessTokenService:      accessTokenService,
+		ImpersonationService:    impersonationService,
 		RBACMiddleware:          rbacMiddleware,
 	}
 }
@@ -35,6 +49,19 @@ func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// Admin ter-scope hanya membuat mahasiswa/dosen di program studinya
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeNewUser(scope, req.RoleID, req.StudentData, req.LecturerData); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	response, err := h.UserService.CreateUser(&req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -48,6 +75,77 @@ func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
 	})
 }
 
+// InviteUser - Handler untuk undang user baru, user membuat password sendiri lewat link aktivasi
+func (h *AdminHandler) InviteUser(c *fiber.Ctx) error {
+	var req service.InviteUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeNewUser(scope, req.RoleID, req.StudentData, req.LecturerData); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	response, err := h.InvitationService.InviteUser(&req, adminID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "User invited successfully, activation link has been sent",
+		"data":    response,
+	})
+}
+
+// ResendInvitation - Handler untuk kirim ulang link aktivasi ke user yang belum aktivasi
+func (h *AdminHandler) ResendInvitation(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	response, err := h.InvitationService.ResendInvitation(userID, adminID)
+	if err != nil {
+		if errors.Is(err, service.ErrNoPendingInvitation) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation resent successfully",
+		"data":    response,
+	})
+}
+
 // GetAllUsers - Handler untuk get all users
 func (h *AdminHandler) GetAllUsers(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -65,7 +163,15 @@ func (h *AdminHandler) GetAllUsers(c *fiber.Ctx) error {
 
 	offset := (page - 1) * pageSize
 
-	users, total, err := h.UserService.GetAllUsers(pageSize, offset)
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+
+	// Admin ter-scope hanya melihat mahasiswa/dosen di program studinya
+	users, total, err := h.UserService.GetUsersInScope(scope, pageSize, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -127,6 +233,20 @@ func (h *AdminHandler) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.RoleID != uuid.Nil {
+		scope, err := h.RBACMiddleware.AdminScope(c)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get admin scope",
+			})
+		}
+		if err := h.UserService.AuthorizeRole(scope, req.RoleID); err != nil {
+			return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	response, err := h.UserService.UpdateUser(userID, &req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -186,6 +306,18 @@ func (h *AdminHandler) AssignRole(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeRole(scope, roleID); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	response, err := h.UserService.AssignRole(userID, roleID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -199,6 +331,354 @@ func (h *AdminHandler) AssignRole(c *fiber.Ctx) error {
 	})
 }
 
+// AddUserRole - Handler untuk tambah role tambahan ke user
+func (h *AdminHandler) AddUserRole(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req model.UserRoleRequest
+	if err := c.BodyParser(&req); err != nil || req.RoleID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "role_id is required",
+		})
+	}
+
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeRole(scope, req.RoleID); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	response, err := h.UserService.AddRole(userID, req.RoleID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role added successfully",
+		"data":    response,
+	})
+}
+
+// RemoveUserRole - Handler untuk lepas role tambahan dari user
+func (h *AdminHandler) RemoveUserRole(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	roleID, err := uuid.Parse(c.Params("roleId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	response, err := h.UserService.RemoveRole(userID, roleID)
+	if err != nil {
+		if errors.Is(err, service.ErrRoleNotAssigned) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role removed successfully",
+		"data":    response,
+	})
+}
+
+// SetUserAuthSource - Handler untuk pilih backend autentikasi user (local/ldap, kosong = ikut role)
+func (h *AdminHandler) SetUserAuthSource(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req struct {
+		AuthSource string `json:"auth_source"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.UserService.SetAuthSource(userID, req.AuthSource)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Auth source updated successfully",
+	})
+}
+
+// SetRoleAuthSource - Handler untuk pilih backend autentikasi default role
+func (h *AdminHandler) SetRoleAuthSource(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	var req struct {
+		AuthSource string `json:"auth_source"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.UserService.SetRoleAuthSource(roleID, req.AuthSource)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role auth source updated successfully",
+	})
+}
+
+// RevokeUserTokens - Handler untuk cabut semua token user (akun dibobol/dinonaktifkan)
+func (h *AdminHandler) RevokeUserTokens(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	err = h.UserService.RevokeUserTokens(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "All tokens revoked successfully",
+	})
+}
+
+// UnlockUser - Handler untuk buka lockout login user
+func (h *AdminHandler) UnlockUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	err = h.LockoutService.UnlockUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User unlocked successfully",
+	})
+}
+
+// ResetUserMFA - Handler untuk hapus MFA user (HP hilang dan recovery code habis)
+func (h *AdminHandler) ResetUserMFA(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	err = h.MFAService.Reset(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User MFA reset successfully",
+	})
+}
+
+// GetUserAccessTokens - Handler untuk melihat personal access token milik user
+func (h *AdminHandler) GetUserAccessTokens(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	tokens, err := h.AccessTokenService.ListTokens(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Access tokens retrieved successfully",
+		"data":    tokens,
+	})
+}
+
+// RevokeAccessToken - Handler untuk mencabut personal access token milik user mana pun
+func (h *AdminHandler) RevokeAccessToken(c *fiber.Ctx) error {
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid token ID",
+		})
+	}
+
+	err = h.AccessTokenService.AdminRevokeToken(tokenID)
+	if err != nil {
+		if errors.Is(err, service.ErrAccessTokenNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Access token revoked successfully",
+	})
+}
+
+// ImpersonateUser - Handler untuk "view as user": token pendek atas nama user, hanya untuk request baca
+func (h *AdminHandler) ImpersonateUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req model.StartImpersonationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	response, err := h.ImpersonationService.Start(adminID, userID, req.Reason, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImpersonateSelf),
+			errors.Is(err, service.ErrImpersonateAdmin),
+			errors.Is(err, service.ErrUserInactive):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Impersonation started, end it with POST /api/v1/auth/logout using this token",
+		"data":    response,
+	})
+}
+
+// GetImpersonationLogs - Handler untuk audit log impersonation (?admin_id=&user_id=&page=&page_size=)
+func (h *AdminHandler) GetImpersonationLogs(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	var adminID, userID uuid.UUID
+	if raw := c.Query("admin_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid admin ID",
+			})
+		}
+		adminID = id
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+		userID = id
+	}
+
+	logs, total, err := h.ImpersonationService.GetLogs(adminID, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	totalPages := total / pageSize
+	if total%pageSize > 0 {
+		totalPages++
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Impersonation logs retrieved successfully",
+		"data":    logs,
+		"pagination": fiber.Map{
+			"page":        page,
+			"page_size":   pageSize,
+			"total_items": total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
 // SetStudentProfile - Handler untuk set student profile (FR-009)
 func (h *AdminHandler) SetStudentProfile(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id"))
@@ -215,6 +695,18 @@ func (h *AdminHandler) SetStudentProfile(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeProfile(scope, req.ProgramStudy); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	student, err := h.UserService.SetStudentProfile(userID, &req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -244,6 +736,18 @@ func (h *AdminHandler) SetLecturerProfile(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeProfile(scope, req.Department); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	lecturer, err := h.UserService.SetLecturerProfile(userID, &req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -282,6 +786,18 @@ func (h *AdminHandler) SetAdvisor(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeAdvisor(scope, advisorID); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	student, err := h.UserService.SetAdvisor(studentUserID, advisorID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -328,8 +844,15 @@ func (h *AdminHandler) ViewAllAchievements(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Get achievements
-	response, err := h.AdminAchievementService.ViewAllAchievements(ctx, req)
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+
+	// Get achievements (admin ter-scope hanya program studinya)
+	response, err := h.AdminAchievementService.ViewAllAchievements(ctx, scope, req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
@@ -337,10 +860,10 @@ func (h *AdminHandler) ViewAllAchievements(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Achievements retrieved successfully",
-		"data":    response.Achievements,
+		"message":    "Achievements retrieved successfully",
+		"data":       response.Achievements,
 		"pagination": response.Pagination,
-		"summary": response.Summary,
+		"summary":    response.Summary,
 	})
 }
 
@@ -357,9 +880,21 @@ func (h *AdminHandler) GetAchievementDetail(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+
 	// Get achievement detail
-	achievement, err := h.AdminAchievementService.GetAchievementByReferenceID(ctx, referenceID)
+	achievement, err := h.AdminAchievementService.GetAchievementByReferenceID(ctx, scope, referenceID)
 	if err != nil {
+		if errors.Is(err, service.ErrOutOfScope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -393,22 +928,61 @@ func SetupAdminRoutes(app *fiber.App, handler *AdminHandler, rbac *middleware.RB
 	// Admin routes - require authentication and admin role
 	admin := api.Group("/admin", rbac.Authenticate(), rbac.RequireRole("admin"))
 	{
+		// inScope - Admin ter-scope hanya mengelola mahasiswa/dosen di program studinya.
+		// Dibuat per route supaya tercatat di registry requirement.
+		inScope := func() fiber.Handler { return rbac.RequirePolicy(policy.AdminUser, rbac.UserParam("id")) }
+
 		// User management
-		admin.Post("/users", handler.CreateUser)                    // Create user
-		admin.Get("/users", handler.GetAllUsers)                    // Get all users
-		admin.Get("/users/:id", handler.GetUserByID)                // Get user by ID
-		admin.Put("/users/:id", handler.UpdateUser)                 // Update user
-		admin.Delete("/users/:id", handler.DeleteUser)              // Delete user
-		admin.Post("/users/:id/assign-role", handler.AssignRole)    // Assign role
-		admin.Post("/users/:id/student-profile", handler.SetStudentProfile)   // Set student profile
-		admin.Post("/users/:id/lecturer-profile", handler.SetLecturerProfile) // Set lecturer profile
-		admin.Post("/users/:id/set-advisor", handler.SetAdvisor)    // Set advisor
+		admin.Post("/users", handler.CreateUser)                                                  // Create user
+		admin.Post("/users/invite", handler.InviteUser)                                           // Invite user (activation link)
+		admin.Get("/users", handler.GetAllUsers)                                                  // Get all users
+		admin.Get("/users/:id", inScope(), handler.GetUserByID)                                   // Get user by ID
+		admin.Put("/users/:id", inScope(), handler.UpdateUser)                                    // Update user
+		admin.Delete("/users/:id", inScope(), handler.DeleteUser)                                 // Delete user
+		admin.Post("/users/:id/assign-role", inScope(), handler.AssignRole)                       // Assign role
+		admin.Post("/users/:id/roles", inScope(), handler.AddUserRole)                            // Add secondary role
+		admin.Delete("/users/:id/roles/:roleId", inScope(), handler.RemoveUserRole)               // Remove secondary role
+		admin.Post("/users/:id/student-profile", inScope(), handler.SetStudentProfile)            // Set student profile
+		admin.Post("/users/:id/lecturer-profile", inScope(), handler.SetLecturerProfile)          // Set lecturer profile
+		admin.Post("/users/:id/set-advisor", inScope(), handler.SetAdvisor)                       // Set advisor
+		admin.Post("/users/:id/resend-invite", inScope(), handler.ResendInvitation)               // Resend activation link
+		admin.Post("/users/:id/revoke-tokens", inScope(), handler.RevokeUserTokens)               // Revoke all tokens
+		admin.Post("/users/:id/unlock", inScope(), handler.UnlockUser)                            // Unlock login lockout
+		admin.Post("/users/:id/reset-mfa", inScope(), handler.ResetUserMFA)                       // Reset MFA
+		admin.Put("/users/:id/auth-source", inScope(), handler.SetUserAuthSource)                 // Set auth backend (local/ldap)
+		admin.Get("/users/:id/tokens", inScope(), handler.GetUserAccessTokens)                    // List personal access tokens
+		admin.Delete("/tokens/:id", rbac.RequireGlobalAdmin(), handler.RevokeAccessToken)         // Revoke personal access token
+		admin.Post("/users/:id/impersonate", inScope(), handler.ImpersonateUser)                  // View as user (read-only token)
+		admin.Get("/impersonation-logs", rbac.RequireGlobalAdmin(), handler.GetImpersonationLogs) // Impersonation audit log
+
+		// Program study scope (admin global saja)
+		admin.Get("/scopes", rbac.RequireGlobalAdmin(), handler.GetAdminScopes)         // List scoped admins
+		admin.Put("/users/:id/scope", rbac.RequireGlobalAdmin(), handler.SetAdminScope) // Set/clear admin scope
 
 		// Achievement management
 		admin.Get("/achievements", handler.ViewAllAchievements)      // View all achievements
 		admin.Get("/achievements/:id", handler.GetAchievementDetail) // Get achievement detail
 
 		// Utility endpoints
-		admin.Get("/roles", handler.GetRoles)                       // Get all roles
+		admin.Get("/roles", handler.GetRoles) // Get all roles
+
+		// Role management (admin global saja)
+		admin.Get("/roles/:id", rbac.RequireGlobalAdmin(), handler.GetRole)                                           // Get role with permissions
+		admin.Post("/roles", rbac.RequireGlobalAdmin(), handler.CreateRole)                                           // Create role
+		admin.Put("/roles/:id", rbac.RequireGlobalAdmin(), handler.UpdateRole)                                        // Update role
+		admin.Delete("/roles/:id", rbac.RequireGlobalAdmin(), handler.DeleteRole)                                     // Delete unused role
+		admin.Put("/roles/:id/auth-source", rbac.RequireGlobalAdmin(), handler.SetRoleAuthSource)                     // Set default auth backend for role
+		admin.Post("/roles/:id/permissions", rbac.RequireGlobalAdmin(), handler.AttachRolePermission)                 // Attach permission
+		admin.Delete("/roles/:id/permissions/:permissionId", rbac.RequireGlobalAdmin(), handler.DetachRolePermission) // Detach permission
+
+		// Permission management (admin global saja)
+		admin.Get("/permissions", rbac.RequireGlobalAdmin(), handler.GetPermissions)          // Get all permissions
+		admin.Post("/permissions", rbac.RequireGlobalAdmin(), handler.CreatePermission)       // Create permission
+		admin.Put("/permissions/:id", rbac.RequireGlobalAdmin(), handler.UpdatePermission)    // Update permission
+		admin.Delete("/permissions/:id", rbac.RequireGlobalAdmin(), handler.DeletePermission) // Delete permission
+
+		// Authorization debugging (admin global saja)
+		admin.Get("/authz/routes", rbac.RequireGlobalAdmin(), handler.GetRouteRequirements) // Route -> required permissions/roles/policies
+		admin.Get("/authz/explain", rbac.RequireGlobalAdmin(), handler.ExplainAccess)       // Why a user can/cannot access a route
 	}
 }
@@ -0,0 +1,73 @@
+package route
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/service"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// adminScopeErrorStatus - Status HTTP untuk error scope program studi admin
+func adminScopeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrOutOfScope),
+		errors.Is(err, service.ErrScopedAdminRole):
+		return fiber.StatusForbidden
+	case errors.Is(err, service.ErrLastAdmin):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// GetAdminScopes - Handler untuk daftar scope program studi semua admin ter-scope
+func (h *AdminHandler) GetAdminScopes(c *fiber.Ctx) error {
+	scopes, err := h.UserService.GetAdminScopes()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Admin scopes retrieved successfully",
+		"data":    scopes,
+	})
+}
+
+// SetAdminScope - Handler untuk batasi admin ke program studi tertentu (kosong = admin global)
+func (h *AdminHandler) SetAdminScope(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req model.AdminScopeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	actorID, err := middleware.GetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	scope, err := h.UserService.SetAdminScope(userID, req.ProgramStudies, actorID)
+	if err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Admin scope updated successfully",
+		"data":    scope,
+	})
+}
@@ -3,6 +3,8 @@ package route
 import (
 	model "UAS_BACKEND/domain/Model"
 	"UAS_BACKEND/domain/service"
+	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -34,13 +36,28 @@ func (h *AuthHandler) LoginHandler(c *fiber.Ctx) error {
 	}
 
 	// Execute login
-	resp, err := h.AuthService.Login(req.Identifier, req.Password)
+	resp, err := h.AuthService.Login(req.Identifier, req.Password, clientInfo(c))
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(throttled.RetryAfterSeconds()))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": throttled.Error(),
+			})
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	// Password benar tapi masih butuh kode MFA, lanjutkan di /api/v1/auth/mfa
+	if resp.MFA != nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "MFA verification required",
+			"data":    resp.MFA,
+		})
+	}
+
 	// Return success response
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Login successful",
@@ -48,8 +65,17 @@ func (h *AuthHandler) LoginHandler(c *fiber.Ctx) error {
 	})
 }
 
+// JWKSHandler handles GET /.well-known/jwks.json
+func (h *AuthHandler) JWKSHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.AuthService.JWKS())
+}
+
 // SetupAuthRoutes registers auth routes
 func SetupAuthRoutes(app *fiber.App, handler *AuthHandler) {
 	auth := app.Group("/api/auth")
 	auth.Post("/login", handler.LoginHandler)
+
+	// Public key untuk verifikasi token oleh service lain
+	app.Get("/.well-known/jwks.json", handler.JWKSHandler)
 }
@@ -2,6 +2,7 @@ package route
 
 import (
 	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/service"
 	"context"
 	"strconv"
@@ -35,13 +36,13 @@ func (h *StatisticsHandler) GetStudentStatistics(c *fiber.Ctx) error {
 
 	// Parse query parameters
 	req := &service.StatisticsRequest{}
-	
+
 	if startDate := c.Query("start_date"); startDate != "" {
 		if t, err := time.Parse("2006-01-02", startDate); err == nil {
 			req.StartDate = &t
 		}
 	}
-	
+
 	if endDate := c.Query("end_date"); endDate != "" {
 		if t, err := time.Parse("2006-01-02", endDate); err == nil {
 			req.EndDate = &t
@@ -78,13 +79,13 @@ func (h *StatisticsHandler) GetLecturerStatistics(c *fiber.Ctx) error {
 
 	// Parse query parameters
 	req := &service.StatisticsRequest{}
-	
+
 	if startDate := c.Query("start_date"); startDate != "" {
 		if t, err := time.Parse("2006-01-02", startDate); err == nil {
 			req.StartDate = &t
 		}
 	}
-	
+
 	if endDate := c.Query("end_date"); endDate != "" {
 		if t, err := time.Parse("2006-01-02", endDate); err == nil {
 			req.EndDate = &t
@@ -113,13 +114,13 @@ func (h *StatisticsHandler) GetLecturerStatistics(c *fiber.Ctx) error {
 func (h *StatisticsHandler) GetAdminStatistics(c *fiber.Ctx) error {
 	// Parse query parameters
 	req := &service.StatisticsRequest{}
-	
+
 	if startDate := c.Query("start_date"); startDate != "" {
 		if t, err := time.Parse("2006-01-02", startDate); err == nil {
 			req.StartDate = &t
 		}
 	}
-	
+
 	if endDate := c.Query("end_date"); endDate != "" {
 		if t, err := time.Parse("2006-01-02", endDate); err == nil {
 			req.EndDate = &t
@@ -130,8 +131,16 @@ func (h *StatisticsHandler) GetAdminStatistics(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
+	// Admin ter-scope hanya melihat statistik program studinya
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
+	}
+
 	// Get statistics
-	stats, err := h.StatisticsService.GetAdminStatistics(ctx, req)
+	stats, err := h.StatisticsService.GetAdminStatistics(ctx, scope, req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
@@ -165,13 +174,19 @@ func (h *StatisticsHandler) GetAchievementTrends(c *fiber.Ctx) error {
 	// Determine role
 	role := "student" // default
 	permissions := claims.Permissions
-	for _, perm := range permissions {
-		if perm == "admin" {
-			role = "admin"
-			break
-		} else if perm == "achievement.verify" {
-			role = "lecturer"
-		}
+	if policy.PermissionGranted(permissions, "admin.manage") {
+		role = "admin"
+	}
+	// Wildcard dan hierarki ikut dihitung (mis. "achievement.*")
+	if role == "student" && policy.PermissionGranted(permissions, "achievement.verify") {
+		role = "lecturer"
+	}
+
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get admin scope",
+		})
 	}
 
 	// Parse months parameter
@@ -182,7 +197,7 @@ func (h *StatisticsHandler) GetAchievementTrends(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Get trends
-	trends, err := h.StatisticsService.GetAchievementTrends(ctx, userID, role, months)
+	trends, err := h.StatisticsService.GetAchievementTrends(ctx, userID, role, months, scope)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
@@ -209,11 +224,11 @@ func SetupStatisticsRoutes(app *fiber.App, handler *StatisticsHandler, rbac *mid
 	// Lecturer statistics - require authentication and lecturer permissions
 	lecturer := api.Group("/lecturer", rbac.Authenticate())
 	{
-		lecturer.Get("/statistics", 
+		lecturer.Get("/statistics",
 			rbac.RequirePermission("achievement.verify"),
 			handler.GetLecturerStatistics,
 		)
-		lecturer.Get("/trends", 
+		lecturer.Get("/trends",
 			rbac.RequirePermission("achievement.verify"),
 			handler.GetAchievementTrends,
 		)
@@ -225,4 +240,4 @@ func SetupStatisticsRoutes(app *fiber.App, handler *StatisticsHandler, rbac *mid
 		admin.Get("/statistics", handler.GetAdminStatistics)
 		admin.Get("/trends", handler.GetAchievementTrends)
 	}
-}
\ No newline at end of file
+}
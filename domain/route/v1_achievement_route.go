@@ -2,11 +2,13 @@ package route
 
 import (
 	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/model"
 	"UAS_BACKEND/domain/service"
+	"UAS_BACKEND/domain/workflow"
 	"context"
+	"errors"
 	"strconv"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -38,23 +40,32 @@ func SetupV1AchievementRoutes(app *fiber.App, handler *V1AchievementHandler) {
 	achievements := app.Group("/api/v1/achievements")
 	achievements.Use(handler.RBACMiddleware.RequireAuth())
 
+	// Policy per prestasi: baca oleh pemilik/dosen wali/dosen prodi/admin, ubah oleh pemilik, verifikasi oleh dosen wali.
+	// Dibuat baru di setiap route (bukan satu handler dipakai ulang) supaya syaratnya tercatat di route registry.
+	rbac := handler.RBACMiddleware
+	canRead := func() fiber.Handler { return rbac.RequirePolicy(policy.StudentRecord, rbac.AchievementParam("id")) }
+	isOwner := func() fiber.Handler { return rbac.RequirePolicy(policy.AchievementOwner, rbac.AchievementParam("id")) }
+	isVerifier := func() fiber.Handler {
+		return rbac.RequirePolicy(policy.AchievementVerifier, rbac.AchievementParam("id"))
+	}
+
 	// 5.4 Achievements endpoints
-	achievements.Get("/", handler.GetAchievements)                                                                    // List (filtered by role)
-	achievements.Get("/:id", handler.GetAchievementDetail)                                                           // Detail
-	achievements.Post("/", handler.RBACMiddleware.RequirePermission("achievement.write"), handler.CreateAchievement) // Create (Mahasiswa)
-	achievements.Put("/:id", handler.RBACMiddleware.RequirePermission("achievement.write"), handler.UpdateAchievement) // Update (Mahasiswa)
-	achievements.Delete("/:id", handler.RBACMiddleware.RequirePermission("achievement.write"), handler.DeleteAchievement) // Delete (Mahasiswa)
-	achievements.Post("/:id/submit", handler.RBACMiddleware.RequirePermission("achievement.write"), handler.SubmitForVerification) // Submit for verification
-	achievements.Post("/:id/verify", handler.RBACMiddleware.RequirePermission("achievement.verify"), handler.VerifyAchievement) // Verify (Dosen Wali)
-	achievements.Post("/:id/reject", handler.RBACMiddleware.RequirePermission("achievement.verify"), handler.RejectAchievement) // Reject (Dosen Wali)
-	achievements.Get("/:id/history", handler.GetAchievementHistory)                                                  // Status history
-	achievements.Post("/:id/attachments", handler.RBACMiddleware.RequirePermission("achievement.write"), handler.UploadAttachments) // Upload files
+	achievements.Get("/", handler.GetAchievements)                                                                           // List (filtered by role)
+	achievements.Get("/:id", canRead(), handler.GetAchievementDetail)                                                        // Detail
+	achievements.Post("/", rbac.RequirePermission("achievement.write"), handler.CreateAchievement)                           // Create (Mahasiswa)
+	achievements.Put("/:id", rbac.RequirePermission("achievement.write"), isOwner(), handler.UpdateAchievement)              // Update (Mahasiswa)
+	achievements.Delete("/:id", rbac.RequirePermission("achievement.write"), isOwner(), handler.DeleteAchievement)           // Delete (Mahasiswa)
+	achievements.Post("/:id/submit", rbac.RequirePermission("achievement.write"), isOwner(), handler.SubmitForVerification)  // Submit for verification
+	achievements.Post("/:id/verify", rbac.RequirePermission("achievement.verify"), isVerifier(), handler.VerifyAchievement)  // Verify (Dosen Wali)
+	achievements.Post("/:id/reject", rbac.RequirePermission("achievement.verify"), isVerifier(), handler.RejectAchievement)  // Reject (Dosen Wali)
+	achievements.Get("/:id/history", canRead(), handler.GetAchievementHistory)                                               // Status history
+	achievements.Post("/:id/attachments", rbac.RequirePermission("achievement.write"), isOwner(), handler.UploadAttachments) // Upload files
 }
 
 // GetAchievements - GET /api/v1/achievements (List filtered by role)
 func (h *V1AchievementHandler) GetAchievements(c *fiber.Ctx) error {
 	user := c.Locals("user").(*model.Claims)
-	
+
 	// Parse query parameters
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -134,8 +145,16 @@ func (h *V1AchievementHandler) CreateAchievement(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateAchievement - PUT /api/v1/achievements/:id (Mahasiswa)
+// UpdateAchievement - PUT /api/v1/achievements/:id (Mahasiswa, hanya prestasi draft atau rejected)
 func (h *V1AchievementHandler) UpdateAchievement(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unauthorized",
+		})
+	}
+
 	achievementID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -152,13 +171,18 @@ func (h *V1AchievementHandler) UpdateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update achievement logic would be implemented here
+	result, err := h.AchievementService.UpdateAchievement(context.Background(), userID, achievementID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
 	return c.Status(200).JSON(fiber.Map{
 		"success": true,
 		"message": "Achievement updated successfully",
-		"data": fiber.Map{
-			"id": achievementID,
-		},
+		"data":    result,
 	})
 }
 
@@ -209,14 +233,39 @@ func (h *V1AchievementHandler) SubmitForVerification(c *fiber.Ctx) error {
 
 	return c.Status(200).JSON(fiber.Map{
 		"success": true,
-		"message": "Achievement submitted for verification successfully",
+		"message": result.Message,
 		"data":    result,
 	})
 }
 
-// VerifyAchievement - POST /api/v1/achievements/:id/verify (Dosen Wali)
-func (h *V1AchievementHandler) VerifyAchievement(c *fiber.Ctx) error {
-	user := c.Locals("user").(*model.Claims)
+// verificationErrorStatus - HTTP status untuk error verify/reject: role atau policy ditolak 403,
+// status prestasi tidak sesuai (termasuk sudah dihapus) 409, prestasi tidak ada 404
+func verificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, workflow.ErrRoleNotAllowed),
+		errors.Is(err, service.ErrNotVerifier),
+		errors.Is(err, service.ErrNotLecturer):
+		return fiber.StatusForbidden
+	case errors.Is(err, workflow.ErrInvalidTransition),
+		errors.Is(err, workflow.NotDeleted.Err):
+		return fiber.StatusConflict
+	case errors.Is(err, service.ErrAchievementNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// decideAchievement - Jalankan verify (approved) atau reject lewat AchievementService
+func (h *V1AchievementHandler) decideAchievement(c *fiber.Ctx, approved bool, note string) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unauthorized",
+		})
+	}
+
 	achievementID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -225,42 +274,39 @@ func (h *V1AchievementHandler) VerifyAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	var req struct {
-		Notes string `json:"notes"`
-	}
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
+	result, err := h.AchievementService.VerifyAchievement(context.Background(), userID, achievementID, approved, note, h.NotificationService)
+	if err != nil {
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{
 			"success": false,
-			"error":   "Invalid JSON format",
+			"error":   err.Error(),
 		})
 	}
 
-	// Verify achievement logic would be implemented here
 	return c.Status(200).JSON(fiber.Map{
 		"success": true,
-		"message": "Achievement verified successfully",
-		"data": fiber.Map{
-			"id":          achievementID,
-			"status":      "verified",
-			"verified_by": user.UserID,
-			"verified_at": time.Now(),
-			"notes":       req.Notes,
-		},
+		"message": result.Message,
+		"data":    result,
 	})
 }
 
-// RejectAchievement - POST /api/v1/achievements/:id/reject (Dosen Wali)
-func (h *V1AchievementHandler) RejectAchievement(c *fiber.Ctx) error {
-	user := c.Locals("user").(*model.Claims)
-	achievementID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
+// VerifyAchievement - POST /api/v1/achievements/:id/verify (Dosen Wali atau dosen pengganti)
+func (h *V1AchievementHandler) VerifyAchievement(c *fiber.Ctx) error {
+	var req struct {
+		Notes string `json:"notes"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"success": false,
-			"error":   "Invalid achievement ID format",
+			"error":   "Invalid JSON format",
 		})
 	}
 
+	return h.decideAchievement(c, true, req.Notes)
+}
+
+// RejectAchievement - POST /api/v1/achievements/:id/reject (Dosen Wali atau dosen pengganti)
+func (h *V1AchievementHandler) RejectAchievement(c *fiber.Ctx) error {
 	var req struct {
 		RejectionNote string `json:"rejection_note"`
 	}
@@ -279,18 +325,7 @@ func (h *V1AchievementHandler) RejectAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	// Reject achievement logic would be implemented here
-	return c.Status(200).JSON(fiber.Map{
-		"success": true,
-		"message": "Achievement rejected successfully",
-		"data": fiber.Map{
-			"id":             achievementID,
-			"status":         "rejected",
-			"rejected_by":    user.UserID,
-			"rejected_at":    time.Now(),
-			"rejection_note": req.RejectionNote,
-		},
-	})
+	return h.decideAchievement(c, false, req.RejectionNote)
 }
 
 // GetAchievementHistory - GET /api/v1/achievements/:id/history
@@ -303,22 +338,16 @@ func (h *V1AchievementHandler) GetAchievementHistory(c *fiber.Ctx) error {
 		})
 	}
 
-	// Mock history data
-	history := []fiber.Map{
-		{
-			"status":     "draft",
-			"timestamp":  time.Now().Add(-72 * time.Hour),
-			"action":     "created",
-			"actor":      "student",
-			"notes":      "Achievement created",
-		},
-		{
-			"status":     "submitted",
-			"timestamp":  time.Now().Add(-24 * time.Hour),
-			"action":     "submitted",
-			"actor":      "student",
-			"notes":      "Submitted for verification",
-		},
+	history, err := h.AchievementService.GetAchievementHistory(achievementID)
+	if err != nil {
+		status := 500
+		if errors.Is(err, service.ErrAchievementNotFound) {
+			status = 404
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
 	}
 
 	return c.Status(200).JSON(fiber.Map{
@@ -385,4 +414,4 @@ func (h *V1AchievementHandler) UploadAttachments(c *fiber.Ctx) error {
 			"files":          uploadedFiles,
 		},
 	})
-}
\ No newline at end of file
+}
@@ -4,21 +4,26 @@ import (
 	"UAS_BACKEND/domain/middleware"
 	"UAS_BACKEND/domain/model"
 	"UAS_BACKEND/domain/service"
-	"time"
+	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type V1AuthHandler struct {
-	AuthService     *service.AuthService
-	RBACMiddleware  *middleware.RBACMiddleware
+	AuthService          *service.AuthService
+	PasswordResetService *service.PasswordResetService
+	InvitationService    *service.InvitationService
+	RBACMiddleware       *middleware.RBACMiddleware
 }
 
-func NewV1AuthHandler(authService *service.AuthService, rbacMiddleware *middleware.RBACMiddleware) *V1AuthHandler {
+func NewV1AuthHandler(authService *service.AuthService, passwordResetService *service.PasswordResetService, invitationService *service.InvitationService, rbacMiddleware *middleware.RBACMiddleware) *V1AuthHandler {
 	return &V1AuthHandler{
-		AuthService:    authService,
-		RBACMiddleware: rbacMiddleware,
+		AuthService:          authService,
+		PasswordResetService: passwordResetService,
+		InvitationService:    invitationService,
+		RBACMiddleware:       rbacMiddleware,
 	}
 }
 
@@ -30,6 +35,10 @@ func SetupV1AuthRoutes(app *fiber.App, handler *V1AuthHandler) {
 	auth.Post("/login", handler.Login)
 	auth.Post("/refresh", handler.RefreshToken)
 	auth.Post("/logout", handler.RBACMiddleware.RequireAuth(), handler.Logout)
+	auth.Post("/forgot-password", handler.ForgotPassword)
+	auth.Post("/reset-password", handler.ResetPassword)
+	auth.Post("/accept-invite", handler.AcceptInvitation)
+	auth.Post("/change-password", handler.RBACMiddleware.RequireAuth(), handler.ChangePassword)
 	auth.Get("/profile", handler.RBACMiddleware.RequireAuth(), handler.GetProfile)
 }
 
@@ -50,20 +59,53 @@ func (h *V1AuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Authenticate user
-	result, err := h.AuthService.Login(&req)
+	result, err := h.AuthService.Login(req.Identifier, req.Password, clientInfo(c))
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(throttled.RetryAfterSeconds()))
+			return c.Status(429).JSON(fiber.Map{
+				"error": throttled.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrAuthBackendUnavailable) {
+			return c.Status(503).JSON(fiber.Map{
+				"error": "Authentication service is temporarily unavailable",
+			})
+		}
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Invalid credentials",
 		})
 	}
 
+	return loginResult(c, result)
+}
+
+// loginResult - Response login (password atau SSO): token, atau challenge MFA jika masih butuh kode
+func loginResult(c *fiber.Ctx, result *model.LoginResponse) error {
+	// Kredensial benar tapi masih butuh kode MFA (atau enrollment MFA)
+	if result.MFA != nil {
+		return c.Status(200).JSON(fiber.Map{
+			"success": true,
+			"message": "MFA verification required",
+			"data": fiber.Map{
+				"mfa_required":        true,
+				"mfa_token":           result.MFA.MFAToken,
+				"enrollment_required": result.MFA.EnrollmentRequired,
+				"expires_at":          result.MFA.ExpiresAt,
+			},
+		})
+	}
+
 	return c.Status(200).JSON(fiber.Map{
 		"success": true,
 		"message": "Login successful",
 		"data": fiber.Map{
-			"token":      result.Token,
-			"user":       result.User,
-			"expires_at": result.ExpiresAt,
+			"token":                    result.Token,
+			"refresh_token":            result.RefreshToken,
+			"user":                     result.User,
+			"expires_at":               result.ExpiresAt,
+			"password_change_required": result.PasswordChangeRequired,
 		},
 	})
 }
@@ -86,47 +128,228 @@ func (h *V1AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate refresh token
-	claims, err := h.AuthService.ValidateToken(req.RefreshToken)
+	// Rotasi refresh token dan generate access token baru
+	result, err := h.AuthService.RefreshToken(req.RefreshToken)
 	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			return c.Status(401).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Invalid refresh token",
 		})
 	}
 
-	// Generate new access token
-	newToken, err := h.AuthService.GenerateToken(claims.UserID, claims.RoleID, claims.Permissions)
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Token refreshed successfully",
+		"data": fiber.Map{
+			"token":                    result.Token,
+			"refresh_token":            result.RefreshToken,
+			"expires_at":               result.ExpiresAt,
+			"password_change_required": result.PasswordChangeRequired,
+		},
+	})
+}
+
+// Logout - POST /api/v1/auth/logout
+func (h *V1AuthHandler) Logout(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
 	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	// Refresh token opsional, jika dikirim ikut dicabut
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.BodyParser(&req)
+
+	if err := h.AuthService.Logout(claims, req.RefreshToken); err != nil {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to generate new token",
+			"error": "Failed to logout",
 		})
 	}
 
 	return c.Status(200).JSON(fiber.Map{
 		"success": true,
-		"message": "Token refreshed successfully",
+		"message": "Logged out successfully",
 		"data": fiber.Map{
-			"token":      newToken,
-			"expires_at": time.Now().Add(24 * time.Hour),
+			"user_id": claims.UserID,
 		},
 	})
 }
 
-// Logout - POST /api/v1/auth/logout
-func (h *V1AuthHandler) Logout(c *fiber.Ctx) error {
-	// Get user from context
-	user := c.Locals("user").(*model.Claims)
-	
-	// In a real implementation, you would:
-	// 1. Add token to blacklist
-	// 2. Clear refresh token from database
-	// 3. Log the logout event
-	
+// ForgotPassword - POST /api/v1/auth/forgot-password
+func (h *V1AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req model.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	if err := h.PasswordResetService.ForgotPassword(req.Email); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to process password reset request",
+		})
+	}
+
+	// Response selalu sama, baik email terdaftar maupun tidak
 	return c.Status(200).JSON(fiber.Map{
 		"success": true,
-		"message": "Logged out successfully",
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword - POST /api/v1/auth/reset-password
+func (h *V1AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req model.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Token and new password are required",
+		})
+	}
+
+	if err := h.PasswordResetService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		var policyErr *service.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":      "Password does not meet the password policy",
+				"violations": policyErr.Violations,
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to reset password",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Password has been reset, please login again",
+	})
+}
+
+// AcceptInvitation - POST /api/v1/auth/accept-invite
+func (h *V1AuthHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req model.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Token and new password are required",
+		})
+	}
+
+	if err := h.InvitationService.AcceptInvitation(req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, service.ErrInvalidInvitation) {
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		var policyErr *service.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":      "Password does not meet the password policy",
+				"violations": policyErr.Violations,
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to activate account",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Account has been activated, please login",
+	})
+}
+
+// ChangePassword - POST /api/v1/auth/change-password
+func (h *V1AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	return changePassword(c, h.AuthService)
+}
+
+// changePassword - Handler ganti password sendiri, dipakai oleh /auth/change-password dan /me/password
+func changePassword(c *fiber.Ctx, authService *service.AuthService) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Current password and new password are required",
+		})
+	}
+
+	result, err := authService.ChangePassword(claims.UserID, req.CurrentPassword, req.NewPassword, clientInfo(c))
+	if err != nil {
+		var policyErr *service.PasswordPolicyError
+		switch {
+		case errors.As(err, &policyErr):
+			return c.Status(400).JSON(fiber.Map{
+				"error":      "Password does not meet the password policy",
+				"violations": policyErr.Violations,
+			})
+		case errors.Is(err, service.ErrInvalidPassword):
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrUserInactive),
+			errors.Is(err, service.ErrExternalPassword):
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to change password",
+			})
+		}
+	}
+
+	// Semua sesi lama dicabut, client harus memakai token baru ini
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Password changed successfully",
 		"data": fiber.Map{
-			"user_id": user.UserID,
+			"token":         result.Token,
+			"refresh_token": result.RefreshToken,
+			"user":          result.User,
+			"expires_at":    result.ExpiresAt,
 		},
 	})
 }
@@ -146,4 +369,4 @@ func (h *V1AuthHandler) GetProfile(c *fiber.Ctx) error {
 			"expires_at":  user.ExpiresAt,
 		},
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,150 @@
+package route
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/service"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type V1DelegationHandler struct {
+	DelegationService *service.DelegationService
+	RBACMiddleware    *middleware.RBACMiddleware
+}
+
+func NewV1DelegationHandler(delegationService *service.DelegationService, rbacMiddleware *middleware.RBACMiddleware) *V1DelegationHandler {
+	return &V1DelegationHandler{
+		DelegationService: delegationService,
+		RBACMiddleware:    rbacMiddleware,
+	}
+}
+
+// SetupV1DelegationRoutes - Setup verification delegation routes v1
+func SetupV1DelegationRoutes(app *fiber.App, handler *V1DelegationHandler) {
+	delegations := app.Group("/api/v1/delegations")
+	delegations.Use(handler.RBACMiddleware.RequireAuth())
+
+	// Delegasi verifikasi: dosen wali untuk mahasiswa bimbingannya sendiri, admin untuk dosen mana pun
+	delegations.Get("/", handler.RBACMiddleware.RequireAnyPermission("achievement.verify", "admin.manage"), handler.GetDelegations)
+	delegations.Post("/", handler.RBACMiddleware.RequireAnyPermission("achievement.verify", "admin.manage"), handler.CreateDelegation)
+	delegations.Delete("/:id", handler.RBACMiddleware.RequireAnyPermission("achievement.verify", "admin.manage"), handler.RevokeDelegation)
+}
+
+// delegationErrorStatus - Status HTTP untuk error DelegationService
+func delegationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrDelegationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrDelegationForbidden),
+		errors.Is(err, service.ErrDelegationActor),
+		errors.Is(err, service.ErrOutOfScope):
+		return fiber.StatusForbidden
+	case errors.Is(err, service.ErrDelegationRevoked):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// GetDelegations - GET /api/v1/delegations (?lecturer_id= untuk admin)
+func (h *V1DelegationHandler) GetDelegations(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unauthorized",
+		})
+	}
+
+	lecturerID := uuid.Nil
+	if value := c.Query("lecturer_id"); value != "" {
+		lecturerID, err = uuid.Parse(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid lecturer ID format",
+			})
+		}
+	}
+
+	delegations, err := h.DelegationService.GetDelegations(userID, lecturerID)
+	if err != nil {
+		return c.Status(delegationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Delegations retrieved successfully",
+		"data":    delegations,
+	})
+}
+
+// CreateDelegation - POST /api/v1/delegations
+func (h *V1DelegationHandler) CreateDelegation(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unauthorized",
+		})
+	}
+
+	var req model.CreateDelegationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid JSON format",
+		})
+	}
+
+	delegation, err := h.DelegationService.CreateDelegation(userID, &req)
+	if err != nil {
+		return c.Status(delegationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Delegation created successfully",
+		"data":    delegation,
+	})
+}
+
+// RevokeDelegation - DELETE /api/v1/delegations/:id
+func (h *V1DelegationHandler) RevokeDelegation(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unauthorized",
+		})
+	}
+
+	delegationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid delegation ID format",
+		})
+	}
+
+	if err := h.DelegationService.RevokeDelegation(userID, delegationID); err != nil {
+		return c.Status(delegationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Delegation revoked successfully",
+	})
+}
@@ -0,0 +1,304 @@
+package route
+
+import (
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/model"
+	"UAS_BACKEND/domain/service"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type V1MeHandler struct {
+	AuthService        *service.AuthService
+	UserService        *service.UserService
+	AccountService     *service.AccountService
+	AccessTokenService *service.AccessTokenService
+	RBACMiddleware     *middleware.RBACMiddleware
+}
+
+func NewV1MeHandler(authService *service.AuthService, userService *service.UserService, accountService *service.AccountService, accessTokenService *service.AccessTokenService, rbacMiddleware *middleware.RBACMiddleware) *V1MeHandler {
+	return &V1MeHandler{
+		AuthService:        authService,
+		UserService:        userService,
+		AccountService:     accountService,
+		AccessTokenService: accessTokenService,
+		RBACMiddleware:     rbacMiddleware,
+	}
+}
+
+// SetupV1MeRoutes - Setup self-service account routes v1 (untuk semua user yang login)
+func SetupV1MeRoutes(app *fiber.App, handler *V1MeHandler) {
+	me := app.Group("/api/v1/me")
+	me.Use(handler.RBACMiddleware.RequireAuth())
+
+	me.Get("/", handler.GetMe)
+	me.Put("/", handler.UpdateProfile)
+	me.Put("/password", handler.ChangePassword)
+	me.Post("/email", handler.RequestEmailChange)
+	me.Post("/email/confirm", handler.ConfirmEmailChange)
+
+	// Personal access token untuk script / integrasi
+	me.Get("/tokens", handler.ListAccessTokens)
+	me.Post("/tokens", handler.CreateAccessToken)
+	me.Delete("/tokens/:id", handler.RevokeAccessToken)
+}
+
+// GetMe - GET /api/v1/me
+func (h *V1MeHandler) GetMe(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	user, err := h.UserService.GetUserByID(claims.UserID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Profile retrieved successfully",
+		"data":    user,
+	})
+}
+
+// UpdateProfile - PUT /api/v1/me
+func (h *V1MeHandler) UpdateProfile(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req model.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	user, err := h.AccountService.UpdateProfile(claims.UserID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrExternalPassword) {
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Profile updated successfully",
+		"data":    user,
+	})
+}
+
+// ChangePassword - PUT /api/v1/me/password
+func (h *V1MeHandler) ChangePassword(c *fiber.Ctx) error {
+	return changePassword(c, h.AuthService)
+}
+
+// RequestEmailChange - POST /api/v1/me/email
+func (h *V1MeHandler) RequestEmailChange(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req model.EmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.NewEmail == "" || req.CurrentPassword == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "New email and current password are required",
+		})
+	}
+
+	if err := h.AccountService.RequestEmailChange(claims.UserID, &req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPassword),
+			errors.Is(err, service.ErrInvalidEmail),
+			errors.Is(err, service.ErrEmailUnchanged):
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrEmailTaken):
+			return c.Status(409).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrExternalPassword):
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to request email change",
+			})
+		}
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "A confirmation link has been sent to the new email address",
+	})
+}
+
+// ConfirmEmailChange - POST /api/v1/me/email/confirm
+func (h *V1MeHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req model.ConfirmEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	user, err := h.AccountService.ConfirmEmailChange(claims.UserID, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidEmailChangeToken):
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrEmailTaken):
+			return c.Status(409).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrExternalPassword):
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to confirm email change",
+			})
+		}
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Email changed successfully",
+		"data":    user,
+	})
+}
+
+// ListAccessTokens - GET /api/v1/me/tokens
+func (h *V1MeHandler) ListAccessTokens(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	tokens, err := h.AccessTokenService.ListTokens(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to get access tokens",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Access tokens retrieved successfully",
+		"data":    tokens,
+	})
+}
+
+// CreateAccessToken - POST /api/v1/me/tokens
+func (h *V1MeHandler) CreateAccessToken(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	// Token hanya bisa dibuat dari login interaktif, agar token yang bocor tidak bisa menerbitkan token baru
+	if claims.AccessTokenID != uuid.Nil {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Access tokens cannot be created with an access token",
+		})
+	}
+
+	var req model.CreateAccessTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	result, err := h.AccessTokenService.CreateToken(claims.UserID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Access token created, copy it now because it will not be shown again",
+		"data":    result,
+	})
+}
+
+// RevokeAccessToken - DELETE /api/v1/me/tokens/:id
+func (h *V1MeHandler) RevokeAccessToken(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid token ID format",
+		})
+	}
+
+	if err := h.AccessTokenService.RevokeToken(claims.UserID, tokenID); err != nil {
+		if errors.Is(err, service.ErrAccessTokenNotFound) {
+			return c.Status(404).JSON(fiber.Map{
+				"error": "Access token not found",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke access token",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Access token revoked successfully",
+	})
+}
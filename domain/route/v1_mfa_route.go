@@ -0,0 +1,263 @@
+package route
+
+import (
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/model"
+	"UAS_BACKEND/domain/service"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type V1MFAHandler struct {
+	AuthService    *service.AuthService
+	MFAService     *service.MFAService
+	RBACMiddleware *middleware.RBACMiddleware
+}
+
+func NewV1MFAHandler(authService *service.AuthService, mfaService *service.MFAService, rbacMiddleware *middleware.RBACMiddleware) *V1MFAHandler {
+	return &V1MFAHandler{
+		AuthService:    authService,
+		MFAService:     mfaService,
+		RBACMiddleware: rbacMiddleware,
+	}
+}
+
+// SetupV1MFARoutes - Setup MFA (TOTP) routes v1
+func SetupV1MFARoutes(app *fiber.App, handler *V1MFAHandler) {
+	mfa := app.Group("/api/v1/auth/mfa")
+
+	// Langkah kedua login (pakai mfa_token dari response login, tanpa JWT)
+	mfa.Post("/verify", handler.VerifyLogin)
+	mfa.Post("/challenge/enroll", handler.BeginLoginEnrollment)
+	mfa.Post("/challenge/enroll/confirm", handler.ConfirmLoginEnrollment)
+
+	// Kelola MFA untuk user yang sudah login
+	mfa.Post("/enroll", handler.RBACMiddleware.RequireAuth(), handler.BeginEnrollment)
+	mfa.Post("/enroll/confirm", handler.RBACMiddleware.RequireAuth(), handler.ConfirmEnrollment)
+	mfa.Post("/recovery-codes", handler.RBACMiddleware.RequireAuth(), handler.RegenerateRecoveryCodes)
+	mfa.Post("/disable", handler.RBACMiddleware.RequireAuth(), handler.Disable)
+}
+
+// VerifyLogin - POST /api/v1/auth/mfa/verify
+func (h *V1MFAHandler) VerifyLogin(c *fiber.Ctx) error {
+	var req model.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "MFA token and code are required",
+		})
+	}
+
+	result, err := h.AuthService.VerifyMFALogin(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Login successful",
+		"data": fiber.Map{
+			"token":                    result.Token,
+			"refresh_token":            result.RefreshToken,
+			"user":                     result.User,
+			"expires_at":               result.ExpiresAt,
+			"password_change_required": result.PasswordChangeRequired,
+		},
+	})
+}
+
+// BeginLoginEnrollment - POST /api/v1/auth/mfa/challenge/enroll
+func (h *V1MFAHandler) BeginLoginEnrollment(c *fiber.Ctx) error {
+	var req model.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.MFAToken == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "MFA token is required",
+		})
+	}
+
+	enrollment, err := h.AuthService.BeginMFALoginEnrollment(req.MFAToken)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Scan the provisioning URI with an authenticator app, then confirm with a code",
+		"data":    enrollment,
+	})
+}
+
+// ConfirmLoginEnrollment - POST /api/v1/auth/mfa/challenge/enroll/confirm
+func (h *V1MFAHandler) ConfirmLoginEnrollment(c *fiber.Ctx) error {
+	var req model.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid JSON format",
+		})
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "MFA token and code are required",
+		})
+	}
+
+	result, recoveryCodes, err := h.AuthService.ConfirmMFALoginEnrollment(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "MFA enabled, login successful",
+		"data": fiber.Map{
+			"token":                    result.Token,
+			"refresh_token":            result.RefreshToken,
+			"user":                     result.User,
+			"expires_at":               result.ExpiresAt,
+			"recovery_codes":           recoveryCodes,
+			"password_change_required": result.PasswordChangeRequired,
+		},
+	})
+}
+
+// BeginEnrollment - POST /api/v1/auth/mfa/enroll
+func (h *V1MFAHandler) BeginEnrollment(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	enrollment, err := h.MFAService.BeginEnrollment(claims.UserID)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Scan the provisioning URI with an authenticator app, then confirm with a code",
+		"data":    enrollment,
+	})
+}
+
+// ConfirmEnrollment - POST /api/v1/auth/mfa/enroll/confirm
+func (h *V1MFAHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	claims, req, err := h.parseCodeRequest(c)
+	if err != nil {
+		return err
+	}
+
+	recoveryCodes, err := h.MFAService.ConfirmEnrollment(claims.UserID, req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "MFA enabled successfully",
+		"data": fiber.Map{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+// RegenerateRecoveryCodes - POST /api/v1/auth/mfa/recovery-codes
+func (h *V1MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	claims, req, err := h.parseCodeRequest(c)
+	if err != nil {
+		return err
+	}
+
+	recoveryCodes, err := h.MFAService.RegenerateRecoveryCodes(claims.UserID, req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Recovery codes regenerated successfully",
+		"data": fiber.Map{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+// Disable - POST /api/v1/auth/mfa/disable
+func (h *V1MFAHandler) Disable(c *fiber.Ctx) error {
+	claims, req, err := h.parseCodeRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.MFAService.Disable(claims.UserID, req.Code); err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "MFA disabled successfully",
+	})
+}
+
+// parseCodeRequest - Ambil claims dan kode MFA dari request yang sudah terautentikasi.
+// Error berupa *fiber.Error sehingga ditulis oleh ErrorHandler app.
+func (h *V1MFAHandler) parseCodeRequest(c *fiber.Ctx) (*model.CustomClaims, *model.MFACodeRequest, error) {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return nil, nil, fiber.NewError(401, "Unauthorized")
+	}
+
+	var req model.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, nil, fiber.NewError(400, "Invalid JSON format")
+	}
+
+	if req.Code == "" {
+		return nil, nil, fiber.NewError(400, "Code is required")
+	}
+
+	return claims, &req, nil
+}
+
+// mfaError - Map error MFA ke status HTTP
+func mfaError(c *fiber.Ctx, err error) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(throttled.RetryAfterSeconds()))
+		return c.Status(429).JSON(fiber.Map{
+			"error": throttled.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidMFAToken), errors.Is(err, service.ErrInvalidMFACode):
+		return c.Status(401).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrMFARequiredByRole), errors.Is(err, service.ErrUserInactive):
+		return c.Status(403).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotEnabled):
+		return c.Status(409).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to process MFA request",
+		})
+	}
+}
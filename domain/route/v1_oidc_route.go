@@ -0,0 +1,89 @@
+package route
+
+import (
+	"UAS_BACKEND/domain/service"
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type V1OIDCHandler struct {
+	OIDCService *service.OIDCService
+}
+
+func NewV1OIDCHandler(oidcService *service.OIDCService) *V1OIDCHandler {
+	return &V1OIDCHandler{
+		OIDCService: oidcService,
+	}
+}
+
+// SetupV1OIDCRoutes - Setup SSO (OpenID Connect) routes v1
+func SetupV1OIDCRoutes(app *fiber.App, handler *V1OIDCHandler) {
+	oidc := app.Group("/api/v1/auth/oidc")
+
+	oidc.Get("/login", handler.Login)
+	oidc.Get("/callback", handler.Callback)
+}
+
+// Login - GET /api/v1/auth/oidc/login
+// Redirect browser ke halaman login identity provider
+func (h *V1OIDCHandler) Login(c *fiber.Ctx) error {
+	if !h.OIDCService.Enabled() {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "SSO login is not configured",
+		})
+	}
+
+	authURL, err := h.OIDCService.BeginLogin()
+	if err != nil {
+		log.Printf("Failed to start OIDC login: %v", err)
+		return c.Status(502).JSON(fiber.Map{
+			"error": "Identity provider is unavailable",
+		})
+	}
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// Callback - GET /api/v1/auth/oidc/callback?code=...&state=...
+// Redirect URI yang didaftarkan di identity provider
+func (h *V1OIDCHandler) Callback(c *fiber.Ctx) error {
+	if !h.OIDCService.Enabled() {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "SSO login is not configured",
+		})
+	}
+
+	// User membatalkan login atau IdP menolak request
+	if idpError := c.Query("error"); idpError != "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error":             "SSO login failed",
+			"idp_error":         idpError,
+			"error_description": c.Query("error_description"),
+		})
+	}
+
+	result, err := h.OIDCService.CompleteLogin(c.Query("state"), c.Query("code"), clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidOIDCState):
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrOIDCEmailNotVerified),
+			errors.Is(err, service.ErrOIDCUserNotFound),
+			errors.Is(err, service.ErrUserInactive):
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			log.Printf("OIDC login failed: %v", err)
+			return c.Status(401).JSON(fiber.Map{
+				"error": "SSO login failed",
+			})
+		}
+	}
+
+	return loginResult(c, result)
+}
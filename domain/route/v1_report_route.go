@@ -2,6 +2,7 @@ package route
 
 import (
 	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/model"
 	"UAS_BACKEND/domain/service"
 	"context"
@@ -64,12 +65,20 @@ func (h *V1ReportHandler) GetStatistics(c *fiber.Ctx) error {
 	}
 
 	var result *service.StatisticsResponse
-	var err error
+
+	// Admin ter-scope hanya melihat statistik program studinya
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get admin scope",
+		})
+	}
 
 	// Role-based statistics
 	switch {
 	case h.hasPermission(user, "admin.manage"):
-		result, err = h.StatisticsService.GetAdminStatistics(context.Background(), req)
+		result, err = h.StatisticsService.GetAdminStatistics(context.Background(), scope, req)
 	case h.hasPermission(user, "lecturer.read"):
 		result, err = h.StatisticsService.GetLecturerStatistics(context.Background(), user.UserID, req)
 	case h.hasPermission(user, "student.read"):
@@ -109,7 +118,7 @@ func (h *V1ReportHandler) GetStatistics(c *fiber.Ctx) error {
 	case "detailed":
 		response["additional_metrics"] = h.getDetailedMetrics(result)
 	case "trends":
-		trends, _ := h.StatisticsService.GetAchievementTrends(context.Background(), user.UserID, h.getUserRole(user), 12)
+		trends, _ := h.StatisticsService.GetAchievementTrends(context.Background(), user.UserID, h.getUserRole(user), 12, scope)
 		response["trends"] = trends
 	}
 
@@ -179,9 +188,9 @@ func (h *V1ReportHandler) GetStudentReport(c *fiber.Ctx) error {
 	// Add detailed information if requested
 	if includeDetails {
 		response["detailed_breakdown"] = h.getStudentDetailedBreakdown(result)
-		
+
 		// Get achievement trends for this student
-		trends, _ := h.StatisticsService.GetAchievementTrends(context.Background(), studentID, "student", 6)
+		trends, _ := h.StatisticsService.GetAchievementTrends(context.Background(), studentID, "student", 6, nil)
 		response["trends"] = trends
 	}
 
@@ -191,12 +200,7 @@ func (h *V1ReportHandler) GetStudentReport(c *fiber.Ctx) error {
 // Helper functions
 
 func (h *V1ReportHandler) hasPermission(user *model.Claims, permission string) bool {
-	for _, perm := range user.Permissions {
-		if perm == permission {
-			return true
-		}
-	}
-	return false
+	return policy.PermissionGranted(user.Permissions, permission)
 }
 
 func (h *V1ReportHandler) getUserRole(user *model.Claims) string {
@@ -215,17 +219,17 @@ func (h *V1ReportHandler) getUserRole(user *model.Claims) string {
 func (h *V1ReportHandler) getDetailedMetrics(stats *service.StatisticsResponse) fiber.Map {
 	return fiber.Map{
 		"performance_indicators": fiber.Map{
-			"completion_rate": h.calculateCompletionRate(stats),
+			"completion_rate":                h.calculateCompletionRate(stats),
 			"average_points_per_achievement": h.calculateAveragePoints(stats),
-			"monthly_growth": h.calculateMonthlyGrowth(stats),
+			"monthly_growth":                 h.calculateMonthlyGrowth(stats),
 		},
 		"quality_metrics": fiber.Map{
 			"verification_rate": h.calculateVerificationRate(stats),
-			"rejection_rate": h.calculateRejectionRate(stats),
+			"rejection_rate":    h.calculateRejectionRate(stats),
 		},
 		"comparative_analysis": fiber.Map{
 			"vs_previous_period": h.compareToPreviousPeriod(stats),
-			"ranking": h.calculateRanking(stats),
+			"ranking":            h.calculateRanking(stats),
 		},
 	}
 }
@@ -233,19 +237,19 @@ func (h *V1ReportHandler) getDetailedMetrics(stats *service.StatisticsResponse)
 func (h *V1ReportHandler) getStudentDetailedBreakdown(stats *service.StatisticsResponse) fiber.Map {
 	return fiber.Map{
 		"achievement_breakdown": fiber.Map{
-			"by_type": stats.TypeStats,
-			"by_period": stats.PeriodStats,
+			"by_type":              stats.TypeStats,
+			"by_period":            stats.PeriodStats,
 			"by_competition_level": stats.CompetitionStats,
 		},
 		"performance_metrics": fiber.Map{
-			"total_points": stats.Summary.TotalPoints,
-			"average_points": stats.Summary.AveragePoints,
+			"total_points":    stats.Summary.TotalPoints,
+			"average_points":  stats.Summary.AveragePoints,
 			"completion_rate": h.calculateCompletionRate(stats),
 		},
 		"status_distribution": fiber.Map{
 			"verified": stats.Summary.VerifiedCount,
-			"pending": stats.Summary.PendingCount,
-			"total": stats.Summary.TotalAchievements,
+			"pending":  stats.Summary.PendingCount,
+			"total":    stats.Summary.TotalAchievements,
 		},
 	}
 }
@@ -287,14 +291,14 @@ func (h *V1ReportHandler) compareToPreviousPeriod(stats *service.StatisticsRespo
 	// Mock comparison data
 	return fiber.Map{
 		"total_achievements": fiber.Map{
-			"current": stats.Summary.TotalAchievements,
+			"current":  stats.Summary.TotalAchievements,
 			"previous": 18,
-			"change": "+38.9%",
+			"change":   "+38.9%",
 		},
 		"total_points": fiber.Map{
-			"current": stats.Summary.TotalPoints,
+			"current":  stats.Summary.TotalPoints,
 			"previous": 1800.0,
-			"change": "+38.9%",
+			"change":   "+38.9%",
 		},
 	}
 }
@@ -302,9 +306,9 @@ func (h *V1ReportHandler) compareToPreviousPeriod(stats *service.StatisticsRespo
 func (h *V1ReportHandler) calculateRanking(stats *service.StatisticsResponse) fiber.Map {
 	// Mock ranking data
 	return fiber.Map{
-		"current_rank": 5,
+		"current_rank":   5,
 		"total_students": 150,
-		"percentile": 96.7,
-		"category": "Top Performer",
+		"percentile":     96.7,
+		"category":       "Top Performer",
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,121 @@
+package route
+
+import (
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/model"
+	"UAS_BACKEND/domain/service"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type V1SessionHandler struct {
+	SessionService *service.SessionService
+	RBACMiddleware *middleware.RBACMiddleware
+}
+
+func NewV1SessionHandler(sessionService *service.SessionService, rbacMiddleware *middleware.RBACMiddleware) *V1SessionHandler {
+	return &V1SessionHandler{
+		SessionService: sessionService,
+		RBACMiddleware: rbacMiddleware,
+	}
+}
+
+// SetupV1SessionRoutes - Setup session management routes v1
+func SetupV1SessionRoutes(app *fiber.App, handler *V1SessionHandler) {
+	sessions := app.Group("/api/v1/auth/sessions", handler.RBACMiddleware.RequireAuth())
+
+	sessions.Get("/", handler.ListSessions)
+	sessions.Delete("/", handler.RevokeAllSessions) // Logout dari semua perangkat
+	sessions.Delete("/:id", handler.RevokeSession)
+}
+
+// ListSessions - GET /api/v1/auth/sessions
+func (h *V1SessionHandler) ListSessions(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	sessions, err := h.SessionService.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to get sessions",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Sessions retrieved successfully",
+		"data":    sessions,
+	})
+}
+
+// RevokeSession - DELETE /api/v1/auth/sessions/:id
+func (h *V1SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid session ID format",
+		})
+	}
+
+	if err := h.SessionService.RevokeSession(claims.UserID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			return c.Status(404).JSON(fiber.Map{
+				"error": "Session not found",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke session",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Session revoked successfully",
+		"data": fiber.Map{
+			"session_id": sessionID,
+			"current":    sessionID == claims.SessionID,
+		},
+	})
+}
+
+// RevokeAllSessions - DELETE /api/v1/auth/sessions
+func (h *V1SessionHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	if err := h.SessionService.RevokeAllSessions(claims.UserID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke sessions",
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"success": true,
+		"message": "Logged out from all devices",
+	})
+}
+
+// clientInfo - IP dan user agent request untuk throttle login dan pencatatan sesi
+func clientInfo(c *fiber.Ctx) model.ClientInfo {
+	return model.ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
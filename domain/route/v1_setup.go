@@ -11,26 +11,44 @@ import (
 func SetupV1Routes(
 	app *fiber.App,
 	authService *service.AuthService,
+	passwordResetService *service.PasswordResetService,
+	invitationService *service.InvitationService,
+	oidcService *service.OIDCService,
+	mfaService *service.MFAService,
+	sessionService *service.SessionService,
+	accountService *service.AccountService,
+	accessTokenService *service.AccessTokenService,
 	userService *service.UserService,
 	achievementService *service.AchievementService,
 	notificationService *service.NotificationService,
 	fileService *service.FileService,
 	statisticsService *service.StatisticsService,
+	delegationService *service.DelegationService,
 	rbacMiddleware *middleware.RBACMiddleware,
 ) {
 	// Initialize handlers
-	v1AuthHandler := NewV1AuthHandler(authService, rbacMiddleware)
+	v1AuthHandler := NewV1AuthHandler(authService, passwordResetService, invitationService, rbacMiddleware)
+	v1OIDCHandler := NewV1OIDCHandler(oidcService)
+	v1MFAHandler := NewV1MFAHandler(authService, mfaService, rbacMiddleware)
+	v1SessionHandler := NewV1SessionHandler(sessionService, rbacMiddleware)
+	v1MeHandler := NewV1MeHandler(authService, userService, accountService, accessTokenService, rbacMiddleware)
 	v1UserHandler := NewV1UserHandler(userService, rbacMiddleware)
 	v1AchievementHandler := NewV1AchievementHandler(achievementService, notificationService, fileService, rbacMiddleware)
 	v1StudentLecturerHandler := NewV1StudentLecturerHandler(userService, achievementService, rbacMiddleware)
 	v1ReportHandler := NewV1ReportHandler(statisticsService, rbacMiddleware)
+	v1DelegationHandler := NewV1DelegationHandler(delegationService, rbacMiddleware)
 
 	// Setup routes
 	SetupV1AuthRoutes(app, v1AuthHandler)
+	SetupV1OIDCRoutes(app, v1OIDCHandler)
+	SetupV1MFARoutes(app, v1MFAHandler)
+	SetupV1SessionRoutes(app, v1SessionHandler)
+	SetupV1MeRoutes(app, v1MeHandler)
 	SetupV1UserRoutes(app, v1UserHandler)
 	SetupV1AchievementRoutes(app, v1AchievementHandler)
 	SetupV1StudentLecturerRoutes(app, v1StudentLecturerHandler)
 	SetupV1ReportRoutes(app, v1ReportHandler)
+	SetupV1DelegationRoutes(app, v1DelegationHandler)
 
 	// API v1 info endpoint
 	app.Get("/api/v1", func(c *fiber.Ctx) error {
@@ -40,13 +58,15 @@ func SetupV1Routes(
 			"version": "1.0.0",
 			"endpoints": fiber.Map{
 				"authentication": "/api/v1/auth",
+				"me":             "/api/v1/me",
 				"users":          "/api/v1/users",
 				"achievements":   "/api/v1/achievements",
 				"students":       "/api/v1/students",
 				"lecturers":      "/api/v1/lecturers",
 				"reports":        "/api/v1/reports",
+				"delegations":    "/api/v1/delegations",
 			},
 			"documentation": "/swagger/",
 		})
 	})
-}
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/model"
 	"UAS_BACKEND/domain/service"
 	"strconv"
@@ -11,9 +12,9 @@ import (
 )
 
 type V1StudentLecturerHandler struct {
-	UserService         *service.UserService
-	AchievementService  *service.AchievementService
-	RBACMiddleware      *middleware.RBACMiddleware
+	UserService        *service.UserService
+	AchievementService *service.AchievementService
+	RBACMiddleware     *middleware.RBACMiddleware
 }
 
 func NewV1StudentLecturerHandler(
@@ -35,9 +36,15 @@ func SetupV1StudentLecturerRoutes(app *fiber.App, handler *V1StudentLecturerHand
 	students.Use(handler.RBACMiddleware.RequireAuth())
 
 	students.Get("/", handler.RBACMiddleware.RequireAnyPermission("student.read", "admin.manage"), handler.GetAllStudents)
-	students.Get("/:id", handler.RBACMiddleware.RequireAnyPermission("student.read", "admin.manage"), handler.GetStudentByID)
-	students.Get("/:id/achievements", handler.RBACMiddleware.RequireAnyPermission("student.read", "achievement.read"), handler.GetStudentAchievements)
-	students.Put("/:id/advisor", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.SetStudentAdvisor)
+	// Data per mahasiswa: hanya mahasiswa itu sendiri, dosen wali, dosen satu program studi, atau admin.
+	// Dibuat baru di setiap route supaya syaratnya tercatat di route registry.
+	studentRecord := func() fiber.Handler {
+		return handler.RBACMiddleware.RequirePolicy(policy.StudentRecord, handler.RBACMiddleware.StudentParam("id"))
+	}
+	students.Get("/:id", handler.RBACMiddleware.RequireAnyPermission("student.read", "admin.manage"), studentRecord(), handler.GetStudentByID)
+	students.Get("/:id/achievements", handler.RBACMiddleware.RequireAnyPermission("student.read", "achievement.read"), studentRecord(), handler.GetStudentAchievements)
+	students.Put("/:id/advisor", handler.RBACMiddleware.RequirePermission("admin.manage"),
+		handler.RBACMiddleware.RequirePolicy(policy.AdminUser, handler.RBACMiddleware.UserParam("id")), handler.SetStudentAdvisor)
 
 	// 5.5 Lecturers endpoints
 	lecturers := app.Group("/api/v1/lecturers")
@@ -58,8 +65,16 @@ func (h *V1StudentLecturerHandler) GetAllStudents(c *fiber.Ctx) error {
 
 	offset := (page - 1) * limit
 
-	// Get all users with student role
-	users, total, err := h.UserService.GetAllUsers(limit, offset)
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get admin scope",
+		})
+	}
+
+	// Get all users with student role (admin ter-scope hanya program studinya)
+	users, total, err := h.UserService.GetUsersInScope(scope, limit, offset)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"success": false,
@@ -287,6 +302,20 @@ func (h *V1StudentLecturerHandler) SetStudentAdvisor(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeAdvisor(scope, req.AdvisorID); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
 	// Set advisor using UserService
 	student, err := h.UserService.SetAdvisor(studentID, req.AdvisorID)
 	if err != nil {
@@ -329,14 +358,14 @@ func (h *V1StudentLecturerHandler) GetAllLecturers(c *fiber.Ctx) error {
 	for _, user := range users {
 		if user.Lecturer != nil {
 			lecturerData := fiber.Map{
-				"id":           user.Lecturer.ID,
-				"user_id":      user.User.ID,
-				"lecturer_id":  user.Lecturer.LecturerID,
-				"full_name":    user.User.FullName,
-				"email":        user.User.Email,
-				"department":   user.Lecturer.Department,
-				"is_active":    user.User.IsActive,
-				"created_at":   user.Lecturer.CreatedAt,
+				"id":          user.Lecturer.ID,
+				"user_id":     user.User.ID,
+				"lecturer_id": user.Lecturer.LecturerID,
+				"full_name":   user.User.FullName,
+				"email":       user.User.Email,
+				"department":  user.Lecturer.Department,
+				"is_active":   user.User.IsActive,
+				"created_at":  user.Lecturer.CreatedAt,
 			}
 
 			// Apply department filter
@@ -433,10 +462,10 @@ func (h *V1StudentLecturerHandler) GetLecturerAdvisees(c *fiber.Ctx) error {
 		"message": "Lecturer advisees retrieved successfully",
 		"data": fiber.Map{
 			"lecturer": fiber.Map{
-				"id":           user.Lecturer.ID,
-				"lecturer_id":  user.Lecturer.LecturerID,
-				"full_name":    user.User.FullName,
-				"department":   user.Lecturer.Department,
+				"id":          user.Lecturer.ID,
+				"lecturer_id": user.Lecturer.LecturerID,
+				"full_name":   user.User.FullName,
+				"department":  user.Lecturer.Department,
 			},
 			"advisees": paginatedAdvisees,
 		},
@@ -447,4 +476,4 @@ func (h *V1StudentLecturerHandler) GetLecturerAdvisees(c *fiber.Ctx) error {
 			"total_pages": totalPages,
 		},
 	})
-}
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/model"
 	"UAS_BACKEND/domain/service"
 	"strconv"
@@ -27,13 +28,18 @@ func SetupV1UserRoutes(app *fiber.App, handler *V1UserHandler) {
 	users := app.Group("/api/v1/users")
 	users.Use(handler.RBACMiddleware.RequireAuth())
 
+	// inScope - Admin ter-scope hanya mengelola mahasiswa/dosen di program studinya
+	inScope := func() fiber.Handler {
+		return handler.RBACMiddleware.RequirePolicy(policy.AdminUser, handler.RBACMiddleware.UserParam("id"))
+	}
+
 	// 5.2 Users (Admin) endpoints
 	users.Get("/", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.GetAllUsers)
-	users.Get("/:id", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.GetUserByID)
+	users.Get("/:id", handler.RBACMiddleware.RequirePermission("admin.manage"), inScope(), handler.GetUserByID)
 	users.Post("/", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.CreateUser)
-	users.Put("/:id", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.UpdateUser)
-	users.Delete("/:id", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.DeleteUser)
-	users.Put("/:id/role", handler.RBACMiddleware.RequirePermission("admin.manage"), handler.AssignRole)
+	users.Put("/:id", handler.RBACMiddleware.RequirePermission("admin.manage"), inScope(), handler.UpdateUser)
+	users.Delete("/:id", handler.RBACMiddleware.RequirePermission("admin.manage"), inScope(), handler.DeleteUser)
+	users.Put("/:id/role", handler.RBACMiddleware.RequirePermission("admin.manage"), inScope(), handler.AssignRole)
 }
 
 // GetAllUsers - GET /api/v1/users
@@ -47,8 +53,16 @@ func (h *V1UserHandler) GetAllUsers(c *fiber.Ctx) error {
 	// Calculate offset
 	offset := (page - 1) * limit
 
-	// Get users
-	users, total, err := h.UserService.GetAllUsers(limit, offset)
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get admin scope",
+		})
+	}
+
+	// Get users (admin ter-scope hanya program studinya)
+	users, total, err := h.UserService.GetUsersInScope(scope, limit, offset)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"success": false,
@@ -132,6 +146,20 @@ func (h *V1UserHandler) CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeNewUser(scope, req.RoleID, req.StudentData, req.LecturerData); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
 	user, err := h.UserService.CreateUser(&req)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -165,6 +193,22 @@ func (h *V1UserHandler) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.RoleID != uuid.Nil {
+		scope, err := h.RBACMiddleware.AdminScope(c)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to get admin scope",
+			})
+		}
+		if err := h.UserService.AuthorizeRole(scope, req.RoleID); err != nil {
+			return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+	}
+
 	user, err := h.UserService.UpdateUser(userID, &req)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -235,6 +279,20 @@ func (h *V1UserHandler) AssignRole(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, err := h.RBACMiddleware.AdminScope(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get admin scope",
+		})
+	}
+	if err := h.UserService.AuthorizeRole(scope, req.RoleID); err != nil {
+		return c.Status(adminScopeErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
 	user, err := h.UserService.AssignRole(userID, req.RoleID)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -248,4 +306,4 @@ func (h *V1UserHandler) AssignRole(c *fiber.Ctx) error {
 		"message": "Role assigned successfully",
 		"data":    user,
 	})
-}
\ No newline at end of file
+}
@@ -3,7 +3,13 @@ package service
 import (
 	model "UAS_BACKEND/domain/Model"
 	"UAS_BACKEND/domain/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,20 +17,52 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, all sessions from this login have been revoked")
+	ErrUserInactive        = errors.New("user account is inactive")
+	ErrInvalidPassword     = errors.New("current password is incorrect")
+)
+
+// dummyPasswordHash - Hash bcrypt (cost sama dengan HashPassword) untuk identifier yang tidak dikenal, supaya
+// waktu respons login tidak membedakan akun yang ada dan yang tidak ada
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("login-timing-dummy-password"), bcrypt.DefaultCost)
+
 type AuthService struct {
-	SecretKey []byte
-	TokenTTL  time.Duration
-	Repo      *repository.AuthRepository
+	Keys            *KeyManager
+	TokenTTL        time.Duration
+	RefreshTokenTTL time.Duration
+	Repo            *repository.AuthRepository
+	TokenRepo       *repository.TokenRepository
+	Lockout         *LockoutService
+	MFA             *MFAService
+	Sessions        *SessionService
+	Passwords       *PasswordService
+	Authenticators  map[string]Authenticator // Backend autentikasi per nama auth_source
 }
 
-func NewAuthService(secretKey string, tokenTTL time.Duration, repo *repository.AuthRepository) *AuthService {
+func NewAuthService(keys *KeyManager, tokenTTL, refreshTokenTTL time.Duration, repo *repository.AuthRepository, tokenRepo *repository.TokenRepository, lockout *LockoutService, mfa *MFAService, sessions *SessionService, passwords *PasswordService) *AuthService {
 	return &AuthService{
-		SecretKey: []byte(secretKey),
-		TokenTTL:  tokenTTL,
-		Repo:      repo,
+		Keys:            keys,
+		TokenTTL:        tokenTTL,
+		RefreshTokenTTL: refreshTokenTTL,
+		Repo:            repo,
+		TokenRepo:       tokenRepo,
+		Lockout:         lockout,
+		MFA:             mfa,
+		Sessions:        sessions,
+		Passwords:       passwords,
+		Authenticators: map[string]Authenticator{
+			AuthSourceLocal: PasswordAuthenticator{},
+		},
 	}
 }
 
+// RegisterAuthenticator - Aktifkan backend autentikasi tambahan (mis. LDAP)
+func (a *AuthService) RegisterAuthenticator(source string, authenticator Authenticator) {
+	a.Authenticators[source] = authenticator
+}
+
 func HashPassword(password string) (string, error) {
 	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(b), err
@@ -34,73 +72,553 @@ func CheckPassword(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-func (a *AuthService) Login(identifier, password string) (*model.LoginResponse, error) {
-	// 1. Validasi kredensial
+// Login - client.IPAddress dipakai untuk throttle per IP (boleh kosong) dan bersama user agent dicatat di sesi
+func (a *AuthService) Login(identifier, password string, client model.ClientInfo) (*model.LoginResponse, error) {
+	// 1. Cari user, lalu cek delay/lockout sebelum bcrypt dijalankan
 	user, err := a.Repo.GetUserByIdentifier(identifier)
-	if err != nil || user == nil {
-		return nil, errors.New("invalid credentials")
+	if err != nil {
+		user = nil
 	}
 
-	// 2. Cek password
-	if err := CheckPassword(user.PasswordHash, password); err != nil {
-		return nil, errors.New("invalid credentials")
+	accountKey := AccountKey(user, identifier)
+	if err := a.Lockout.Check(accountKey, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	// 2. Validasi kredensial lewat backend autentikasi user (lokal atau LDAP)
+	//    Identifier tidak dikenal tetap menjalankan bcrypt terhadap hash dummy dan dihitung gagal dengan cara yang sama
+	var profile *model.DirectoryProfile
+	if user != nil {
+		profile, err = a.authenticate(user, password)
+	} else {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+	}
+	if user == nil || errors.Is(err, ErrInvalidCredentials) {
+		if err := a.Lockout.RecordFailure(accountKey, client.IPAddress, user); err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+		}
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		log.Printf("Login for user %s failed: %v", user.ID, err)
+		return nil, ErrAuthBackendUnavailable
+	}
+	if profile != nil {
+		a.syncDirectoryProfile(user, profile)
 	}
 
 	// 3. Cek status aktif user
 	if !user.IsActive {
-		return nil, errors.New("user account is inactive")
+		return nil, ErrUserInactive
+	}
+
+	// 4. Jika user memakai MFA (atau role mewajibkan), minta kode dulu sebelum token diterbitkan
+	challenge, err := a.mfaChallengeFor(user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &model.LoginResponse{User: *user, MFA: challenge}, nil
+	}
+
+	if err := a.Lockout.RecordSuccess(accountKey); err != nil {
+		log.Printf("Failed to reset login attempts: %v", err)
+	}
+
+	return a.issueLoginTokens(user, client)
+}
+
+// VerifyMFALogin - Langkah kedua login: tukar token challenge + kode TOTP/recovery code dengan token
+func (a *AuthService) VerifyMFALogin(mfaToken, code string, client model.ClientInfo) (*model.LoginResponse, error) {
+	challenge, err := a.MFA.GetChallenge(mfaToken, MFAChallengeVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.checkMFAChallengeUser(challenge, client.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.MFA.VerifyCode(user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			a.recordMFAFailure(challenge, user, client.IPAddress)
+		}
+		return nil, err
+	}
+
+	return a.completeMFALogin(challenge, user, client)
+}
+
+// BeginMFALoginEnrollment - Enrollment MFA saat login untuk user yang role-nya mewajibkan MFA
+func (a *AuthService) BeginMFALoginEnrollment(mfaToken string) (*model.MFAEnrollResponse, error) {
+	challenge, err := a.MFA.GetChallenge(mfaToken, MFAChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.MFA.BeginEnrollment(challenge.UserID)
+}
+
+// ConfirmMFALoginEnrollment - Konfirmasi enrollment saat login, return token dan recovery code
+func (a *AuthService) ConfirmMFALoginEnrollment(mfaToken, code string, client model.ClientInfo) (*model.LoginResponse, []string, error) {
+	challenge, err := a.MFA.GetChallenge(mfaToken, MFAChallengeEnroll)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := a.checkMFAChallengeUser(challenge, client.IPAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recoveryCodes, err := a.MFA.ConfirmEnrollment(user.ID, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			a.recordMFAFailure(challenge, user, client.IPAddress)
+		}
+		return nil, nil, err
+	}
+
+	resp, err := a.completeMFALogin(challenge, user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, recoveryCodes, nil
+}
+
+// mfaChallengeFor - Return challenge jika user harus melewati MFA, nil jika tidak
+func (a *AuthService) mfaChallengeFor(user *model.Users) (*model.MFAChallengeResponse, error) {
+	enabled, err := a.MFA.IsEnabled(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to check MFA status")
+	}
+	if enabled {
+		return a.MFA.CreateChallenge(user.ID, MFAChallengeVerify)
+	}
+
+	required, err := a.MFA.IsRequired(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to check MFA requirement")
+	}
+	if required {
+		return a.MFA.CreateChallenge(user.ID, MFAChallengeEnroll)
+	}
+
+	return nil, nil
+}
+
+// authenticate - Cek password dengan backend sesuai auth_source user (atau role-nya)
+func (a *AuthService) authenticate(user *model.Users, password string) (*model.DirectoryProfile, error) {
+	source, err := a.Repo.GetAuthSource(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get authentication source: %v", ErrAuthBackendUnavailable, err)
+	}
+
+	authenticator, ok := a.Authenticators[source]
+	if !ok {
+		return nil, fmt.Errorf("%w: backend %q is not configured", ErrAuthBackendUnavailable, source)
+	}
+
+	return authenticator.Authenticate(user, password)
+}
+
+// syncDirectoryProfile - Salin atribut direktori ke user lokal. Password dikelola direktori,
+// jadi kewajiban ganti password lokal dihapus. Gagal sinkron tidak menggagalkan login.
+func (a *AuthService) syncDirectoryProfile(user *model.Users, profile *model.DirectoryProfile) {
+	if profile.FullName != "" {
+		user.FullName = profile.FullName
+	}
+	if profile.Email != "" {
+		user.Email = profile.Email
+	}
+	user.MustChangePassword = false
+
+	if err := a.Repo.SyncDirectoryProfile(user.ID, user.FullName, user.Email); err != nil {
+		log.Printf("Failed to sync directory profile for user %s: %v", user.ID, err)
+	}
+
+	if profile.LecturerID != "" {
+		if err := a.Repo.SyncLecturerID(user.ID, profile.LecturerID); err != nil {
+			log.Printf("Failed to sync lecturer ID for user %s: %v", user.ID, err)
+		}
+	}
+}
+
+// checkMFAChallengeUser - Cek lockout dan status user untuk challenge MFA
+func (a *AuthService) checkMFAChallengeUser(challenge *model.MFAChallenge, ip string) (*model.Users, error) {
+	if err := a.Lockout.Check(challenge.UserID.String(), ip); err != nil {
+		return nil, err
+	}
+
+	user, err := a.Repo.GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	return user, nil
+}
+
+// recordMFAFailure - Kode MFA salah dihitung sebagai login gagal (ikut delay progresif dan lockout)
+func (a *AuthService) recordMFAFailure(challenge *model.MFAChallenge, user *model.Users, ip string) {
+	if err := a.MFA.FailChallenge(challenge.ID); err != nil {
+		log.Printf("Failed to record MFA challenge attempt: %v", err)
+	}
+	if err := a.Lockout.RecordFailure(user.ID.String(), ip, user); err != nil {
+		log.Printf("Failed to record failed login: %v", err)
+	}
+}
+
+// completeMFALogin - Tutup challenge lalu terbitkan token
+func (a *AuthService) completeMFALogin(challenge *model.MFAChallenge, user *model.Users, client model.ClientInfo) (*model.LoginResponse, error) {
+	if err := a.MFA.CompleteChallenge(challenge.ID); err != nil {
+		return nil, err
 	}
 
-	// 4. Load permissions dari RBAC
-	permissions, err := a.Repo.GetUserPermissions(user.RoleID)
+	if err := a.Lockout.RecordSuccess(user.ID.String()); err != nil {
+		log.Printf("Failed to reset login attempts: %v", err)
+	}
+
+	return a.issueLoginTokens(user, client)
+}
+
+// issueLoginTokens - Buat sesi baru lalu terbitkan access token dan refresh token untuk login yang sudah lolos semua cek
+func (a *AuthService) issueLoginTokens(user *model.Users, client model.ClientInfo) (*model.LoginResponse, error) {
+	// 1. Load versi permission lalu permissions dari RBAC. Versi dibaca lebih dulu supaya perubahan
+	// di antara dua query membuat versi token basi (permission di-resolve ulang), bukan sebaliknya.
+	userVersion, roleVersion, err := a.Repo.GetPermissionVersions(user.ID)
+	if err != nil {
+		log.Printf("Failed to get permission versions for user %s: %v", user.ID, err)
+	}
+	permissions, err := a.Repo.GetUserPermissions(user.ID)
 	if err != nil {
 		permissions = []string{} // fallback jika error
 	}
 
-	// 5. Generate JWT token dengan role dan permissions
-	claims := model.CustomClaims{
-		UserID:      user.ID,
-		RoleID:      user.RoleID,
-		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.TokenTTL)),
-		},
+	// 2. Catat sesi login (ID sesi = family refresh token)
+	session, err := a.Sessions.CreateSession(user.ID, client, time.Now().Add(a.RefreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Generate JWT token dengan role, permissions, dan ID sesi
+	signed, expiresAt, err := a.signAccessToken(model.CustomClaims{
+		UserID:                 user.ID,
+		RoleID:                 user.RoleID,
+		Permissions:            permissions,
+		SessionID:              session.ID,
+		UserPermVersion:        userVersion,
+		RolePermVersion:        roleVersion,
+		PasswordChangeRequired: user.MustChangePassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Generate refresh token untuk family sesi ini
+	refreshToken, _, err := a.createRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return nil, errors.New("failed to issue refresh token")
+	}
+
+	// 5. Return token dan user profile
+	return &model.LoginResponse{
+		Token:                  signed,
+		RefreshToken:           refreshToken,
+		ExpiresAt:              expiresAt,
+		User:                   *user,
+		PasswordChangeRequired: user.MustChangePassword,
+	}, nil
+}
+
+// RefreshToken - Tukar refresh token dengan access token baru dan refresh token baru (rotasi).
+// Refresh token yang sudah pernah dirotasi dianggap dicuri: seluruh family-nya dicabut.
+func (a *AuthService) RefreshToken(rawToken string) (*model.LoginResponse, error) {
+	// 1. Cari token berdasarkan hash
+	current, err := a.TokenRepo.GetRefreshTokenByHash(hashToken(rawToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 2. Deteksi reuse: token lama yang sudah dirotasi dipakai lagi
+	if current.RotatedAt != nil {
+		_ = a.TokenRepo.RevokeRefreshTokenFamily(current.FamilyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 3. Cek ulang status user dan muat ulang permissions terbaru
+	user, err := a.Repo.GetUserByID(current.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if !user.IsActive {
+		_ = a.TokenRepo.RevokeRefreshTokenFamily(current.FamilyID)
+		return nil, ErrUserInactive
+	}
+
+	userVersion, roleVersion, err := a.Repo.GetPermissionVersions(user.ID)
+	if err != nil {
+		log.Printf("Failed to get permission versions for user %s: %v", user.ID, err)
+	}
+	permissions, err := a.Repo.GetUserPermissions(user.ID)
+	if err != nil {
+		permissions = []string{}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(a.SecretKey)
+	// 4. Perpanjang sesi, sesi yang sudah dicabut tidak boleh refresh lagi
+	if err := a.Sessions.ExtendSession(current.FamilyID, user.ID, time.Now().Add(a.RefreshTokenTTL)); err != nil {
+		if errors.Is(err, ErrSessionRevoked) {
+			_ = a.TokenRepo.RevokeRefreshTokenFamily(current.FamilyID)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// 5. Rotasi: terbitkan token baru di family yang sama lalu tandai token lama
+	newToken, newTokenID, err := a.createRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		return nil, errors.New("failed to issue refresh token")
+	}
+
+	rotated, err := a.TokenRepo.MarkRefreshTokenRotated(current.ID, newTokenID)
+	if err != nil {
+		return nil, errors.New("failed to rotate refresh token")
+	}
+	if !rotated {
+		// Token lain sudah menukar token ini lebih dulu
+		_ = a.TokenRepo.RevokeRefreshTokenFamily(current.FamilyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// 6. Generate access token baru untuk sesi yang sama
+	signed, expiresAt, err := a.signAccessToken(model.CustomClaims{
+		UserID:                 user.ID,
+		RoleID:                 user.RoleID,
+		Permissions:            permissions,
+		SessionID:              current.FamilyID,
+		UserPermVersion:        userVersion,
+		RolePermVersion:        roleVersion,
+		PasswordChangeRequired: user.MustChangePassword,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 6. Return token dan user profile
 	return &model.LoginResponse{
-		Token: signed,
-		User:  *user,
+		Token:                  signed,
+		RefreshToken:           newToken,
+		ExpiresAt:              expiresAt,
+		User:                   *user,
+		PasswordChangeRequired: user.MustChangePassword,
 	}, nil
 }
 
 // GenerateToken - Generate JWT token with user ID, role ID, and permissions
 func (a *AuthService) GenerateToken(userID, roleID uuid.UUID, permissions []string) (string, error) {
-	claims := model.CustomClaims{
+	signed, _, err := a.signAccessToken(model.CustomClaims{
 		UserID:      userID,
 		RoleID:      roleID,
 		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.TokenTTL)),
-		},
+	})
+	return signed, err
+}
+
+// signAccessToken - Lengkapi claims (jti, iat, exp) lalu tanda tangani JWT access token.
+// SessionID boleh uuid.Nil untuk token tanpa sesi.
+func (a *AuthService) signAccessToken(claims model.CustomClaims) (string, time.Time, error) {
+	return a.signAccessTokenTTL(claims, a.TokenTTL)
+}
+
+// signAccessTokenTTL - signAccessToken dengan masa berlaku khusus (mis. token impersonation).
+// jti yang sudah diisi pemanggil dipertahankan.
+func (a *AuthService) signAccessTokenTTL(claims model.CustomClaims, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	tokenID := claims.ID
+	if tokenID == "" {
+		tokenID = uuid.New().String()
+	}
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID, // jti untuk revocation list
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	signed, err := a.Keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// createRefreshToken - Simpan refresh token baru, return nilai opaque dan ID-nya
+func (a *AuthService) createRefreshToken(userID, familyID uuid.UUID) (string, uuid.UUID, error) {
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return "", uuid.Nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(a.SecretKey)
+	token := &model.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(a.RefreshTokenTTL),
+	}
+
+	if err := a.TokenRepo.CreateRefreshToken(token); err != nil {
+		return "", uuid.Nil, err
+	}
+
+	return raw, token.ID, nil
+}
+
+// ChangePassword - Ganti password oleh user sendiri (termasuk ganti password wajib setelah dibuat admin).
+// Semua sesi lama dicabut, lalu sesi baru diterbitkan untuk perangkat yang dipakai.
+func (a *AuthService) ChangePassword(userID uuid.UUID, currentPassword, newPassword string, client model.ClientInfo) (*model.LoginResponse, error) {
+	// 1. Verifikasi password saat ini
+	user, err := a.Repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	source, err := a.Repo.GetAuthSource(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to get authentication source")
+	}
+	if source != AuthSourceLocal {
+		return nil, ErrExternalPassword
+	}
+	if CheckPassword(user.PasswordHash, currentPassword) != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	// 2. Terapkan password policy, daftar password bocor, dan riwayat password
+	if err := a.Passwords.CheckNewPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+
+	// 3. Simpan password baru
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	if err := a.Repo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		return nil, errors.New("failed to change password: " + err.Error())
+	}
+
+	if err := a.Passwords.RecordPassword(user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to record password history for user %s: %v", user.ID, err)
+	}
+
+	// 4. Cabut semua token lama, lalu terbitkan token baru tanpa batasan ganti password
+	if err := a.TokenRepo.RevokeAllUserTokens(user.ID); err != nil {
+		return nil, errors.New("failed to revoke sessions: " + err.Error())
+	}
+
+	user.PasswordHash = hashedPassword
+	user.MustChangePassword = false
+
+	return a.issueLoginTokens(user, client)
+}
+
+// Logout - Cabut access token dan sesi yang sedang dipakai, dan refresh token dari login yang sama jika dikirim
+func (a *AuthService) Logout(claims *model.CustomClaims, refreshToken string) error {
+	// Personal access token tidak punya sesi, dicabut lewat endpoint token
+	if claims.AccessTokenID != uuid.Nil {
+		return nil
+	}
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		err := a.TokenRepo.RevokeToken(&model.RevokedToken{
+			JTI:       claims.ID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		})
+		if err != nil {
+			return errors.New("failed to revoke token: " + err.Error())
+		}
+	}
+
+	if claims.SessionID != uuid.Nil {
+		if err := a.Sessions.RevokeSession(claims.UserID, claims.SessionID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	if refreshToken != "" {
+		current, err := a.TokenRepo.GetRefreshTokenByHash(hashToken(refreshToken))
+		if err == nil && current.UserID == claims.UserID {
+			if err := a.TokenRepo.RevokeRefreshTokenFamily(current.FamilyID); err != nil {
+				return errors.New("failed to revoke refresh token: " + err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsTokenRevoked - Cek revocation list untuk access token yang sudah tervalidasi
+func (a *AuthService) IsTokenRevoked(claims *model.CustomClaims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return a.TokenRepo.IsTokenRevoked(claims.ID, claims.UserID, issuedAt)
+}
+
+// CheckSession - Perbarui last-seen sesi token, ErrSessionRevoked jika sesinya sudah dicabut.
+// Token tanpa sesi (terbit sebelum ada sesi) tetap diterima sampai kadaluarsa.
+func (a *AuthService) CheckSession(claims *model.CustomClaims) error {
+	if claims.SessionID == uuid.Nil {
+		return nil
+	}
+	return a.Sessions.Touch(claims.SessionID, claims.UserID)
+}
+
+// StartTokenCleanup - Jalankan goroutine untuk hapus token yang sudah kadaluarsa secara berkala
+func (a *AuthService) StartTokenCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := a.TokenRepo.DeleteExpiredTokens(a.TokenTTL); err != nil {
+				log.Printf("Failed to clean up expired tokens: %v", err)
+			}
+			if err := a.Lockout.CleanupStale(); err != nil {
+				log.Printf("Failed to clean up login attempts: %v", err)
+			}
+		}
+	}()
+}
+
+// JWKS - Public key untuk verifikasi token oleh service lain
+func (a *AuthService) JWKS() model.JWKS {
+	return a.Keys.JWKS()
 }
 
 func (a *AuthService) ParseToken(tokenStr string) (*model.CustomClaims, error) {
-	t, err := jwt.ParseWithClaims(tokenStr, &model.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return a.SecretKey, nil
-	})
+	t, err := jwt.ParseWithClaims(tokenStr, &model.CustomClaims{}, a.Keys.Keyfunc, jwt.WithValidMethods(a.Keys.ValidMethods()))
 
 	if err != nil {
 		return nil, err
@@ -113,3 +631,18 @@ func (a *AuthService) ParseToken(tokenStr string) (*model.CustomClaims, error) {
 
 	return claims, nil
 }
+
+// generateOpaqueToken - Token acak 256-bit yang aman untuk URL
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken - SHA-256 hex dari token opaque untuk disimpan di database
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
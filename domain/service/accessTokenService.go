@@ -0,0 +1,222 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware/policy"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrInvalidAccessToken  = errors.New("invalid or expired access token")
+)
+
+const (
+	// accessTokenPrefix - Awalan token mentah, membedakan personal access token dari JWT di header Authorization
+	accessTokenPrefix = "uas_pat_"
+
+	// Panjang awal token yang disimpan apa adanya untuk ditampilkan ke user
+	accessTokenDisplayLength = len(accessTokenPrefix) + 4
+
+	defaultAccessTokenDays = 30
+	maxAccessTokenDays     = 365
+	maxAccessTokenLabel    = 100
+)
+
+// AccessTokenService - Personal access token untuk script dan integrasi.
+// Token membawa subset permission role pemilik; saat dipakai, permission efektif adalah irisan
+// permission token dengan permission role pemilik saat itu, jadi turun role langsung berlaku.
+type AccessTokenService struct {
+	Repo     *repository.AccessTokenRepository
+	AuthRepo *repository.AuthRepository
+}
+
+func NewAccessTokenService(repo *repository.AccessTokenRepository, authRepo *repository.AuthRepository) *AccessTokenService {
+	return &AccessTokenService{
+		Repo:     repo,
+		AuthRepo: authRepo,
+	}
+}
+
+// IsPersonalAccessToken - True jika bearer token adalah personal access token, bukan JWT
+func IsPersonalAccessToken(raw string) bool {
+	return strings.HasPrefix(raw, accessTokenPrefix)
+}
+
+// CreateToken - Buat token baru untuk user. Token mentah hanya dikembalikan sekali di response.
+func (s *AccessTokenService) CreateToken(userID uuid.UUID, req *model.CreateAccessTokenRequest) (*model.CreateAccessTokenResponse, error) {
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		return nil, errors.New("label is required")
+	}
+	if utf8.RuneCountInString(label) > maxAccessTokenLabel {
+		return nil, fmt.Errorf("label must be at most %d characters", maxAccessTokenLabel)
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAccessTokenDays
+	}
+	if days < 1 || days > maxAccessTokenDays {
+		return nil, fmt.Errorf("expires_in_days must be between 1 and %d", maxAccessTokenDays)
+	}
+
+	if len(req.Permissions) == 0 {
+		return nil, errors.New("at least one permission is required")
+	}
+
+	user, err := s.AuthRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	granted, err := s.AuthRepo.GetUserPermissions(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to get permissions: " + err.Error())
+	}
+
+	permissions, err := scopeAccessToken(req.Permissions, granted)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate access token: " + err.Error())
+	}
+	raw = accessTokenPrefix + raw
+
+	token := &model.PersonalAccessToken{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Label:       label,
+		TokenPrefix: raw[:accessTokenDisplayLength],
+		TokenHash:   hashToken(raw),
+		Permissions: permissions,
+		ExpiresAt:   time.Now().AddDate(0, 0, days),
+	}
+
+	if err := s.Repo.CreateAccessToken(token); err != nil {
+		return nil, errors.New("failed to create access token: " + err.Error())
+	}
+
+	return &model.CreateAccessTokenResponse{
+		Token:       raw,
+		AccessToken: token,
+	}, nil
+}
+
+// ListTokens - Semua token milik user, termasuk yang sudah dicabut (sampai dibersihkan setelah kadaluarsa)
+func (s *AccessTokenService) ListTokens(userID uuid.UUID) ([]model.PersonalAccessToken, error) {
+	tokens, err := s.Repo.GetUserAccessTokens(userID)
+	if err != nil {
+		return nil, errors.New("failed to get access tokens: " + err.Error())
+	}
+	return tokens, nil
+}
+
+// RevokeToken - Cabut token milik user sendiri
+func (s *AccessTokenService) RevokeToken(userID, tokenID uuid.UUID) error {
+	ok, err := s.Repo.RevokeUserAccessToken(tokenID, userID)
+	if err != nil {
+		return errors.New("failed to revoke access token: " + err.Error())
+	}
+	if !ok {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// AdminRevokeToken - Cabut token milik user mana pun (admin)
+func (s *AccessTokenService) AdminRevokeToken(tokenID uuid.UUID) error {
+	ok, err := s.Repo.RevokeAccessToken(tokenID)
+	if err != nil {
+		return errors.New("failed to revoke access token: " + err.Error())
+	}
+	if !ok {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// Authenticate - Validasi token mentah dari header Authorization dan bangun claims setara JWT.
+// Token ditolak jika tidak dikenal, dicabut, kadaluarsa, atau pemiliknya tidak aktif.
+func (s *AccessTokenService) Authenticate(raw string) (*model.CustomClaims, error) {
+	token, err := s.Repo.GetAccessTokenByHash(hashToken(raw))
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	user, err := s.AuthRepo.GetUserByID(token.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidAccessToken
+	}
+
+	current, err := s.AuthRepo.GetUserPermissions(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to get permissions: " + err.Error())
+	}
+
+	if err := s.Repo.TouchAccessToken(token.ID); err != nil {
+		log.Printf("Failed to update access token last use: %v", err)
+	}
+
+	return &model.CustomClaims{
+		UserID:                 user.ID,
+		RoleID:                 user.RoleID,
+		Permissions:            intersectPermissions(token.Permissions, current),
+		PasswordChangeRequired: user.MustChangePassword,
+		AccessTokenID:          token.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        token.ID.String(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(token.CreatedAt),
+			ExpiresAt: jwt.NewNumericDate(token.ExpiresAt),
+		},
+	}, nil
+}
+
+// scopeAccessToken - Pastikan permission yang diminta tercakup permission user (wildcard dan hierarki
+// ikut dihitung), hapus duplikat
+func scopeAccessToken(requested, granted []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	permissions := make([]string, 0, len(requested))
+	for _, perm := range requested {
+		perm = strings.TrimSpace(perm)
+		if seen[perm] {
+			continue
+		}
+		if !policy.PermissionGranted(granted, perm) {
+			return nil, fmt.Errorf("permission %q is not granted to your role", perm)
+		}
+		seen[perm] = true
+		permissions = append(permissions, perm)
+	}
+
+	return permissions, nil
+}
+
+// intersectPermissions - Permission token yang masih tercakup permission role pemilik
+func intersectPermissions(tokenPermissions, current []string) []string {
+	permissions := []string{}
+	for _, perm := range tokenPermissions {
+		if policy.PermissionGranted(current, perm) {
+			permissions = append(permissions, perm)
+		}
+	}
+
+	return permissions
+}
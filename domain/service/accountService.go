@@ -0,0 +1,214 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidEmail            = errors.New("invalid email address")
+	ErrEmailTaken              = errors.New("email already exists")
+	ErrEmailUnchanged          = errors.New("new email is the same as the current email")
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email confirmation token")
+)
+
+// Sesuai kolom users.full_name dan users.email VARCHAR(100)
+const (
+	maxFullNameLength = 100
+	maxEmailLength    = 100
+)
+
+// AccountService - Self-service akun untuk user yang sedang login (profil dan ganti email).
+// Hanya field yang aman yang bisa diubah; role, status aktif, dan username tetap lewat admin.
+// User dengan backend autentikasi eksternal (LDAP) tidak bisa mengubah nama dan email karena
+// keduanya disalin ulang dari direktori setiap login.
+type AccountService struct {
+	UserRepo        *repository.UserRepository
+	AuthRepo        *repository.AuthRepository
+	EmailChangeRepo *repository.EmailChangeRepository
+	Mail            *MailService
+	EmailChangeTTL  time.Duration
+	ConfirmURL      string // URL halaman konfirmasi email di frontend, token ditambahkan sebagai query ?token=
+}
+
+func NewAccountService(userRepo *repository.UserRepository, authRepo *repository.AuthRepository, emailChangeRepo *repository.EmailChangeRepository, mail *MailService, emailChangeTTL time.Duration, confirmURL string) *AccountService {
+	return &AccountService{
+		UserRepo:        userRepo,
+		AuthRepo:        authRepo,
+		EmailChangeRepo: emailChangeRepo,
+		Mail:            mail,
+		EmailChangeTTL:  emailChangeTTL,
+		ConfirmURL:      confirmURL,
+	}
+}
+
+// UpdateProfile - Update profil sendiri, saat ini hanya full name
+func (s *AccountService) UpdateProfile(userID uuid.UUID, req *model.UpdateProfileRequest) (*model.Users, error) {
+	fullName := strings.TrimSpace(req.FullName)
+	if fullName == "" {
+		return nil, errors.New("full name is required")
+	}
+	if utf8.RuneCountInString(fullName) > maxFullNameLength {
+		return nil, fmt.Errorf("full name must be at most %d characters", maxFullNameLength)
+	}
+
+	if err := s.ensureLocalProfile(userID, "full name"); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	user.FullName = fullName
+	if err := s.UserRepo.UpdateUser(user); err != nil {
+		return nil, errors.New("failed to update profile: " + err.Error())
+	}
+
+	return user, nil
+}
+
+// RequestEmailChange - Kirim link konfirmasi ke email baru. Email belum berubah sampai link dikonfirmasi.
+func (s *AccountService) RequestEmailChange(userID uuid.UUID, req *model.EmailChangeRequest) error {
+	// 1. Verifikasi password saat ini (hanya untuk user lokal, password user LDAP ada di direktori)
+	if err := s.ensureLocalProfile(userID, "email"); err != nil {
+		return err
+	}
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if CheckPassword(user.PasswordHash, req.CurrentPassword) != nil {
+		return ErrInvalidPassword
+	}
+
+	// 2. Validasi email baru
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if !isValidEmail(newEmail) {
+		return ErrInvalidEmail
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrEmailUnchanged
+	}
+
+	existingUser, _ := s.UserRepo.GetUserByEmail(newEmail)
+	if existingUser != nil {
+		return ErrEmailTaken
+	}
+
+	// 3. Simpan token (hanya hash) lalu kirim link ke email baru
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return errors.New("failed to generate confirmation token")
+	}
+
+	token := &model.EmailChangeToken{
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(s.EmailChangeTTL),
+	}
+
+	if err := s.EmailChangeRepo.CreateEmailChange(token); err != nil {
+		return errors.New("failed to save email change request: " + err.Error())
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nKami menerima permintaan untuk mengganti email akun Anda ke alamat ini.\n"+
+			"Buka link berikut untuk konfirmasi (berlaku %d jam, hanya bisa dipakai sekali):\n\n%s?token=%s\n\n"+
+			"Jika Anda tidak meminta perubahan ini, abaikan email ini.\n",
+		user.FullName, int(s.EmailChangeTTL.Hours()), s.ConfirmURL, raw,
+	)
+	if err := s.Mail.Enqueue(newEmail, "Konfirmasi Email Baru", body); err != nil {
+		return err
+	}
+
+	// 4. Beri tahu email lama, supaya pemilik akun tahu jika bukan dia yang meminta
+	notice := fmt.Sprintf(
+		"Halo %s,\n\nAda permintaan untuk mengganti email akun Anda ke %s.\n"+
+			"Email akan berubah setelah alamat baru dikonfirmasi.\n\n"+
+			"Jika bukan Anda yang meminta, segera ganti password Anda.\n",
+		user.FullName, newEmail,
+	)
+	if err := s.Mail.Enqueue(user.Email, "Permintaan Ganti Email", notice); err != nil {
+		log.Printf("Failed to enqueue email change notice for user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange - Ganti email dengan token dari link konfirmasi milik user yang sedang login
+func (s *AccountService) ConfirmEmailChange(userID uuid.UUID, rawToken string) (*model.Users, error) {
+	// 1. Cari token berdasarkan hash
+	token, err := s.EmailChangeRepo.GetEmailChangeByHash(hashToken(rawToken))
+	if err != nil {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	if token.UserID != userID || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	// 2. Backend autentikasi bisa sudah diganti ke LDAP sejak permintaan dibuat,
+	// dan email bisa sudah dipakai user lain
+	if err := s.ensureLocalProfile(userID, "email"); err != nil {
+		return nil, err
+	}
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	existingUser, _ := s.UserRepo.GetUserByEmail(token.NewEmail)
+	if existingUser != nil && existingUser.ID != userID {
+		return nil, ErrEmailTaken
+	}
+
+	// 3. Pakai token (sekali pakai) lalu simpan email baru
+	ok, err := s.EmailChangeRepo.MarkEmailChangeUsed(token.ID)
+	if err != nil {
+		return nil, errors.New("failed to confirm email change: " + err.Error())
+	}
+	if !ok {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	user.Email = token.NewEmail
+	if err := s.UserRepo.UpdateUser(user); err != nil {
+		return nil, errors.New("failed to update email: " + err.Error())
+	}
+
+	return user, nil
+}
+
+// ensureLocalProfile - Tolak perubahan field profil user dengan backend autentikasi eksternal,
+// karena field tersebut ditimpa dari direktori setiap login (syncDirectoryProfile)
+func (s *AccountService) ensureLocalProfile(userID uuid.UUID, field string) error {
+	source, err := s.AuthRepo.GetAuthSource(userID)
+	if err != nil {
+		return errors.New("failed to get authentication source")
+	}
+	if source != AuthSourceLocal {
+		return fmt.Errorf("%w: %s is synced from the directory at login", ErrExternalPassword, field)
+	}
+	return nil
+}
+
+// isValidEmail - Alamat email tunggal tanpa nama tampilan, mis. "user@example.com"
+func isValidEmail(email string) bool {
+	if email == "" || len(email) > maxEmailLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
@@ -2,21 +2,55 @@ package service
 
 import (
 	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/workflow"
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	ErrAchievementNotFound = errors.New("achievement reference not found")
+	ErrNotLecturer         = errors.New("user is not a lecturer")
+	ErrNotVerifier         = errors.New("unauthorized: you are not the advisor of this student")
+)
+
+// AchievementService - Workflow = aturan status prestasi, default workflow.Achievement. RBAC = sumber role user
+// yang dicocokkan dengan role transisi workflow.
 type AchievementService struct {
-	Repo *repository.AchievementRepository
+	Repo        *repository.AchievementRepository
+	Delegations *repository.DelegationRepository
+	RBAC        *RBACService
+	Workflow    *workflow.StateMachine
+}
+
+func NewAchievementService(repo *repository.AchievementRepository, delegationRepo *repository.DelegationRepository, rbac *RBACService) *AchievementService {
+	return &AchievementService{Repo: repo, Delegations: delegationRepo, RBAC: rbac, Workflow: workflow.Achievement}
 }
 
-func NewAchievementService(repo *repository.AchievementRepository) *AchievementService {
-	return &AchievementService{Repo: repo}
+// fire - Jalankan aksi workflow dengan role RBAC user. Mengembalikan status tujuan dan role yang cocok
+// dengan transisi (untuk actor_role riwayat status).
+func (s *AchievementService) fire(action string, userID uuid.UUID, reference *model.AchievementReference) (string, string, error) {
+	state, err := s.RBAC.GetPermissionState(userID)
+	if err != nil {
+		return "", "", errors.New("failed to get user roles: " + err.Error())
+	}
+
+	role, err := s.Workflow.RoleFor(action, state.RoleNames)
+	if err != nil {
+		return "", "", err
+	}
+
+	newStatus, err := s.Workflow.Fire(action, role, reference)
+	if err != nil {
+		return "", "", err
+	}
+	return newStatus, role, nil
 }
 
 // SubmitAchievementRequest - DTO untuk submit prestasi
@@ -39,11 +73,11 @@ type AttachmentRequest struct {
 
 // SubmitAchievementResponse - DTO untuk response
 type SubmitAchievementResponse struct {
-	ReferenceID        uuid.UUID              `json:"reference_id"`
-	MongoAchievementID string                 `json:"mongo_achievement_id"`
-	Status             string                 `json:"status"`
-	Achievement        *model.Achievement     `json:"achievement"`
-	CreatedAt          time.Time              `json:"created_at"`
+	ReferenceID        uuid.UUID          `json:"reference_id"`
+	MongoAchievementID string             `json:"mongo_achievement_id"`
+	Status             string             `json:"status"`
+	Achievement        *model.Achievement `json:"achievement"`
+	CreatedAt          time.Time          `json:"created_at"`
 }
 
 // SubmitAchievement - Flow FR-003: Submit Prestasi
@@ -98,10 +132,10 @@ func (s *AchievementService) SubmitAchievement(ctx context.Context, userID uuid.
 		ID:                 referenceID,
 		StudentID:          student.ID,
 		MongoAchievementID: mongoID.Hex(),
-		Status:             "draft", // 4. Status awal: 'draft'
+		Status:             s.Workflow.Initial, // 4. Status awal: 'draft'
 	}
 
-	err = s.Repo.CreateAchievementReference(reference)
+	err = s.Repo.CreateAchievementReference(reference, model.StatusActor{UserID: userID, Role: workflow.RoleStudent})
 	if err != nil {
 		return nil, errors.New("failed to save achievement reference to PostgreSQL: " + err.Error())
 	}
@@ -113,12 +147,96 @@ func (s *AchievementService) SubmitAchievement(ctx context.Context, userID uuid.
 	return &SubmitAchievementResponse{
 		ReferenceID:        referenceID,
 		MongoAchievementID: mongoID.Hex(),
-		Status:             "draft",
+		Status:             reference.Status,
 		Achievement:        achievement,
 		CreatedAt:          achievement.CreatedAt,
 	}, nil
 }
 
+// UpdateAchievementResponse - DTO untuk response update prestasi
+type UpdateAchievementResponse struct {
+	ReferenceID   uuid.UUID          `json:"reference_id"`
+	Status        string             `json:"status"`
+	RejectionNote *string            `json:"rejection_note,omitempty"` // Catatan penolakan terakhir, untuk prestasi yang sedang diperbaiki
+	Achievement   *model.Achievement `json:"achievement"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// UpdateAchievement - Ubah prestasi milik mahasiswa yang masih 'draft' atau 'rejected'. Dokumen MongoDB ditulis ulang
+// dari request (attachments lama dipertahankan jika request tidak mengirim attachments), status tidak berubah:
+// prestasi yang ditolak diajukan ulang lewat SubmitForVerification.
+func (s *AchievementService) UpdateAchievement(ctx context.Context, userID uuid.UUID, referenceID uuid.UUID, req *SubmitAchievementRequest) (*UpdateAchievementResponse, error) {
+	// Validasi: User harus mahasiswa
+	student, err := s.Repo.GetStudentByUserID(userID)
+	if err != nil {
+		return nil, errors.New("user is not a student")
+	}
+
+	reference, err := s.Repo.GetAchievementReferenceByID(referenceID)
+	if err != nil {
+		return nil, ErrAchievementNotFound
+	}
+
+	// Validasi: Reference harus milik mahasiswa ini
+	if err := policy.AchievementOwner.Authorize(studentSubject(userID, student), achievementResource(reference, nil)); err != nil {
+		return nil, errors.New("unauthorized: achievement does not belong to you")
+	}
+
+	// Precondition: Prestasi berstatus 'draft' atau 'rejected'
+	if _, _, err := s.fire(workflow.ActionEdit, userID, reference); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateAchievementRequest(req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetAchievementByID(ctx, reference.MongoAchievementID)
+	if err != nil {
+		return nil, errors.New("achievement not found in MongoDB")
+	}
+
+	attachments := existing.Attachments
+	if req.Attachments != nil {
+		attachments = make([]model.Attachment, len(req.Attachments))
+		for i, att := range req.Attachments {
+			attachments[i] = model.Attachment{
+				FileName:   att.FileName,
+				FileURL:    att.FileURL,
+				FileType:   att.FileType,
+				UploadedAt: time.Now(),
+			}
+		}
+	}
+
+	achievement := &model.Achievement{
+		StudentID:       existing.StudentID,
+		AchievementType: req.AchievementType,
+		Title:           req.Title,
+		Description:     req.Description,
+		Details:         s.parseDetails(req.AchievementType, req.Details),
+		CustomFields:    req.CustomFields,
+		Attachments:     attachments,
+		Tags:            req.Tags,
+		Points:          req.Points,
+		CreatedAt:       existing.CreatedAt,
+	}
+
+	// Status dicek ulang saat menulis: edit gagal jika prestasi sudah diajukan/dihapus setelah dibaca
+	if err := s.Repo.UpdateAchievementIfStatus(ctx, referenceID, reference.Status, existing.ID, achievement); err != nil {
+		return nil, fmt.Errorf("failed to update achievement: %w", err)
+	}
+	achievement.ID = existing.ID
+
+	return &UpdateAchievementResponse{
+		ReferenceID:   referenceID,
+		Status:        reference.Status,
+		RejectionNote: reference.RejectionNote,
+		Achievement:   achievement,
+		UpdatedAt:     achievement.UpdatedAt,
+	}, nil
+}
+
 // validateAchievementRequest - Validasi input
 func (s *AchievementService) validateAchievementRequest(req *SubmitAchievementRequest) error {
 	if req.Title == "" {
@@ -178,7 +296,7 @@ func (s *AchievementService) parseDetails(achievementType string, detailsMap map
 		details.PublicationTitle = getStringPtr("publicationTitle")
 		details.Publisher = getStringPtr("publisher")
 		details.ISSN = getStringPtr("issn")
-		
+
 		// Parse authors array
 		if authors, ok := detailsMap["authors"].([]interface{}); ok {
 			authorStrs := make([]string, 0, len(authors))
@@ -193,7 +311,7 @@ func (s *AchievementService) parseDetails(achievementType string, detailsMap map
 	case "organization":
 		details.OrganizationName = getStringPtr("organizationName")
 		details.Position = getStringPtr("position")
-		
+
 		// Parse period
 		if periodMap, ok := detailsMap["period"].(map[string]interface{}); ok {
 			period := &model.Period{}
@@ -214,7 +332,7 @@ func (s *AchievementService) parseDetails(achievementType string, detailsMap map
 		details.CertificationName = getStringPtr("certificationName")
 		details.IssuedBy = getStringPtr("issuedBy")
 		details.CertificationNumber = getStringPtr("certificationNumber")
-		
+
 		// Parse validUntil
 		if validUntil, ok := detailsMap["validUntil"].(string); ok {
 			if t, err := time.Parse(time.RFC3339, validUntil); err == nil {
@@ -265,13 +383,15 @@ type SubmitForVerificationRequest struct {
 
 // SubmitForVerificationResponse - DTO untuk response
 type SubmitForVerificationResponse struct {
-	ReferenceID uuid.UUID  `json:"reference_id"`
-	Status      string     `json:"status"`
-	SubmittedAt time.Time  `json:"submitted_at"`
-	Message     string     `json:"message"`
+	ReferenceID           uuid.UUID `json:"reference_id"`
+	Status                string    `json:"status"`
+	SubmittedAt           time.Time `json:"submitted_at"`
+	PreviousRejectionNote *string   `json:"previous_rejection_note,omitempty"` // Diisi saat prestasi yang ditolak diajukan ulang
+	Message               string    `json:"message"`
 }
 
-// SubmitForVerification - Flow FR-004: Submit untuk Verifikasi
+// SubmitForVerification - Flow FR-004: Submit untuk Verifikasi. Prestasi yang ditolak boleh diajukan ulang
+// setelah diperbaiki, catatan penolakan sebelumnya tetap disimpan agar terlihat dosen wali.
 func (s *AchievementService) SubmitForVerification(ctx context.Context, userID uuid.UUID, referenceID uuid.UUID, notificationService *NotificationService) (*SubmitForVerificationResponse, error) {
 	// Validasi: User harus mahasiswa
 	student, err := s.Repo.GetStudentByUserID(userID)
@@ -282,18 +402,20 @@ func (s *AchievementService) SubmitForVerification(ctx context.Context, userID u
 	// 1. Get achievement reference
 	reference, err := s.Repo.GetAchievementReferenceByID(referenceID)
 	if err != nil {
-		return nil, errors.New("achievement reference not found")
+		return nil, ErrAchievementNotFound
 	}
 
 	// Validasi: Reference harus milik mahasiswa ini
-	if reference.StudentID != student.ID {
+	if err := policy.AchievementOwner.Authorize(studentSubject(userID, student), achievementResource(reference, nil)); err != nil {
 		return nil, errors.New("unauthorized: achievement does not belong to you")
 	}
 
-	// Precondition: Prestasi berstatus 'draft'
-	if reference.Status != "draft" {
-		return nil, errors.New("achievement must be in 'draft' status to submit for verification")
+	// Precondition: Prestasi berstatus 'draft' atau 'rejected' (pengajuan ulang)
+	newStatus, role, err := s.fire(workflow.ActionSubmit, userID, reference)
+	if err != nil {
+		return nil, err
 	}
+	resubmitted := reference.Status == workflow.StatusRejected
 
 	// Get achievement detail dari MongoDB
 	achievement, err := s.GetAchievementByID(ctx, reference.MongoAchievementID)
@@ -301,15 +423,15 @@ func (s *AchievementService) SubmitForVerification(ctx context.Context, userID u
 		return nil, errors.New("achievement not found in MongoDB")
 	}
 
-	// 2. Update status menjadi 'submitted'
+	// 2. Update status menjadi 'submitted' (catatan penolakan lama dipertahankan)
 	now := time.Now()
-	err = s.Repo.UpdateAchievementReferenceStatus(referenceID, "submitted", nil, nil)
+	err = s.Repo.UpdateAchievementReferenceStatus(referenceID, reference.Status, newStatus, nil, reference.RejectionNote, model.StatusActor{UserID: userID, Role: role}, nil)
 	if err != nil {
-		return nil, errors.New("failed to update status: " + err.Error())
+		return nil, fmt.Errorf("failed to update status: %w", err)
 	}
 
 	// Update submitted_at
-	reference.Status = "submitted"
+	reference.Status = newStatus
 	reference.SubmittedAt = &now
 
 	// 3. Create notification untuk dosen wali
@@ -328,18 +450,83 @@ func (s *AchievementService) SubmitForVerification(ctx context.Context, userID u
 					referenceID,
 				)
 			}
+
+			// Dosen pengganti yang sedang menerima delegasi untuk mahasiswa ini ikut diberi tahu
+			delegations, err := s.Delegations.GetActiveDelegationsForDelegator(student.AdvisorID, now)
+			if err == nil {
+				for _, delegation := range delegations {
+					if !delegation.Covers(student.ID, now) {
+						continue
+					}
+					delegate, err := s.Repo.GetLecturerByID(delegation.DelegateID)
+					if err != nil {
+						continue
+					}
+					_ = notificationService.CreateAchievementSubmittedNotification(
+						delegate.UserID,
+						studentUser.FullName,
+						achievement.Title,
+						referenceID,
+					)
+				}
+			}
 		}
 	}
 
 	// 4. Return updated status
+	message := "Achievement submitted for verification successfully"
+	if resubmitted {
+		message = "Achievement resubmitted for verification successfully"
+	}
+
 	return &SubmitForVerificationResponse{
-		ReferenceID: referenceID,
-		Status:      "submitted",
-		SubmittedAt: now,
-		Message:     "Achievement submitted for verification successfully",
+		ReferenceID:           referenceID,
+		Status:                newStatus,
+		SubmittedAt:           now,
+		PreviousRejectionNote: reference.RejectionNote,
+		Message:               message,
 	}, nil
 }
 
+// GetAchievementHistory - Riwayat status prestasi dari achievement_status_history. Hak akses
+// (pemilik, dosen wali, dosen pengganti, dosen prodi, admin) dicek policy student.record di route.
+func (s *AchievementService) GetAchievementHistory(referenceID uuid.UUID) ([]model.AchievementStatusHistory, error) {
+	if _, err := s.Repo.GetAchievementReferenceByID(referenceID); err != nil {
+		if errors.Is(err, repository.ErrAchievementReferenceNotFound) {
+			return nil, ErrAchievementNotFound
+		}
+		return nil, errors.New("failed to get achievement reference: " + err.Error())
+	}
+
+	history, err := s.Repo.GetAchievementStatusHistory(referenceID)
+	if err != nil {
+		return nil, errors.New("failed to get achievement history: " + err.Error())
+	}
+
+	return history, nil
+}
+
+// studentSubject - Subject policy untuk mahasiswa yang sudah dimuat
+func studentSubject(userID uuid.UUID, student *model.Student) *policy.Subject {
+	return &policy.Subject{UserID: userID, Student: student}
+}
+
+// lecturerSubject - Subject policy untuk dosen yang sudah dimuat
+func lecturerSubject(userID uuid.UUID, lecturer *model.Lecturer) *policy.Subject {
+	return &policy.Subject{UserID: userID, Lecturer: lecturer}
+}
+
+// achievementResource - Resource policy untuk achievement reference. owner boleh nil jika
+// policy hanya butuh ID pemilik (mis. AchievementOwner)
+func achievementResource(reference *model.AchievementReference, owner *model.Student) *policy.Resource {
+	return &policy.Resource{
+		Type:    policy.ResourceAchievement,
+		ID:      reference.ID,
+		OwnerID: reference.StudentID,
+		Owner:   owner,
+	}
+}
+
 // DeleteAchievementRequest - DTO untuk delete achievement
 type DeleteAchievementRequest struct {
 	ReferenceID uuid.UUID `json:"reference_id"`
@@ -363,22 +550,18 @@ func (s *AchievementService) DeleteAchievement(ctx context.Context, userID uuid.
 	// Get achievement reference
 	reference, err := s.Repo.GetAchievementReferenceByID(referenceID)
 	if err != nil {
-		return nil, errors.New("achievement reference not found")
+		return nil, ErrAchievementNotFound
 	}
 
 	// Validasi: Reference harus milik mahasiswa ini
-	if reference.StudentID != student.ID {
+	if err := policy.AchievementOwner.Authorize(studentSubject(userID, student), achievementResource(reference, nil)); err != nil {
 		return nil, errors.New("unauthorized: achievement does not belong to you")
 	}
 
-	// Precondition: Prestasi berstatus 'draft'
-	if reference.Status != "draft" {
-		return nil, errors.New("only draft achievements can be deleted")
-	}
-
-	// Validasi: Belum dihapus sebelumnya
-	if reference.IsDeleted {
-		return nil, errors.New("achievement already deleted")
+	// Precondition: Prestasi berstatus 'draft' dan belum dihapus sebelumnya
+	_, role, err := s.fire(workflow.ActionDelete, userID, reference)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse MongoDB ObjectID
@@ -387,19 +570,13 @@ func (s *AchievementService) DeleteAchievement(ctx context.Context, userID uuid.
 		return nil, errors.New("invalid mongo achievement ID")
 	}
 
-	// 1. Soft delete data di MongoDB
-	err = s.Repo.SoftDeleteAchievement(ctx, mongoID)
+	// 1. Soft delete data di MongoDB dan reference di PostgreSQL, selama status reference masih sama
+	err = s.Repo.SoftDeleteAchievementReference(ctx, referenceID, reference.Status, mongoID, model.StatusActor{UserID: userID, Role: role})
 	if err != nil {
-		return nil, errors.New("failed to delete achievement in MongoDB: " + err.Error())
+		return nil, fmt.Errorf("failed to delete achievement: %w", err)
 	}
 
-	// 2. Update reference di PostgreSQL (soft delete)
-	err = s.Repo.SoftDeleteAchievementReference(referenceID)
-	if err != nil {
-		return nil, errors.New("failed to delete achievement reference in PostgreSQL: " + err.Error())
-	}
-
-	// 3. Return success message
+	// 2. Return success message
 	now := time.Now()
 	return &DeleteAchievementResponse{
 		ReferenceID: referenceID,
@@ -410,9 +587,10 @@ func (s *AchievementService) DeleteAchievement(ctx context.Context, userID uuid.
 
 // AdvisedStudentAchievement - DTO untuk prestasi mahasiswa bimbingan
 type AdvisedStudentAchievement struct {
-	Reference   model.AchievementReference `json:"reference"`
-	Achievement *model.Achievement         `json:"achievement"`
-	Student     *StudentInfo               `json:"student"`
+	Reference    model.AchievementReference `json:"reference"`
+	Achievement  *model.Achievement         `json:"achievement"`
+	Student      *StudentInfo               `json:"student"`
+	DelegationID *uuid.UUID                 `json:"delegation_id,omitempty"` // Diisi jika mahasiswa bimbingan dosen lain yang didelegasikan
 }
 
 // StudentInfo - DTO untuk info mahasiswa
@@ -439,12 +617,14 @@ type VerifyAchievementRequest struct {
 
 // VerifyAchievementResponse - DTO untuk response
 type VerifyAchievementResponse struct {
-	ReferenceID uuid.UUID  `json:"reference_id"`
-	Status      string     `json:"status"`
-	VerifiedBy  uuid.UUID  `json:"verified_by"`
-	VerifiedAt  time.Time  `json:"verified_at"`
-	Note        *string    `json:"note,omitempty"`
-	Message     string     `json:"message"`
+	ReferenceID  uuid.UUID  `json:"reference_id"`
+	Status       string     `json:"status"`
+	VerifiedBy   uuid.UUID  `json:"verified_by"`
+	VerifiedAt   time.Time  `json:"verified_at"`
+	Note         *string    `json:"note,omitempty"`
+	DelegationID *uuid.UUID `json:"delegation_id,omitempty"` // Diisi jika diputuskan dosen pengganti
+	OnBehalfOf   *uuid.UUID `json:"on_behalf_of,omitempty"`  // Dosen wali yang mendelegasikan
+	Message      string     `json:"message"`
 }
 
 // VerifyAchievement - Flow FR-007: Verify Prestasi
@@ -452,18 +632,23 @@ func (s *AchievementService) VerifyAchievement(ctx context.Context, userID uuid.
 	// Validasi: User harus dosen/lecturer
 	lecturer, err := s.Repo.GetLecturerByUserID(userID)
 	if err != nil {
-		return nil, errors.New("user is not a lecturer")
+		return nil, ErrNotLecturer
 	}
 
 	// 1. Get achievement reference
 	reference, err := s.Repo.GetAchievementReferenceByID(referenceID)
 	if err != nil {
-		return nil, errors.New("achievement reference not found")
+		return nil, ErrAchievementNotFound
 	}
 
-	// Precondition: Prestasi berstatus 'submitted'
-	if reference.Status != "submitted" {
-		return nil, errors.New("achievement must be in 'submitted' status to verify")
+	// Precondition: Prestasi berstatus 'submitted'. Status tujuan ('verified' atau 'rejected') dari workflow
+	action := workflow.ActionVerify
+	if !approved {
+		action = workflow.ActionReject
+	}
+	newStatus, role, err := s.fire(action, userID, reference)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get student info untuk validasi advisor
@@ -472,9 +657,24 @@ func (s *AchievementService) VerifyAchievement(ctx context.Context, userID uuid.
 		return nil, errors.New("student not found")
 	}
 
-	// Validasi: Hanya dosen wali yang bisa verify
-	if student.AdvisorID != lecturer.ID {
-		return nil, errors.New("unauthorized: you are not the advisor of this student")
+	// Validasi: Hanya dosen wali, atau dosen pengganti selama delegasinya berlaku, yang bisa verify
+	now := time.Now()
+	delegations, err := s.Delegations.GetActiveDelegationsForDelegate(lecturer.ID, now)
+	if err != nil {
+		return nil, errors.New("failed to get delegations: " + err.Error())
+	}
+	subject := lecturerSubject(userID, lecturer)
+	subject.Delegations = delegations
+	resource := achievementResource(reference, student)
+
+	decision := policy.AchievementVerifier.Evaluate(subject, resource)
+	if !decision.Allowed {
+		return nil, ErrNotVerifier
+	}
+
+	var delegation *model.VerificationDelegation
+	if decision.Matched == policy.IsDelegate.Name {
+		delegation = policy.ActiveDelegation(subject, resource, now)
 	}
 
 	// Get achievement detail dari MongoDB
@@ -484,24 +684,27 @@ func (s *AchievementService) VerifyAchievement(ctx context.Context, userID uuid.
 	}
 
 	// 2. Dosen approve/reject prestasi
-	var newStatus string
 	var rejectionNote *string
-	if approved {
-		newStatus = "verified"
-		rejectionNote = nil
-	} else {
-		newStatus = "rejected"
-		if note != "" {
-			rejectionNote = &note
-		}
+	if !approved && note != "" {
+		rejectionNote = &note
 	}
 
 	// 3. Update status menjadi 'verified' atau 'rejected'
-	// 4. Set verified_by dan verified_at
-	now := time.Now()
-	err = s.Repo.UpdateAchievementReferenceStatus(referenceID, newStatus, &lecturer.ID, rejectionNote)
+	// 4. Set verified_by dan verified_at, catatan dosen (juga saat approve) masuk riwayat status
+	// 5. Delegasi yang dipakai (jika diputuskan dosen pengganti) dicatat di transaksi yang sama
+	var historyNote *string
+	if note != "" {
+		historyNote = &note
+	}
+	var delegationID, onBehalfOf *uuid.UUID
+	if delegation != nil {
+		delegationID = &delegation.ID
+		onBehalfOf = &delegation.DelegatorID
+	}
+	actor := model.StatusActor{UserID: userID, Role: role, DelegationID: delegationID}
+	err = s.Repo.UpdateAchievementReferenceStatus(referenceID, reference.Status, newStatus, &lecturer.ID, rejectionNote, actor, historyNote)
 	if err != nil {
-		return nil, errors.New("failed to update status: " + err.Error())
+		return nil, fmt.Errorf("failed to update status: %w", err)
 	}
 
 	// Create notification untuk mahasiswa
@@ -537,12 +740,14 @@ func (s *AchievementService) VerifyAchievement(ctx context.Context, userID uuid.
 	}
 
 	return &VerifyAchievementResponse{
-		ReferenceID: referenceID,
-		Status:      newStatus,
-		VerifiedBy:  lecturer.ID,
-		VerifiedAt:  now,
-		Note:        rejectionNote,
-		Message:     message,
+		ReferenceID:  referenceID,
+		Status:       newStatus,
+		VerifiedBy:   lecturer.ID,
+		VerifiedAt:   now,
+		Note:         rejectionNote,
+		DelegationID: delegationID,
+		OnBehalfOf:   onBehalfOf,
+		Message:      message,
 	}, nil
 }
 
@@ -551,7 +756,7 @@ func (s *AchievementService) ViewAdvisedStudentsAchievements(ctx context.Context
 	// Validasi: User harus dosen/lecturer
 	lecturer, err := s.Repo.GetLecturerByUserID(userID)
 	if err != nil {
-		return nil, errors.New("user is not a lecturer")
+		return nil, ErrNotLecturer
 	}
 
 	// 1. Get list student IDs dari tabel students where advisor_id
@@ -560,6 +765,13 @@ func (s *AchievementService) ViewAdvisedStudentsAchievements(ctx context.Context
 		return nil, errors.New("failed to get advised students: " + err.Error())
 	}
 
+	// Ditambah mahasiswa bimbingan dosen lain yang sedang didelegasikan ke dosen ini
+	delegatedStudents, delegationOf, err := s.delegatedStudents(lecturer.ID, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to get delegated students: " + err.Error())
+	}
+	students = append(students, delegatedStudents...)
+
 	// Jika tidak ada mahasiswa bimbingan
 	if len(students) == 0 {
 		return &ViewAdvisedStudentsAchievementsResponse{
@@ -658,10 +870,16 @@ func (s *AchievementService) ViewAdvisedStudentsAchievements(ctx context.Context
 			}
 		}
 
+		var delegationID *uuid.UUID
+		if id, ok := delegationOf[ref.StudentID]; ok {
+			delegationID = &id
+		}
+
 		result = append(result, AdvisedStudentAchievement{
-			Reference:   ref,
-			Achievement: achievement,
-			Student:     studentInfo,
+			Reference:    ref,
+			Achievement:  achievement,
+			Student:      studentInfo,
+			DelegationID: delegationID,
 		})
 	}
 
@@ -676,3 +894,30 @@ func (s *AchievementService) ViewAdvisedStudentsAchievements(ctx context.Context
 		},
 	}, nil
 }
+
+// delegatedStudents - Mahasiswa bimbingan dosen lain yang hak verifikasinya sedang didelegasikan
+// ke dosen ini, beserta ID delegasinya per mahasiswa
+func (s *AchievementService) delegatedStudents(lecturerID uuid.UUID, at time.Time) ([]model.Student, map[uuid.UUID]uuid.UUID, error) {
+	delegations, err := s.Delegations.GetActiveDelegationsForDelegate(lecturerID, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	students := []model.Student{}
+	delegationOf := make(map[uuid.UUID]uuid.UUID)
+	for _, delegation := range delegations {
+		advisees, err := s.Repo.GetStudentsByAdvisorID(delegation.DelegatorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, student := range advisees {
+			if _, seen := delegationOf[student.ID]; seen || !delegation.Covers(student.ID, at) {
+				continue
+			}
+			delegationOf[student.ID] = delegation.ID
+			students = append(students, student)
+		}
+	}
+
+	return students, delegationOf, nil
+}
@@ -18,8 +18,6 @@ func NewAdminAchievementService(repo *repository.AchievementRepository) *AdminAc
 	return &AdminAchievementService{Repo: repo}
 }
 
-
-
 // AdminAchievementResponse - DTO untuk response
 type AdminAchievementResponse struct {
 	Reference   model.AchievementReference `json:"reference"`
@@ -59,15 +57,19 @@ type ViewAllAchievementsRequest struct {
 
 // ViewAllAchievementsResponse - DTO untuk response
 type ViewAllAchievementsResponse struct {
-	Achievements []AdminAchievementResponse   `json:"achievements"`
-	Pagination   model.PaginationResponse     `json:"pagination"`
-	Summary      *model.AchievementSummary    `json:"summary"`
+	Achievements []AdminAchievementResponse `json:"achievements"`
+	Pagination   model.PaginationResponse   `json:"pagination"`
+	Summary      *model.AchievementSummary  `json:"summary"`
 }
 
+// ViewAllAchievements - Flow FR-010: View All Achievements. Admin ter-scope hanya melihat prestasi
+// mahasiswa di program studinya (berlaku juga untuk summary).
+func (s *AdminAchievementService) ViewAllAchievements(ctx context.Context, scope *model.ProgramStudyScope, req *ViewAllAchievementsRequest) (*ViewAllAchievementsResponse, error) {
+	if req.Filter == nil {
+		req.Filter = &model.AdminAchievementFilter{}
+	}
+	req.Filter.ScopeProgramStudies = scope.Lowered()
 
-
-// ViewAllAchievements - Flow FR-010: View All Achievements
-func (s *AdminAchievementService) ViewAllAchievements(ctx context.Context, req *ViewAllAchievementsRequest) (*ViewAllAchievementsResponse, error) {
 	// Set default values
 	if req.Page < 1 {
 		req.Page = 1
@@ -141,7 +143,7 @@ func (s *AdminAchievementService) ViewAllAchievements(ctx context.Context, req *
 			studentInfo, err := s.getStudentInfo(ref.StudentID)
 			if err == nil {
 				studentMap[ref.StudentID] = studentInfo
-				
+
 				// Get advisor info if not cached
 				if studentInfo != nil && studentInfo.ID != uuid.Nil {
 					student, err := s.Repo.GetStudentByID(ref.StudentID)
@@ -163,7 +165,7 @@ func (s *AdminAchievementService) ViewAllAchievements(ctx context.Context, req *
 	for _, ref := range references {
 		achievement := achievementMap[ref.MongoAchievementID]
 		student := studentMap[ref.StudentID]
-		
+
 		var advisor *AdminAdvisorInfo
 		if student != nil {
 			studentRecord, err := s.Repo.GetStudentByID(ref.StudentID)
@@ -250,14 +252,21 @@ func (s *AdminAchievementService) getAchievementSummary(filter *model.AdminAchie
 	return summary, nil
 }
 
-// GetAchievementByReferenceID - Get achievement detail by reference ID
-func (s *AdminAchievementService) GetAchievementByReferenceID(ctx context.Context, referenceID uuid.UUID) (*AdminAchievementResponse, error) {
+// GetAchievementByReferenceID - Get achievement detail by reference ID (admin ter-scope hanya program studinya)
+func (s *AdminAchievementService) GetAchievementByReferenceID(ctx context.Context, scope *model.ProgramStudyScope, referenceID uuid.UUID) (*AdminAchievementResponse, error) {
 	// Get reference
 	reference, err := s.Repo.GetAchievementReferenceByID(referenceID)
 	if err != nil {
 		return nil, errors.New("achievement reference not found")
 	}
 
+	if !scope.IsGlobal() {
+		owner, err := s.Repo.GetStudentByID(reference.StudentID)
+		if err != nil || !scope.Allows(owner.ProgramStudy) {
+			return nil, ErrOutOfScope
+		}
+	}
+
 	// Get achievement from MongoDB
 	mongoID, err := primitive.ObjectIDFromHex(reference.MongoAchievementID)
 	if err != nil {
@@ -288,4 +297,4 @@ func (s *AdminAchievementService) GetAchievementByReferenceID(ctx context.Contex
 		Student:     student,
 		Advisor:     advisor,
 	}, nil
-}
\ No newline at end of file
+}
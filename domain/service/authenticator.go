@@ -0,0 +1,42 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"errors"
+)
+
+var (
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrAuthBackendUnavailable = errors.New("authentication backend unavailable")
+	ErrExternalPassword       = errors.New("password is managed by an external directory")
+)
+
+// Nama backend autentikasi, disimpan di users.auth_source dan roles.auth_source
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
+// Authenticator - Backend pemeriksa password untuk AuthService.Login.
+// Backend dipilih per user (users.auth_source), atau per role (roles.auth_source) jika user tidak mengatur.
+type Authenticator interface {
+	// Authenticate - Cek password user. Return ErrInvalidCredentials jika salah,
+	// ErrAuthBackendUnavailable jika backend tidak bisa dihubungi (tidak dihitung sebagai login gagal).
+	// Profile non-nil disinkronkan ke data user lokal.
+	Authenticate(user *model.Users, password string) (*model.DirectoryProfile, error)
+}
+
+// PasswordAuthenticator - Backend lokal: hash bcrypt di tabel users
+type PasswordAuthenticator struct{}
+
+func (PasswordAuthenticator) Authenticate(user *model.Users, password string) (*model.DirectoryProfile, error) {
+	if CheckPassword(user.PasswordHash, password) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return nil, nil
+}
+
+// IsValidAuthSource - True untuk nama backend yang dikenal
+func IsValidAuthSource(source string) bool {
+	return source == AuthSourceLocal || source == AuthSourceLDAP
+}
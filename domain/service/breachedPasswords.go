@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedPasswordChecker - Cek password terhadap daftar password bocor secara offline.
+// Format file mengikuti range API k-anonymity Have I Been Pwned: satu file per 5 karakter
+// pertama SHA-1 (hex huruf besar), misalnya "5BAA6.txt", berisi baris "SUFFIX" atau "SUFFIX:COUNT".
+// Password atau hash lengkapnya tidak pernah dikirim ke mana pun.
+type BreachedPasswordChecker struct {
+	Dir string // Folder berisi file prefix, kosong berarti pengecekan nonaktif
+}
+
+func NewBreachedPasswordChecker(dir string) *BreachedPasswordChecker {
+	return &BreachedPasswordChecker{Dir: dir}
+}
+
+// IsBreached - Return true jika SHA-1 password ada di daftar. Prefix tanpa file dianggap tidak bocor.
+func (b *BreachedPasswordChecker) IsBreached(password string) (bool, error) {
+	if b == nil || b.Dir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := b.openPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(candidate, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// openPrefix - Buka file prefix, dengan atau tanpa ekstensi .txt
+func (b *BreachedPasswordChecker) openPrefix(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(b.Dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Open(filepath.Join(b.Dir, prefix))
+	}
+	return file, err
+}
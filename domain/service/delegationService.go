@@ -0,0 +1,234 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDelegationNotFound      = errors.New("delegation not found")
+	ErrDelegationForbidden     = errors.New("you can only manage delegations of your own advisees")
+	ErrDelegationActor         = errors.New("only lecturers or admins can manage delegations")
+	ErrDelegatorRequired       = errors.New("delegator_id is required")
+	ErrInvalidDelegate         = errors.New("delegate must be another existing lecturer")
+	ErrInvalidDelegationPeriod = errors.New("ends_at must be after starts_at and in the future")
+	ErrStudentNotAdvisee       = errors.New("student is not an advisee of the delegator")
+	ErrDelegationRevoked       = errors.New("delegation is already revoked")
+)
+
+// DelegationService - Kelola delegasi hak verifikasi prestasi dari dosen wali ke dosen pengganti.
+// Dosen hanya mendelegasikan mahasiswa bimbingannya sendiri, admin boleh atas nama dosen di dalam scope-nya.
+type DelegationService struct {
+	Repo            *repository.DelegationRepository
+	AchievementRepo *repository.AchievementRepository
+	RBAC            *RBACService
+	Notifications   *NotificationService
+}
+
+func NewDelegationService(repo *repository.DelegationRepository, achievementRepo *repository.AchievementRepository, rbac *RBACService, notificationService *NotificationService) *DelegationService {
+	return &DelegationService{
+		Repo:            repo,
+		AchievementRepo: achievementRepo,
+		RBAC:            rbac,
+		Notifications:   notificationService,
+	}
+}
+
+// delegationActor - User yang mengelola delegasi: data dosennya (nil jika bukan dosen) dan,
+// jika admin, scope program studinya
+type delegationActor struct {
+	Lecturer *model.Lecturer
+	IsAdmin  bool
+	Scope    *model.ProgramStudyScope
+}
+
+// manages - Admin boleh mengelola delegasi dosen ini (department dosen di dalam scope-nya)
+func (a *delegationActor) manages(lecturer *model.Lecturer) bool {
+	return a.IsAdmin && lecturer != nil && a.Scope.Allows(lecturer.Department)
+}
+
+// actor - Data dosen dan scope admin milik user
+func (s *DelegationService) actor(userID uuid.UUID) (*delegationActor, error) {
+	isAdmin, err := s.RBAC.UserHasPermission(userID, "admin.manage")
+	if err != nil {
+		return nil, errors.New("failed to get user permissions: " + err.Error())
+	}
+
+	lecturer, _ := s.AchievementRepo.GetLecturerByUserID(userID)
+	if lecturer == nil && !isAdmin {
+		return nil, ErrDelegationActor
+	}
+
+	actor := &delegationActor{Lecturer: lecturer, IsAdmin: isAdmin}
+	if isAdmin {
+		actor.Scope, err = s.RBAC.AdminScope(userID)
+		if err != nil {
+			return nil, errors.New("failed to get admin scope: " + err.Error())
+		}
+	}
+
+	return actor, nil
+}
+
+// CreateDelegation - Buat delegasi verifikasi dan beri tahu dosen pengganti
+func (s *DelegationService) CreateDelegation(userID uuid.UUID, req *model.CreateDelegationRequest) (*model.VerificationDelegation, error) {
+	actor, err := s.actor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tentukan dosen wali yang mendelegasikan
+	var delegatorID uuid.UUID
+	switch {
+	case actor.IsAdmin && req.DelegatorID != nil:
+		delegatorID = *req.DelegatorID
+	case actor.Lecturer != nil:
+		if req.DelegatorID != nil && *req.DelegatorID != actor.Lecturer.ID {
+			return nil, ErrDelegationForbidden
+		}
+		delegatorID = actor.Lecturer.ID
+	default:
+		return nil, ErrDelegatorRequired
+	}
+
+	delegator, err := s.AchievementRepo.GetLecturerByID(delegatorID)
+	if err != nil {
+		return nil, errors.New("delegator lecturer not found")
+	}
+
+	delegate, err := s.AchievementRepo.GetLecturerByID(req.DelegateID)
+	if err != nil || delegate.ID == delegator.ID {
+		return nil, ErrInvalidDelegate
+	}
+
+	// Atas nama dosen lain: dosen wali dan dosen pengganti harus di dalam scope admin
+	ownDelegation := actor.Lecturer != nil && actor.Lecturer.ID == delegator.ID
+	if !ownDelegation && (!actor.manages(delegator) || !actor.manages(delegate)) {
+		return nil, ErrOutOfScope
+	}
+
+	// Validasi periode, starts_at kosong = mulai sekarang
+	now := time.Now()
+	startsAt := req.StartsAt
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+	if !req.EndsAt.After(startsAt) || !req.EndsAt.After(now) {
+		return nil, ErrInvalidDelegationPeriod
+	}
+
+	// Subset mahasiswa harus mahasiswa bimbingan dosen wali
+	studentIDs := []uuid.UUID{}
+	for _, studentID := range req.StudentIDs {
+		student, err := s.AchievementRepo.GetStudentByID(studentID)
+		if err != nil || student.AdvisorID != delegator.ID {
+			return nil, ErrStudentNotAdvisee
+		}
+		studentIDs = append(studentIDs, studentID)
+	}
+
+	delegation := &model.VerificationDelegation{
+		DelegatorID: delegator.ID,
+		DelegateID:  delegate.ID,
+		StartsAt:    startsAt,
+		EndsAt:      req.EndsAt,
+		StudentIDs:  studentIDs,
+		Reason:      req.Reason,
+		CreatedBy:   &userID,
+	}
+
+	if err := s.Repo.CreateDelegation(delegation); err != nil {
+		return nil, errors.New("failed to create delegation: " + err.Error())
+	}
+
+	// Beri tahu dosen pengganti (gagal kirim notifikasi tidak membatalkan delegasi)
+	_ = s.Notifications.CreateDelegationReceivedNotification(delegate.UserID, s.lecturerName(delegator), delegation.StartsAt, delegation.EndsAt, delegation.ID)
+
+	return delegation, nil
+}
+
+// GetDelegations - Dosen melihat delegasi yang dibuat atau diterimanya, admin melihat semua delegasi
+// dosen wali di dalam scope-nya atau hanya milik dosen lecturerID (uuid.Nil = semua)
+func (s *DelegationService) GetDelegations(userID, lecturerID uuid.UUID) ([]model.VerificationDelegation, error) {
+	actor, err := s.actor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actor.IsAdmin {
+		lecturerID = actor.Lecturer.ID
+	}
+
+	delegations, err := s.Repo.GetDelegations(lecturerID)
+	if err != nil {
+		return nil, errors.New("failed to get delegations: " + err.Error())
+	}
+
+	if !actor.IsAdmin || actor.Scope.IsGlobal() {
+		return delegations, nil
+	}
+
+	// Admin ter-scope: hanya delegasi dari dosen wali di program studinya
+	inScope := []model.VerificationDelegation{}
+	for _, delegation := range delegations {
+		delegator, _ := s.AchievementRepo.GetLecturerByID(delegation.DelegatorID)
+		if actor.manages(delegator) {
+			inScope = append(inScope, delegation)
+		}
+	}
+
+	return inScope, nil
+}
+
+// RevokeDelegation - Cabut delegasi oleh dosen wali yang membuatnya atau admin yang scope-nya mencakup dosen wali
+func (s *DelegationService) RevokeDelegation(userID, delegationID uuid.UUID) error {
+	actor, err := s.actor(userID)
+	if err != nil {
+		return err
+	}
+
+	delegation, err := s.Repo.GetDelegationByID(delegationID)
+	if err != nil {
+		return ErrDelegationNotFound
+	}
+
+	if actor.Lecturer == nil || actor.Lecturer.ID != delegation.DelegatorID {
+		if !actor.IsAdmin {
+			return ErrDelegationForbidden
+		}
+		delegator, _ := s.AchievementRepo.GetLecturerByID(delegation.DelegatorID)
+		if !actor.manages(delegator) {
+			return ErrOutOfScope
+		}
+	}
+
+	revoked, err := s.Repo.RevokeDelegation(delegationID)
+	if err != nil {
+		return errors.New("failed to revoke delegation: " + err.Error())
+	}
+	if !revoked {
+		return ErrDelegationRevoked
+	}
+
+	if delegate, err := s.AchievementRepo.GetLecturerByID(delegation.DelegateID); err == nil {
+		delegator, _ := s.AchievementRepo.GetLecturerByID(delegation.DelegatorID)
+		_ = s.Notifications.CreateDelegationRevokedNotification(delegate.UserID, s.lecturerName(delegator), delegation.ID)
+	}
+
+	return nil
+}
+
+// lecturerName - Nama lengkap dosen untuk pesan notifikasi
+func (s *DelegationService) lecturerName(lecturer *model.Lecturer) string {
+	if lecturer == nil {
+		return "Dosen wali"
+	}
+	if user, err := s.AchievementRepo.GetUserByID(lecturer.UserID); err == nil {
+		return user.FullName
+	}
+	return "Dosen wali"
+}
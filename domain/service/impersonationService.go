@@ -0,0 +1,164 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const maxImpersonationReasonLength = 255
+
+var (
+	ErrImpersonateSelf        = errors.New("cannot impersonate yourself")
+	ErrImpersonateAdmin       = errors.New("cannot impersonate another admin")
+	ErrImpersonationReason    = errors.New("reason is required (max 255 characters)")
+	ErrImpersonationNotActive = errors.New("impersonating admin is no longer allowed")
+)
+
+// ImpersonationService - "View as user": admin mendapat token pendek atas nama user lain.
+// Token membawa claim "act" berisi admin asli, hanya boleh untuk request baca, dan setiap
+// request dengan token tersebut dicatat di audit log.
+type ImpersonationService struct {
+	Auth *AuthService
+	RBAC *RBACService
+	Repo *repository.ImpersonationRepository
+	TTL  time.Duration // Masa berlaku token impersonation, tanpa refresh token
+}
+
+func NewImpersonationService(auth *AuthService, rbac *RBACService, repo *repository.ImpersonationRepository, ttl time.Duration) *ImpersonationService {
+	return &ImpersonationService{
+		Auth: auth,
+		RBAC: rbac,
+		Repo: repo,
+		TTL:  ttl,
+	}
+}
+
+// ImpersonationAllows - Request yang boleh dijalankan dengan token impersonation:
+// hanya baca (GET/HEAD) di luar /api/admin, ditambah logout untuk mengakhiri impersonation.
+func ImpersonationAllows(method, path string) bool {
+	if method == "POST" && path == "/api/v1/auth/logout" {
+		return true
+	}
+	if method != "GET" && method != "HEAD" {
+		return false
+	}
+	return path != "/api/admin" && !strings.HasPrefix(path, "/api/admin/")
+}
+
+// Start - Terbitkan token impersonation untuk targetID atas nama adminID
+func (s *ImpersonationService) Start(adminID, targetID uuid.UUID, reason string, client model.ClientInfo) (*model.ImpersonationResponse, error) {
+	// 1. Validasi alasan dan target
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxImpersonationReasonLength {
+		return nil, ErrImpersonationReason
+	}
+	if adminID == targetID {
+		return nil, ErrImpersonateSelf
+	}
+
+	user, err := s.Auth.Repo.GetUserByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	isAdmin, err := s.RBAC.UserHasRole(user.ID, adminRoleName)
+	if err != nil {
+		return nil, errors.New("failed to get user roles: " + err.Error())
+	}
+	if isAdmin {
+		return nil, ErrImpersonateAdmin
+	}
+
+	// 2. Token dengan role dan permissions user target, tanpa sesi dan refresh token
+	userVersion, roleVersion, err := s.Auth.Repo.GetPermissionVersions(user.ID)
+	if err != nil {
+		log.Printf("Failed to get permission versions for user %s: %v", user.ID, err)
+	}
+	permissions, err := s.Auth.Repo.GetUserPermissions(user.ID)
+	if err != nil {
+		permissions = []string{}
+	}
+
+	tokenID := uuid.New().String()
+	signed, expiresAt, err := s.Auth.signAccessTokenTTL(model.CustomClaims{
+		UserID:          user.ID,
+		RoleID:          user.RoleID,
+		Permissions:     permissions,
+		UserPermVersion: userVersion,
+		RolePermVersion: roleVersion,
+		Actor:           &model.ImpersonationActor{UserID: adminID},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID: tokenID, // jti dicatat di audit log
+		},
+	}, s.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Catat awal impersonation, token tidak diterbitkan jika audit log gagal
+	err = s.Repo.CreateLog(&model.ImpersonationLog{
+		AdminID:   adminID,
+		UserID:    user.ID,
+		TokenID:   tokenID,
+		Event:     model.ImpersonationEventStart,
+		Reason:    reason,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		return nil, errors.New("failed to write impersonation audit log: " + err.Error())
+	}
+
+	return &model.ImpersonationResponse{
+		Token:     signed,
+		ExpiresAt: expiresAt,
+		User:      *user,
+	}, nil
+}
+
+// CheckActor - Admin di balik token harus masih aktif dan masih admin
+func (s *ImpersonationService) CheckActor(claims *model.CustomClaims) error {
+	state, err := s.RBAC.GetPermissionState(claims.Actor.UserID)
+	if err != nil {
+		return err
+	}
+	if !state.IsActive || !hasRole(state, adminRoleName) {
+		return ErrImpersonationNotActive
+	}
+
+	return nil
+}
+
+// RecordRequest - Catat request dengan token impersonation (diizinkan atau ditolak)
+func (s *ImpersonationService) RecordRequest(claims *model.CustomClaims, event, method, path string, statusCode int, client model.ClientInfo) {
+	err := s.Repo.CreateLog(&model.ImpersonationLog{
+		AdminID:    claims.Actor.UserID,
+		UserID:     claims.UserID,
+		TokenID:    claims.ID,
+		Event:      event,
+		Method:     method,
+		Path:       path,
+		StatusCode: statusCode,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	})
+	if err != nil {
+		log.Printf("Failed to write impersonation audit log (admin %s, user %s, %s %s): %v",
+			claims.Actor.UserID, claims.UserID, method, path, err)
+	}
+}
+
+// GetLogs - Audit log impersonation, filter admin/user opsional
+func (s *ImpersonationService) GetLogs(adminID, userID uuid.UUID, limit, offset int) ([]model.ImpersonationLog, int, error) {
+	return s.Repo.GetLogs(adminID, userID, limit, offset)
+}
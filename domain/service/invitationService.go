@@ -0,0 +1,209 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInvitation   = errors.New("invalid or expired invitation")
+	ErrNoPendingInvitation = errors.New("user has no pending invitation")
+)
+
+// InviteUserRequest - DTO untuk undang user baru, password dibuat sendiri oleh user saat aktivasi
+type InviteUserRequest struct {
+	Username     string                  `json:"username"`
+	Email        string                  `json:"email"`
+	FullName     string                  `json:"full_name"`
+	RoleID       uuid.UUID               `json:"role_id"`
+	StudentData  *StudentProfileRequest  `json:"student_data,omitempty"`
+	LecturerData *LecturerProfileRequest `json:"lecturer_data,omitempty"`
+}
+
+// InvitationResponse - DTO response undang/kirim ulang undangan
+type InvitationResponse struct {
+	*UserResponse
+	InvitationExpiresAt time.Time `json:"invitation_expires_at"`
+}
+
+type InvitationService struct {
+	Users     *UserService
+	Repo      *repository.InvitationRepository
+	Mail      *MailService
+	Passwords *PasswordService
+	TokenTTL  time.Duration
+	InviteURL string // URL halaman aktivasi akun di frontend, token ditambahkan sebagai query ?token=
+}
+
+func NewInvitationService(users *UserService, repo *repository.InvitationRepository, mail *MailService, passwords *PasswordService, tokenTTL time.Duration, inviteURL string) *InvitationService {
+	return &InvitationService{
+		Users:     users,
+		Repo:      repo,
+		Mail:      mail,
+		Passwords: passwords,
+		TokenTTL:  tokenTTL,
+		InviteURL: inviteURL,
+	}
+}
+
+// InviteUser - Buat user nonaktif beserta profilnya, lalu kirim link aktivasi ke email user
+func (s *InvitationService) InviteUser(req *InviteUserRequest, invitedBy uuid.UUID) (*InvitationResponse, error) {
+	if req.Username == "" {
+		return nil, errors.New("username is required")
+	}
+	if req.Email == "" {
+		return nil, errors.New("email is required")
+	}
+	if req.FullName == "" {
+		return nil, errors.New("full name is required")
+	}
+	if req.RoleID == uuid.Nil {
+		return nil, errors.New("role ID is required")
+	}
+
+	// 1. Password acak yang tidak pernah dikirim ke siapa pun, user belum bisa login sampai aktivasi
+	placeholder, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate password")
+	}
+	hashedPassword, err := HashPassword(placeholder)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	user := &model.Users{
+		ID:           uuid.New(),
+		Username:     req.Username,
+		Email:        req.Email,
+		PasswordHash: hashedPassword,
+		FullName:     req.FullName,
+		RoleID:       req.RoleID,
+		IsActive:     false,
+	}
+
+	// 2. Simpan user dan profil student/lecturer
+	response, err := s.Users.createUserWithProfile(user, req.StudentData, req.LecturerData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Buat undangan dan kirim email (user sudah tersimpan, gagal di sini bisa diulang lewat resend-invite)
+	invitation, err := s.sendInvitation(user, invitedBy)
+	if err != nil {
+		return nil, errors.New("user created but invitation was not sent, resend the invitation: " + err.Error())
+	}
+
+	return &InvitationResponse{
+		UserResponse:        response,
+		InvitationExpiresAt: invitation.ExpiresAt,
+	}, nil
+}
+
+// ResendInvitation - Kirim ulang link aktivasi dengan token dan masa berlaku baru, link lama tidak berlaku lagi
+func (s *InvitationService) ResendInvitation(userID, invitedBy uuid.UUID) (*InvitationResponse, error) {
+	user, err := s.Users.Repo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Hanya user yang dibuat lewat undangan dan belum pernah aktivasi
+	current, err := s.Repo.GetInvitationByUserID(userID)
+	if err != nil || current.AcceptedAt != nil || user.IsActive {
+		return nil, ErrNoPendingInvitation
+	}
+
+	invitation, err := s.sendInvitation(user, invitedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvitationResponse{
+		UserResponse:        response,
+		InvitationExpiresAt: invitation.ExpiresAt,
+	}, nil
+}
+
+// AcceptInvitation - Aktivasi akun dari link undangan: user membuat password sendiri
+func (s *InvitationService) AcceptInvitation(rawToken, newPassword string) error {
+	// 1. Cari undangan berdasarkan hash token
+	invitation, err := s.Repo.GetInvitationByHash(hashToken(rawToken))
+	if err != nil {
+		return ErrInvalidInvitation
+	}
+
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return ErrInvalidInvitation
+	}
+
+	user, err := s.Users.Repo.GetUserByID(invitation.UserID)
+	if err != nil {
+		return ErrInvalidInvitation
+	}
+
+	if err := s.Passwords.CheckNewPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	// 2. Pakai undangan, set password, dan aktifkan user (atomic, undangan hanya berlaku sekali)
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	ok, err := s.Repo.AcceptInvitation(invitation.ID, user.ID, hashedPassword)
+	if err != nil {
+		return errors.New("failed to accept invitation: " + err.Error())
+	}
+	if !ok {
+		return ErrInvalidInvitation
+	}
+
+	if err := s.Passwords.RecordPassword(user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to record password history for user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// sendInvitation - Generate token undangan baru (hanya hash yang disimpan) dan enqueue email aktivasi
+func (s *InvitationService) sendInvitation(user *model.Users, invitedBy uuid.UUID) (*model.UserInvitation, error) {
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate invitation token")
+	}
+
+	invitation := &model.UserInvitation{
+		UserID:    user.ID,
+		TokenHash: hashToken(raw),
+		InvitedBy: &invitedBy,
+		ExpiresAt: time.Now().Add(s.TokenTTL),
+	}
+
+	if err := s.Repo.SaveInvitation(invitation); err != nil {
+		return nil, errors.New("failed to create invitation: " + err.Error())
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nAdmin telah membuatkan akun untuk Anda dengan username %s.\n"+
+			"Buka link berikut untuk membuat password dan mengaktifkan akun (berlaku %d jam, hanya bisa dipakai sekali):\n\n%s?token=%s\n\n"+
+			"Jika link sudah kadaluarsa, hubungi admin untuk mengirim ulang undangan.\n",
+		user.FullName, user.Username, int(s.TokenTTL.Hours()), s.InviteURL, raw,
+	)
+
+	if err := s.Mail.Enqueue(user.Email, "Aktivasi Akun", body); err != nil {
+		return nil, errors.New("failed to send invitation email: " + err.Error())
+	}
+
+	return invitation, nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey - Satu pasangan key JWT yang diidentifikasi dengan kid.
+// PrivateKey nil berarti key sudah pensiun: hanya dipakai untuk verifikasi token lama.
+type SigningKey struct {
+	KID        string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeyManager - Kumpulan key JWT asimetris (RS256/EdDSA) dengan satu key aktif untuk signing
+type KeyManager struct {
+	keys      map[string]*SigningKey
+	activeKID string
+}
+
+// LoadKeyManager - Muat semua key dari direktori.
+//
+// Format file:
+//   - <kid>.pem     private key (PKCS#8 RSA/Ed25519 atau PKCS#1 RSA), bisa sign dan verify
+//   - <kid>.pub.pem public key (PKIX), hanya verify (key yang sudah pensiun)
+//
+// Key aktif dipilih dari activeKID, atau private key dengan kid terbesar secara leksikografis
+// (mis. kid berbentuk tanggal "2025-01"). Jika direktori kosong, key Ed25519 sementara dibuat
+// sehingga server tetap bisa jalan di development.
+func LoadKeyManager(dir, activeKID string) (*KeyManager, error) {
+	km := &KeyManager{keys: make(map[string]*SigningKey)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read JWT key directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key %s: %w", name, err)
+		}
+
+		var key *SigningKey
+		if strings.HasSuffix(name, ".pub.pem") {
+			key, err = parsePublicKeyPEM(strings.TrimSuffix(name, ".pub.pem"), data)
+		} else {
+			key, err = parsePrivateKeyPEM(strings.TrimSuffix(name, ".pem"), data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT key %s: %w", name, err)
+		}
+
+		// Private key menang jika kid yang sama juga punya file .pub.pem
+		if existing, ok := km.keys[key.KID]; ok && existing.PrivateKey != nil {
+			continue
+		}
+		km.keys[key.KID] = key
+	}
+
+	if len(km.keys) == 0 {
+		log.Println("WARNING: no JWT keys found in", dir, "- using an ephemeral Ed25519 key, tokens will not survive a restart")
+		key, err := generateEphemeralKey()
+		if err != nil {
+			return nil, err
+		}
+		km.keys[key.KID] = key
+	}
+
+	if activeKID == "" {
+		activeKID = km.newestSigningKID()
+	}
+
+	active, ok := km.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active JWT key %q not found", activeKID)
+	}
+	if active.PrivateKey == nil {
+		return nil, fmt.Errorf("active JWT key %q has no private key", activeKID)
+	}
+	km.activeKID = activeKID
+
+	return km, nil
+}
+
+// ActiveKID - kid yang dipakai untuk signing token baru
+func (km *KeyManager) ActiveKID() string {
+	return km.activeKID
+}
+
+// Sign - Tanda tangani claims dengan key aktif, kid ditulis di header token
+func (km *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	key := km.keys[km.activeKID]
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.KID
+
+	return token.SignedString(key.PrivateKey)
+}
+
+// Keyfunc - Pilih public key berdasarkan kid di header token
+func (km *KeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key, ok := km.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.PublicKey, nil
+}
+
+// ValidMethods - Algoritma yang diterima saat parsing token
+func (km *KeyManager) ValidMethods() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+// JWKS - Public key semua key (aktif dan pensiun) dalam format JSON Web Key Set
+func (km *KeyManager) JWKS() model.JWKS {
+	kids := make([]string, 0, len(km.keys))
+	for kid := range km.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := model.JWKS{Keys: []model.JWK{}}
+	for _, kid := range kids {
+		key := km.keys[kid]
+		jwk := model.JWK{
+			KID: key.KID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+		}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func (km *KeyManager) newestSigningKID() string {
+	newest := ""
+	for kid, key := range km.keys {
+		if key.PrivateKey != nil && kid > newest {
+			newest = kid
+		}
+	}
+	return newest
+}
+
+func parsePrivateKeyPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		if priv.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		return &SigningKey{KID: kid, Method: jwt.SigningMethodRS256, PrivateKey: priv, PublicKey: &priv.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{KID: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: priv, PublicKey: priv.Public()}, nil
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+}
+
+func parsePublicKeyPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pub := parsed.(type) {
+	case *rsa.PublicKey:
+		return &SigningKey{KID: kid, Method: jwt.SigningMethodRS256, PublicKey: pub}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{KID: kid, Method: jwt.SigningMethodEdDSA, PublicKey: pub}, nil
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+}
+
+func generateEphemeralKey() (*SigningKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	kidBytes := make([]byte, 8)
+	if _, err := rand.Read(kidBytes); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		KID:        "ephemeral-" + base64.RawURLEncoding.EncodeToString(kidBytes),
+		Method:     jwt.SigningMethodEdDSA,
+		PrivateKey: priv,
+		PublicKey:  pub,
+	}, nil
+}
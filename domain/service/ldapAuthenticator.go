@@ -0,0 +1,132 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	ldap "github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConfig - Koneksi dan pemetaan atribut direktori LDAP fakultas
+type LDAPConfig struct {
+	URL            string // ldap://host:389 atau ldaps://host:636
+	StartTLS       bool   // Upgrade koneksi ldap:// ke TLS sebelum bind
+	BindDN         string // Service account untuk mencari DN user, kosong = anonymous search
+	BindPassword   string
+	BaseDN         string // Mis. ou=lecturers,dc=example,dc=ac,dc=id
+	UserFilter     string // %s diganti username lokal (sudah di-escape), mis. (uid=%s)
+	FullNameAttr   string // Atribut untuk users.full_name
+	EmailAttr      string // Atribut untuk users.email
+	LecturerIDAttr string // Atribut untuk lecturers.lecturer_id
+	Timeout        time.Duration
+}
+
+// LDAPAuthenticator - Backend LDAP: cari DN user dengan service account lalu bind sebagai user.
+// Username lokal harus sama dengan username di direktori (atribut di UserFilter).
+type LDAPAuthenticator struct {
+	Config LDAPConfig
+}
+
+func NewLDAPAuthenticator(cfg LDAPConfig) *LDAPAuthenticator {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.FullNameAttr == "" {
+		cfg.FullNameAttr = "cn"
+	}
+	if cfg.EmailAttr == "" {
+		cfg.EmailAttr = "mail"
+	}
+	if cfg.LecturerIDAttr == "" {
+		cfg.LecturerIDAttr = "employeeNumber"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	return &LDAPAuthenticator{Config: cfg}
+}
+
+// Authenticate - Bind ke LDAP sebagai user dan ambil atribut profil
+func (l *LDAPAuthenticator) Authenticate(user *model.Users, password string) (*model.DirectoryProfile, error) {
+	// Bind dengan password kosong adalah "unauthenticated bind" yang sukses di banyak server
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := l.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 1. Cari DN user
+	if l.Config.BindDN != "" {
+		if err := conn.Bind(l.Config.BindDN, l.Config.BindPassword); err != nil {
+			return nil, fmt.Errorf("%w: service account bind failed: %v", ErrAuthBackendUnavailable, err)
+		}
+	}
+
+	search := ldap.NewSearchRequest(
+		l.Config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, // Cukup untuk mendeteksi username yang tidak unik
+		int(l.Config.Timeout/time.Second),
+		false,
+		fmt.Sprintf(l.Config.UserFilter, ldap.EscapeFilter(user.Username)),
+		[]string{l.Config.FullNameAttr, l.Config.EmailAttr, l.Config.LecturerIDAttr},
+		nil,
+	)
+
+	result, err := conn.Search(search)
+	if err != nil {
+		return nil, fmt.Errorf("%w: search failed: %v", ErrAuthBackendUnavailable, err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	// 2. Verifikasi password dengan bind sebagai user
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("%w: user bind failed: %v", ErrAuthBackendUnavailable, err)
+	}
+
+	// 3. Pemetaan atribut ke data lokal
+	return &model.DirectoryProfile{
+		FullName:   strings.TrimSpace(entry.GetAttributeValue(l.Config.FullNameAttr)),
+		Email:      strings.TrimSpace(entry.GetAttributeValue(l.Config.EmailAttr)),
+		LecturerID: strings.TrimSpace(entry.GetAttributeValue(l.Config.LecturerIDAttr)),
+	}, nil
+}
+
+// connect - Buka koneksi (ldap:// atau ldaps://) dan StartTLS jika dikonfigurasi
+func (l *LDAPAuthenticator) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(l.Config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: l.Config.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthBackendUnavailable, err)
+	}
+	conn.SetTimeout(l.Config.Timeout)
+
+	if l.Config.StartTLS {
+		host := l.Config.URL
+		if parsed, err := url.Parse(l.Config.URL); err == nil {
+			host = parsed.Hostname()
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: StartTLS failed: %v", ErrAuthBackendUnavailable, err)
+		}
+	}
+
+	return conn, nil
+}
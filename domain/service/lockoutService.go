@@ -0,0 +1,230 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+)
+
+// LockoutPolicy - Aturan delay progresif dan lockout login
+type LockoutPolicy struct {
+	MaxAccountFailures int           // Gagal sebanyak ini per akun = akun dikunci
+	MaxIPFailures      int           // Gagal sebanyak ini per IP = IP dikunci (credential stuffing ke banyak akun)
+	LockDuration       time.Duration // Lama lockout sementara
+	FailureWindow      time.Duration // Counter mulai dari awal jika gagal terakhir lebih lama dari ini
+	FreeAttempts       int           // Jumlah gagal sebelum delay mulai berlaku
+	BaseDelay          time.Duration // Delay setelah gagal pertama di luar FreeAttempts, lalu naik 2x tiap gagal
+	MaxDelay           time.Duration
+}
+
+// DefaultLockoutPolicy - 5 gagal per akun / 50 gagal per IP dalam 15 menit = kunci 15 menit
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		MaxAccountFailures: 5,
+		MaxIPFailures:      50,
+		LockDuration:       15 * time.Minute,
+		FailureWindow:      15 * time.Minute,
+		FreeAttempts:       2,
+		BaseDelay:          1 * time.Second,
+		MaxDelay:           1 * time.Minute,
+	}
+}
+
+// Delay - Waktu tunggu sebelum login berikutnya boleh dicoba setelah failedCount kali gagal
+func (p LockoutPolicy) Delay(failedCount int) time.Duration {
+	if failedCount <= p.FreeAttempts || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	exp := failedCount - p.FreeAttempts - 1
+	if exp > 30 {
+		return p.MaxDelay
+	}
+
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(exp)))
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// LoginThrottledError - Login ditolak sebelum password dicek karena terlalu banyak percobaan gagal
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // true = lockout sementara, false = delay progresif
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, account temporarily locked, try again in %d seconds", e.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds - Nilai untuk header Retry-After
+func (e *LoginThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+type LockoutService struct {
+	Repo             *repository.LoginAttemptRepository
+	NotificationRepo *repository.NotificationRepository
+	Mail             *MailService
+	Policy           LockoutPolicy
+}
+
+func NewLockoutService(repo *repository.LoginAttemptRepository, notificationRepo *repository.NotificationRepository, mail *MailService, policy LockoutPolicy) *LockoutService {
+	return &LockoutService{
+		Repo:             repo,
+		NotificationRepo: notificationRepo,
+		Mail:             mail,
+		Policy:           policy,
+	}
+}
+
+// AccountKey - Key per akun. User yang ditemukan memakai ID-nya sehingga username dan email
+// berbagi counter yang sama; identifier yang tidak terdaftar tetap di-throttle per identifier.
+func AccountKey(user *model.Users, identifier string) string {
+	if user != nil {
+		return user.ID.String()
+	}
+	return "identifier:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// Check - Tolak login jika akun atau IP masih dalam delay/lockout
+func (s *LockoutService) Check(accountKey, ip string) error {
+	now := time.Now()
+
+	throttled := &LoginThrottledError{}
+	for _, k := range [][2]string{{loginScopeIP, ip}, {loginScopeAccount, accountKey}} {
+		if k[1] == "" {
+			continue
+		}
+
+		attempt, err := s.Repo.GetLoginAttempt(k[0], k[1])
+		if err != nil {
+			return errors.New("failed to check login attempts: " + err.Error())
+		}
+		if attempt == nil {
+			continue
+		}
+
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			throttled.Locked = true
+			if retry := attempt.LockedUntil.Sub(now); retry > throttled.RetryAfter {
+				throttled.RetryAfter = retry
+			}
+		}
+		if attempt.NextAttemptAt != nil && now.Before(*attempt.NextAttemptAt) {
+			if retry := attempt.NextAttemptAt.Sub(now); retry > throttled.RetryAfter {
+				throttled.RetryAfter = retry
+			}
+		}
+	}
+
+	if throttled.RetryAfter > 0 {
+		return throttled
+	}
+	return nil
+}
+
+// RecordFailure - Catat login gagal untuk akun dan IP, kunci jika threshold tercapai.
+// User boleh nil (identifier tidak terdaftar), notifikasi lockout hanya dikirim ke user yang ada.
+func (s *LockoutService) RecordFailure(accountKey, ip string, user *model.Users) error {
+	if ip != "" {
+		if _, err := s.recordFailure(loginScopeIP, ip, s.Policy.MaxIPFailures); err != nil {
+			return err
+		}
+	}
+
+	lockedUntil, err := s.recordFailure(loginScopeAccount, accountKey, s.Policy.MaxAccountFailures)
+	if err != nil {
+		return err
+	}
+
+	if lockedUntil != nil && user != nil {
+		s.notifyLocked(user, *lockedUntil)
+	}
+
+	return nil
+}
+
+// RecordSuccess - Reset counter akun setelah login berhasil.
+// Counter IP tidak di-reset supaya satu akun valid tidak bisa dipakai untuk menghapus jejak stuffing.
+func (s *LockoutService) RecordSuccess(accountKey string) error {
+	return s.Repo.ClearLoginAttempts(loginScopeAccount, accountKey)
+}
+
+// UnlockUser - Buka lockout akun oleh admin
+func (s *LockoutService) UnlockUser(userID uuid.UUID) error {
+	if err := s.Repo.ClearLoginAttempts(loginScopeAccount, userID.String()); err != nil {
+		return errors.New("failed to unlock user: " + err.Error())
+	}
+	return nil
+}
+
+// CleanupStale - Hapus data percobaan login lama
+func (s *LockoutService) CleanupStale() error {
+	return s.Repo.DeleteStaleLoginAttempts(24 * time.Hour)
+}
+
+// recordFailure - Return waktu lockout jika percobaan ini membuat key dikunci
+func (s *LockoutService) recordFailure(scope, key string, maxFailures int) (*time.Time, error) {
+	failedCount, err := s.Repo.RecordFailure(scope, key, s.Policy.FailureWindow)
+	if err != nil {
+		return nil, errors.New("failed to record login attempt: " + err.Error())
+	}
+
+	now := time.Now()
+	nextAttemptAt := now.Add(s.Policy.Delay(failedCount))
+
+	var lockedUntil *time.Time
+	if maxFailures > 0 && failedCount >= maxFailures {
+		until := now.Add(s.Policy.LockDuration)
+		lockedUntil = &until
+	}
+
+	if err := s.Repo.SetBlock(scope, key, nextAttemptAt, lockedUntil); err != nil {
+		return nil, errors.New("failed to record login attempt: " + err.Error())
+	}
+
+	return lockedUntil, nil
+}
+
+// notifyLocked - Kirim notifikasi in-app dan email saat akun dikunci
+func (s *LockoutService) notifyLocked(user *model.Users, lockedUntil time.Time) {
+	message := fmt.Sprintf(
+		"Akun Anda dikunci sementara sampai %s karena terlalu banyak percobaan login gagal. "+
+			"Jika ini bukan Anda, segera ganti password atau hubungi admin.",
+		lockedUntil.Format("02 Jan 2006 15:04"),
+	)
+
+	notification := &model.Notification{
+		UserID:  user.ID,
+		Type:    "account_locked",
+		Title:   "Akun Dikunci Sementara",
+		Message: message,
+	}
+	if err := s.NotificationRepo.CreateNotification(notification); err != nil {
+		log.Printf("Failed to create lockout notification: %v", err)
+	}
+
+	if s.Mail != nil && user.Email != "" {
+		body := fmt.Sprintf("Halo %s,\n\n%s\n", user.FullName, message)
+		if err := s.Mail.Enqueue(user.Email, "Akun Dikunci Sementara", body); err != nil {
+			log.Printf("Failed to enqueue lockout email: %v", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"log"
+	"time"
+)
+
+// maxMailAttempts - Setelah gagal sebanyak ini email ditandai 'failed'
+const maxMailAttempts = 5
+
+type MailService struct {
+	Repo   *repository.MailRepository
+	Mailer Mailer
+}
+
+func NewMailService(repo *repository.MailRepository, mailer Mailer) *MailService {
+	return &MailService{
+		Repo:   repo,
+		Mailer: mailer,
+	}
+}
+
+// Enqueue - Simpan email ke outbox, pengiriman dilakukan oleh worker
+func (s *MailService) Enqueue(to, subject, body string) error {
+	msg := &model.MailMessage{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	}
+
+	if err := s.Repo.EnqueueMail(msg); err != nil {
+		return errors.New("failed to enqueue mail: " + err.Error())
+	}
+
+	return nil
+}
+
+// ProcessOutbox - Kirim email pending satu batch. Email yang gagal dicoba lagi dengan backoff.
+func (s *MailService) ProcessOutbox(batchSize int) error {
+	mails, err := s.Repo.GetPendingMails(batchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range mails {
+		msg := &mails[i]
+
+		if sendErr := s.Mailer.Send(msg); sendErr != nil {
+			attempts := msg.Attempts + 1
+			nextAttemptAt := time.Now().Add(time.Duration(attempts*attempts) * time.Minute)
+			if err := s.Repo.MarkMailFailed(msg.ID, sendErr.Error(), nextAttemptAt, attempts >= maxMailAttempts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.Repo.MarkMailSent(msg.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartOutboxWorker - Jalankan goroutine untuk kirim email di outbox secara berkala
+func (s *MailService) StartOutboxWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.ProcessOutbox(50); err != nil {
+				log.Printf("Failed to process mail outbox: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"bytes"
+	"errors"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mailer - Pengirim email. Implementasi bisa diganti (SMTP, log, provider lain)
+// tanpa mengubah service yang meng-enqueue email.
+type Mailer interface {
+	Send(msg *model.MailMessage) error
+}
+
+// SMTPMailer - Kirim email lewat server SMTP.
+// Username kosong berarti tanpa AUTH, cocok untuk catch-all lokal seperti MailHog/Mailpit (localhost:1025).
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+// Send - Kirim satu email plain text
+func (m *SMTPMailer) Send(msg *model.MailMessage) error {
+	data, err := buildMailData(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{msg.To}, data)
+}
+
+// LogMailer - Mailer untuk development, email hanya ditulis ke log. Body berisi token reset password,
+// undangan, dan ganti email, jadi hanya ditulis jika LogBody diset eksplisit (MAIL_LOG_BODY=true).
+type LogMailer struct {
+	LogBody bool
+}
+
+func (m LogMailer) Send(msg *model.MailMessage) error {
+	if !m.LogBody {
+		log.Printf("Mail to %s: %s (body redacted, %d bytes)", msg.To, msg.Subject, len(msg.Body))
+		return nil
+	}
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// buildMailData - Susun header dan body email (RFC 5322, CRLF)
+func buildMailData(from string, msg *model.MailMessage) ([]byte, error) {
+	for _, v := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, errors.New("mail header contains line break")
+		}
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + msg.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("Message-ID: <" + uuid.New().String() + "@" + domain + ">\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
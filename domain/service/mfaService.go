@@ -0,0 +1,297 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidMFACode    = errors.New("invalid MFA code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+	ErrMFAAlreadyEnabled = errors.New("MFA is already enabled")
+	ErrMFANotEnabled     = errors.New("MFA is not enabled")
+	ErrMFARequiredByRole = errors.New("MFA is required for this role and cannot be disabled")
+)
+
+const (
+	MFAChallengeVerify = "verify" // User sudah enroll, tinggal masukkan kode
+	MFAChallengeEnroll = "enroll" // Role mewajibkan MFA, user harus enroll sebelum dapat token
+
+	mfaChallengeMaxAttempts = 5
+	recoveryCodeCount       = 10
+)
+
+type MFAService struct {
+	Repo         *repository.MFARepository
+	AuthRepo     *repository.AuthRepository
+	Issuer       string        // Nama yang tampil di authenticator app
+	ChallengeTTL time.Duration // Waktu untuk memasukkan kode setelah password benar
+}
+
+func NewMFAService(repo *repository.MFARepository, authRepo *repository.AuthRepository, issuer string) *MFAService {
+	return &MFAService{
+		Repo:         repo,
+		AuthRepo:     authRepo,
+		Issuer:       issuer,
+		ChallengeTTL: 5 * time.Minute,
+	}
+}
+
+// IsRequired - Cek apakah salah satu role user mewajibkan MFA
+func (s *MFAService) IsRequired(userID uuid.UUID) (bool, error) {
+	return s.Repo.IsMFARequiredForUser(userID)
+}
+
+// IsEnabled - Cek apakah user sudah mengaktifkan MFA
+func (s *MFAService) IsEnabled(userID uuid.UUID) (bool, error) {
+	mfa, err := s.Repo.GetUserMFA(userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.Enabled, nil
+}
+
+// BeginEnrollment - Buat secret TOTP baru (belum aktif sampai dikonfirmasi dengan kode pertama)
+func (s *MFAService) BeginEnrollment(userID uuid.UUID) (*model.MFAEnrollResponse, error) {
+	user, err := s.AuthRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate MFA secret")
+	}
+
+	saved, err := s.Repo.SavePendingMFA(user.ID, secret)
+	if err != nil {
+		return nil, errors.New("failed to save MFA secret: " + err.Error())
+	}
+	if !saved {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+
+	return &model.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: TOTPProvisioningURI(s.Issuer, account, secret),
+	}, nil
+}
+
+// ConfirmEnrollment - Aktifkan MFA dengan kode TOTP pertama, return recovery code (hanya ditampilkan sekali)
+func (s *MFAService) ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.Repo.GetUserMFA(userID)
+	if err != nil {
+		return nil, errors.New("failed to load MFA: " + err.Error())
+	}
+	if mfa == nil {
+		return nil, ErrMFANotEnabled
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+
+	enabled, err := s.Repo.EnableMFA(userID, step, hashes)
+	if err != nil {
+		return nil, errors.New("failed to enable MFA: " + err.Error())
+	}
+	if !enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	return codes, nil
+}
+
+// VerifyCode - Cek kode TOTP (sekali pakai per periode) atau recovery code (sekali pakai)
+func (s *MFAService) VerifyCode(userID uuid.UUID, code string) error {
+	mfa, err := s.Repo.GetUserMFA(userID)
+	if err != nil {
+		return errors.New("failed to load MFA: " + err.Error())
+	}
+	if mfa == nil || !mfa.Enabled {
+		return ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == totpDigits {
+		step, ok := ValidateTOTP(mfa.Secret, code, time.Now())
+		if !ok || step <= mfa.LastUsedStep {
+			return ErrInvalidMFACode
+		}
+
+		used, err := s.Repo.MarkStepUsed(userID, step)
+		if err != nil {
+			return errors.New("failed to verify MFA code: " + err.Error())
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	used, err := s.Repo.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return errors.New("failed to verify recovery code: " + err.Error())
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// Disable - Matikan MFA setelah verifikasi kode. Ditolak jika role user mewajibkan MFA.
+func (s *MFAService) Disable(userID uuid.UUID, code string) error {
+	required, err := s.IsRequired(userID)
+	if err != nil {
+		return errors.New("failed to check MFA requirement: " + err.Error())
+	}
+	if required {
+		return ErrMFARequiredByRole
+	}
+
+	if err := s.VerifyCode(userID, code); err != nil {
+		return err
+	}
+
+	if err := s.Repo.DeleteUserMFA(userID); err != nil {
+		return errors.New("failed to disable MFA: " + err.Error())
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes - Ganti semua recovery code setelah verifikasi kode
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+
+	if err := s.Repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, errors.New("failed to save recovery codes: " + err.Error())
+	}
+
+	return codes, nil
+}
+
+// Reset - Hapus MFA user oleh admin (mis. HP hilang dan recovery code habis).
+// Jika role mewajibkan MFA, user akan diminta enroll ulang saat login berikutnya.
+func (s *MFAService) Reset(userID uuid.UUID) error {
+	if err := s.Repo.DeleteUserMFA(userID); err != nil {
+		return errors.New("failed to reset MFA: " + err.Error())
+	}
+	return nil
+}
+
+// CreateChallenge - Buat token challenge MFA untuk langkah kedua login
+func (s *MFAService) CreateChallenge(userID uuid.UUID, purpose string) (*model.MFAChallengeResponse, error) {
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate MFA token")
+	}
+
+	challenge := &model.MFAChallenge{
+		UserID:    userID,
+		TokenHash: hashToken(raw),
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(s.ChallengeTTL),
+	}
+
+	if err := s.Repo.CreateChallenge(challenge); err != nil {
+		return nil, errors.New("failed to create MFA challenge: " + err.Error())
+	}
+
+	return &model.MFAChallengeResponse{
+		MFAToken:           raw,
+		EnrollmentRequired: purpose == MFAChallengeEnroll,
+		ExpiresAt:          challenge.ExpiresAt,
+	}, nil
+}
+
+// GetChallenge - Ambil challenge yang masih berlaku untuk purpose tertentu
+func (s *MFAService) GetChallenge(rawToken, purpose string) (*model.MFAChallenge, error) {
+	challenge, err := s.Repo.GetChallengeByHash(hashToken(rawToken))
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if challenge.Purpose != purpose ||
+		challenge.UsedAt != nil ||
+		challenge.Attempts >= mfaChallengeMaxAttempts ||
+		time.Now().After(challenge.ExpiresAt) {
+		return nil, ErrInvalidMFAToken
+	}
+
+	return challenge, nil
+}
+
+// FailChallenge - Catat kode salah, challenge tidak berlaku lagi setelah terlalu banyak percobaan
+func (s *MFAService) FailChallenge(challengeID uuid.UUID) error {
+	return s.Repo.IncrementChallengeAttempts(challengeID)
+}
+
+// CompleteChallenge - Tandai challenge selesai (sekali pakai)
+func (s *MFAService) CompleteChallenge(challengeID uuid.UUID) error {
+	ok, err := s.Repo.MarkChallengeUsed(challengeID)
+	if err != nil {
+		return errors.New("failed to complete MFA challenge: " + err.Error())
+	}
+	if !ok {
+		return ErrInvalidMFAToken
+	}
+	return nil
+}
+
+// generateRecoveryCodes - Recovery code format xxxxx-xxxxx, return kode asli dan hash-nya
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode - Abaikan huruf besar, spasi, dan tanda hubung saat user mengetik recovery code
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -4,6 +4,7 @@ import (
 	model "UAS_BACKEND/domain/Model"
 	"UAS_BACKEND/domain/repository"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -60,6 +61,32 @@ func (s *NotificationService) CreateAchievementRejectedNotification(studentUserI
 	return s.Repo.CreateNotification(notification)
 }
 
+// CreateDelegationReceivedNotification - Buat notifikasi untuk dosen pengganti saat menerima delegasi verifikasi
+func (s *NotificationService) CreateDelegationReceivedNotification(delegateUserID uuid.UUID, delegatorName string, startsAt, endsAt time.Time, delegationID uuid.UUID) error {
+	notification := &model.Notification{
+		UserID:    delegateUserID,
+		Type:      "verification_delegated",
+		Title:     "Delegasi Verifikasi Prestasi",
+		Message:   fmt.Sprintf("%s mendelegasikan verifikasi prestasi mahasiswa bimbingannya kepada Anda mulai %s sampai %s.", delegatorName, startsAt.Format("02-01-2006 15:04"), endsAt.Format("02-01-2006 15:04")),
+		RelatedID: &delegationID,
+	}
+
+	return s.Repo.CreateNotification(notification)
+}
+
+// CreateDelegationRevokedNotification - Buat notifikasi untuk dosen pengganti saat delegasi dicabut
+func (s *NotificationService) CreateDelegationRevokedNotification(delegateUserID uuid.UUID, delegatorName string, delegationID uuid.UUID) error {
+	notification := &model.Notification{
+		UserID:    delegateUserID,
+		Type:      "verification_delegation_revoked",
+		Title:     "Delegasi Verifikasi Dicabut",
+		Message:   fmt.Sprintf("Delegasi verifikasi prestasi dari %s telah dicabut.", delegatorName),
+		RelatedID: &delegationID,
+	}
+
+	return s.Repo.CreateNotification(notification)
+}
+
 // GetUserNotifications - Ambil notifikasi user
 func (s *NotificationService) GetUserNotifications(userID uuid.UUID, limit int) ([]model.Notification, error) {
 	if limit <= 0 {
@@ -0,0 +1,390 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrOIDCTokenInvalid = errors.New("invalid ID token from identity provider")
+
+// Batas ukuran response identity provider yang dibaca
+const maxOIDCResponseSize = 1 << 20
+
+// JWKS identity provider di-fetch ulang untuk kid yang belum dikenal, paling sering sekali per interval ini
+const oidcJWKSRefreshInterval = time.Minute
+
+// OIDCConfig - Konfigurasi client OIDC (authorization code + PKCE)
+type OIDCConfig struct {
+	IssuerURL    string // Issuer identity provider, discovery di <issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string   // Kosong untuk public client (hanya PKCE)
+	RedirectURL  string   // Callback yang terdaftar di identity provider
+	Scopes       []string // Minimal "openid"
+}
+
+// oidcDiscovery - Bagian dokumen discovery yang dipakai
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcIDTokenClaims - Claim ID token yang diverifikasi
+type oidcIDTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// OIDCClient - Client OpenID Connect untuk login SSO kampus.
+// Dokumen discovery dan JWKS diambil saat pertama dipakai lalu di-cache.
+type OIDCClient struct {
+	Config     OIDCConfig
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+func NewOIDCClient(cfg OIDCConfig, httpClient *http.Client) *OIDCClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	cfg.IssuerURL = strings.TrimSuffix(cfg.IssuerURL, "/")
+
+	return &OIDCClient{
+		Config:     cfg,
+		HTTPClient: httpClient,
+	}
+}
+
+// PKCEChallenge - Code challenge S256 dari code verifier (RFC 7636)
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL - URL authorization endpoint untuk redirect browser user
+func (c *OIDCClient) AuthCodeURL(state, nonce, codeVerifier string) (string, error) {
+	discovery, err := c.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.Config.ClientID)
+	params.Set("redirect_uri", c.Config.RedirectURL)
+	params.Set("scope", strings.Join(c.Config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", PKCEChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange - Tukar authorization code dengan token, lalu verifikasi ID token (signature, issuer, audience, expiry, nonce)
+func (c *OIDCClient) Exchange(code, codeVerifier, nonce string) (*model.OIDCIdentity, error) {
+	discovery, err := c.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.Config.RedirectURL)
+	form.Set("client_id", c.Config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.New("failed to build token request: " + err.Error())
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.Config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.Config.ClientID), url.QueryEscape(c.Config.ClientSecret))
+	}
+
+	var tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.doJSON(req, &tokenResponse)
+	if err != nil {
+		return nil, errors.New("failed to exchange authorization code: " + err.Error())
+	}
+	if status != http.StatusOK {
+		if tokenResponse.Error != "" {
+			return nil, fmt.Errorf("identity provider rejected authorization code: %s %s", tokenResponse.Error, tokenResponse.ErrorDescription)
+		}
+		return nil, fmt.Errorf("identity provider returned status %d", status)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("identity provider did not return an ID token")
+	}
+
+	return c.verifyIDToken(discovery, tokenResponse.IDToken, nonce)
+}
+
+// verifyIDToken - Validasi ID token sesuai OpenID Connect Core 3.1.3.7
+func (c *OIDCClient) verifyIDToken(discovery *oidcDiscovery, rawIDToken, nonce string) (*model.OIDCIdentity, error) {
+	claims := &oidcIDTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, c.keyfunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(c.Config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCTokenInvalid, err)
+	}
+
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCTokenInvalid)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrOIDCTokenInvalid)
+	}
+
+	return &model.OIDCIdentity{
+		Issuer:            claims.Issuer,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// keyfunc - Pilih public key identity provider berdasarkan kid, fetch ulang JWKS jika kid belum dikenal (rotasi key)
+func (c *OIDCClient) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if c.keys != nil && time.Since(c.keysFetched) < oidcJWKSRefreshInterval {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if err := c.fetchKeys(); err != nil {
+		return nil, err
+	}
+
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookupKey - Cari key berdasarkan kid; token tanpa kid hanya diterima jika JWKS berisi satu key
+func (c *OIDCClient) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(c.keys) == 1 {
+			for _, key := range c.keys {
+				return key, true
+			}
+		}
+		return nil, false
+	}
+
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// fetchKeys - Ambil JWKS identity provider, dipanggil dengan c.mu terkunci
+func (c *OIDCClient) fetchKeys() error {
+	discovery, err := c.discoveryLocked()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return errors.New("failed to build JWKS request: " + err.Error())
+	}
+
+	var jwks model.JWKS
+	status, err := c.doJSON(req, &jwks)
+	if err != nil {
+		return errors.New("failed to fetch identity provider keys: " + err.Error())
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("identity provider keys returned status %d", status)
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parsePublicJWK(jwk)
+		if err != nil {
+			continue // Key dengan tipe yang tidak didukung dilewati
+		}
+		keys[jwk.KID] = key
+	}
+
+	c.keys = keys
+	c.keysFetched = time.Now()
+	return nil
+}
+
+// getDiscovery - Dokumen discovery (di-cache setelah berhasil diambil)
+func (c *OIDCClient) getDiscovery() (*oidcDiscovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.discoveryLocked()
+}
+
+func (c *OIDCClient) discoveryLocked() (*oidcDiscovery, error) {
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.Config.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, errors.New("failed to build discovery request: " + err.Error())
+	}
+
+	var discovery oidcDiscovery
+	status, err := c.doJSON(req, &discovery)
+	if err != nil {
+		return nil, errors.New("failed to fetch OIDC discovery document: " + err.Error())
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery returned status %d", status)
+	}
+
+	// Issuer di dokumen harus sama persis dengan yang dikonfigurasi (OpenID Connect Discovery 4.3)
+	if strings.TrimSuffix(discovery.Issuer, "/") != c.Config.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery issuer %q does not match configured issuer", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+
+	c.discovery = &discovery
+	return c.discovery, nil
+}
+
+// doJSON - Kirim request dan decode body JSON, return status code
+func (c *OIDCClient) doJSON(req *http.Request, out interface{}) (int, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOIDCResponseSize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, errors.New("invalid JSON response: " + err.Error())
+	}
+
+	return resp.StatusCode, nil
+}
+
+// parsePublicJWK - Konversi JWK (RSA, EC, Ed25519) menjadi public key untuk verifikasi signature
+func parsePublicJWK(jwk model.JWK) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC point is not on curve")
+		}
+		return key, nil
+
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
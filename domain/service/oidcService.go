@@ -0,0 +1,265 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOIDCDisabled         = errors.New("OIDC login is not configured")
+	ErrInvalidOIDCState     = errors.New("invalid or expired OIDC login state")
+	ErrOIDCEmailNotVerified = errors.New("identity provider did not return a verified email")
+	ErrOIDCUserNotFound     = errors.New("no account is linked to this identity")
+)
+
+// Batas panjang kolom users.username (VARCHAR(50)), sisakan ruang untuk suffix angka
+const maxOIDCUsernameBase = 45
+
+// OIDCService - Login SSO lewat identity provider kampus (authorization code + PKCE).
+// Akun IdP dicocokkan ke user lokal lewat user_identities (issuer + subject), lalu lewat email
+// yang sudah diverifikasi IdP, atau dibuat baru dengan role default jika auto provisioning aktif.
+// Setelah itu login berjalan seperti biasa: cek MFA, buat sesi, terbitkan JWT.
+type OIDCService struct {
+	Client        *OIDCClient
+	Repo          *repository.OIDCRepository
+	UserRepo      *repository.UserRepository
+	Auth          *AuthService
+	DefaultRole   string        // Role untuk user hasil provisioning, mis. "student"
+	AutoProvision bool          // false = hanya user yang sudah ada yang bisa login via SSO
+	StateTTL      time.Duration // Batas waktu dari redirect ke IdP sampai callback
+}
+
+func NewOIDCService(client *OIDCClient, repo *repository.OIDCRepository, userRepo *repository.UserRepository, auth *AuthService, defaultRole string, autoProvision bool, stateTTL time.Duration) *OIDCService {
+	return &OIDCService{
+		Client:        client,
+		Repo:          repo,
+		UserRepo:      userRepo,
+		Auth:          auth,
+		DefaultRole:   defaultRole,
+		AutoProvision: autoProvision,
+		StateTTL:      stateTTL,
+	}
+}
+
+// Enabled - False jika OIDC tidak dikonfigurasi (OIDC_ISSUER_URL kosong)
+func (s *OIDCService) Enabled() bool {
+	return s != nil && s.Client != nil
+}
+
+// BeginLogin - Buat state, nonce, dan PKCE verifier lalu return URL redirect ke identity provider
+func (s *OIDCService) BeginLogin() (string, error) {
+	if !s.Enabled() {
+		return "", ErrOIDCDisabled
+	}
+
+	state, err := generateOpaqueToken()
+	if err != nil {
+		return "", errors.New("failed to generate state: " + err.Error())
+	}
+	nonce, err := generateOpaqueToken()
+	if err != nil {
+		return "", errors.New("failed to generate nonce: " + err.Error())
+	}
+	// 32 byte base64url = 43 karakter, panjang minimal code verifier RFC 7636
+	verifier, err := generateOpaqueToken()
+	if err != nil {
+		return "", errors.New("failed to generate code verifier: " + err.Error())
+	}
+
+	authURL, err := s.Client.AuthCodeURL(state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.Repo.CreateAuthRequest(&model.OIDCAuthRequest{
+		ID:           uuid.New(),
+		StateHash:    hashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.StateTTL),
+	})
+	if err != nil {
+		return "", errors.New("failed to save OIDC login state: " + err.Error())
+	}
+
+	return authURL, nil
+}
+
+// CompleteLogin - Callback dari identity provider: tukar code, petakan ke user lokal, lalu login
+func (s *OIDCService) CompleteLogin(state, code string, client model.ClientInfo) (*model.LoginResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrOIDCDisabled
+	}
+	if state == "" || code == "" {
+		return nil, ErrInvalidOIDCState
+	}
+
+	// 1. State hanya bisa dipakai sekali
+	req, err := s.Repo.ConsumeAuthRequest(hashToken(state))
+	if err != nil || time.Now().After(req.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	// 2. Tukar code dengan ID token (PKCE verifier dan nonce dari state)
+	identity, err := s.Client.Exchange(code, req.CodeVerifier, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Petakan ke user lokal
+	user, err := s.resolveUser(identity)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	// 4. Sama seperti login password: MFA dulu jika dipakai atau diwajibkan role
+	challenge, err := s.Auth.mfaChallengeFor(user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &model.LoginResponse{User: *user, MFA: challenge}, nil
+	}
+
+	return s.Auth.issueLoginTokens(user, client)
+}
+
+// resolveUser - Cari user untuk identity IdP, hubungkan atau buat baru jika belum ada
+func (s *OIDCService) resolveUser(identity *model.OIDCIdentity) (*model.Users, error) {
+	linked, err := s.Repo.GetIdentity(identity.Issuer, identity.Subject)
+	if err == nil {
+		if err := s.Repo.TouchIdentity(linked.ID, identity.Email); err != nil {
+			log.Printf("Failed to update OIDC identity: %v", err)
+		}
+
+		user, err := s.UserRepo.GetUserByID(linked.UserID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		return user, nil
+	}
+
+	// Belum terhubung: email hanya dipercaya jika sudah diverifikasi oleh IdP
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	newIdentity := &model.UserIdentity{
+		ID:      uuid.New(),
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   identity.Email,
+	}
+
+	user, err := s.UserRepo.GetUserByEmail(identity.Email)
+	if err == nil {
+		newIdentity.UserID = user.ID
+		if err := s.Repo.CreateIdentity(newIdentity); err != nil {
+			return nil, errors.New("failed to link identity: " + err.Error())
+		}
+		return user, nil
+	}
+
+	if !s.AutoProvision {
+		return nil, ErrOIDCUserNotFound
+	}
+
+	return s.provisionUser(identity, newIdentity)
+}
+
+// provisionUser - Buat user baru dengan role default. Password diisi acak sehingga login hanya lewat SSO
+// sampai user memakai lupa password.
+func (s *OIDCService) provisionUser(identity *model.OIDCIdentity, newIdentity *model.UserIdentity) (*model.Users, error) {
+	if utf8.RuneCountInString(identity.Email) > maxEmailLength {
+		return nil, errors.New("email from identity provider is too long")
+	}
+
+	roleID, err := s.Repo.GetRoleIDByName(s.DefaultRole)
+	if err != nil {
+		return nil, errors.New("failed to get default role: " + err.Error())
+	}
+
+	username, err := s.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate password: " + err.Error())
+	}
+	passwordHash, err := HashPassword(randomPassword)
+	if err != nil {
+		return nil, errors.New("failed to hash password: " + err.Error())
+	}
+
+	fullName := strings.TrimSpace(identity.Name)
+	if fullName == "" {
+		fullName = username
+	}
+	if utf8.RuneCountInString(fullName) > maxFullNameLength {
+		fullName = string([]rune(fullName)[:maxFullNameLength])
+	}
+
+	user := &model.Users{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        identity.Email,
+		PasswordHash: passwordHash,
+		FullName:     fullName,
+		RoleID:       roleID,
+		IsActive:     true,
+	}
+
+	if err := s.Repo.CreateUserWithIdentity(user, newIdentity); err != nil {
+		return nil, errors.New("failed to create user: " + err.Error())
+	}
+
+	log.Printf("Provisioned user %s from OIDC subject %s", user.Username, identity.Subject)
+	return user, nil
+}
+
+// availableUsername - Username dari preferred_username atau bagian depan email, diberi suffix angka jika sudah dipakai
+func (s *OIDCService) availableUsername(identity *model.OIDCIdentity) (string, error) {
+	source := identity.PreferredUsername
+	if source == "" {
+		source = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(source) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+		if b.Len() >= maxOIDCUsernameBase {
+			break
+		}
+	}
+	base := b.String()
+	if base == "" {
+		base = "user"
+	}
+
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = base + strconv.Itoa(i)
+		}
+		if _, err := s.UserRepo.GetUserByUsername(candidate); err != nil {
+			return candidate, nil
+		}
+	}
+
+	return "", errors.New("failed to find an available username")
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Panjang minimal username / bagian lokal email yang dicek di dalam password,
+// agar nama pendek seperti "al" tidak membuat banyak password ditolak
+const minIdentityFragment = 3
+
+type PasswordPolicy struct {
+	MinLength      int // Jumlah karakter minimal
+	MinCharClasses int // Jumlah minimal jenis karakter: huruf kecil, huruf besar, angka, simbol
+	HistorySize    int // Jumlah password terakhir yang tidak boleh dipakai ulang, 0 untuk nonaktif
+}
+
+// DefaultPasswordPolicy - Minimal 10 karakter dengan 3 jenis karakter, tidak boleh sama dengan 5 password terakhir
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:      10,
+		MinCharClasses: 3,
+		HistorySize:    5,
+	}
+}
+
+// PasswordPolicyError - Password ditolak, Violations berisi semua alasan agar bisa ditampilkan sekaligus
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the password policy: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate - Cek aturan password yang tidak butuh database. Return *PasswordPolicyError jika ada pelanggaran.
+func (p PasswordPolicy) Validate(password, username, email string) error {
+	violations := p.violations(password, username, email)
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p PasswordPolicy) violations(password, username, email string) []string {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	if countCharClasses(password) < p.MinCharClasses {
+		violations = append(violations, fmt.Sprintf("must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", p.MinCharClasses))
+	}
+
+	lower := strings.ToLower(password)
+	if containsFragment(lower, username) {
+		violations = append(violations, "must not contain the username")
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	if containsFragment(lower, localPart) {
+		violations = append(violations, "must not contain the email address")
+	}
+
+	return violations
+}
+
+// countCharClasses - Hitung jenis karakter yang dipakai (huruf kecil, huruf besar, angka, simbol)
+func countCharClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// containsFragment - Cek apakah password (huruf kecil) memuat fragment, tanpa membedakan huruf besar/kecil
+func containsFragment(lowerPassword, fragment string) bool {
+	fragment = strings.ToLower(strings.TrimSpace(fragment))
+	if utf8.RuneCountInString(fragment) < minIdentityFragment {
+		return false
+	}
+	return strings.Contains(lowerPassword, fragment)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+type PasswordResetService struct {
+	AuthRepo  *repository.AuthRepository
+	ResetRepo *repository.PasswordResetRepository
+	TokenRepo *repository.TokenRepository
+	Mail      *MailService
+	Passwords *PasswordService
+	TokenTTL  time.Duration
+	ResetURL  string // URL halaman reset password di frontend, token ditambahkan sebagai query ?token=
+}
+
+func NewPasswordResetService(authRepo *repository.AuthRepository, resetRepo *repository.PasswordResetRepository, tokenRepo *repository.TokenRepository, mail *MailService, passwords *PasswordService, tokenTTL time.Duration, resetURL string) *PasswordResetService {
+	return &PasswordResetService{
+		AuthRepo:  authRepo,
+		ResetRepo: resetRepo,
+		TokenRepo: tokenRepo,
+		Mail:      mail,
+		Passwords: passwords,
+		TokenTTL:  tokenTTL,
+		ResetURL:  resetURL,
+	}
+}
+
+// ForgotPassword - Buat token reset dan kirim link reset ke email user.
+// Email yang tidak terdaftar atau user tidak aktif tetap return nil supaya
+// endpoint tidak bisa dipakai untuk menebak email yang terdaftar.
+func (s *PasswordResetService) ForgotPassword(email string) error {
+	email = strings.TrimSpace(email)
+
+	// 1. Cari user berdasarkan email
+	user, err := s.AuthRepo.GetUserByIdentifier(email)
+	if err != nil || user == nil || !strings.EqualFold(user.Email, email) || !user.IsActive {
+		return nil
+	}
+
+	// Password user LDAP dikelola direktori, reset lewat layanan direktori
+	if source, err := s.AuthRepo.GetAuthSource(user.ID); err != nil || source != AuthSourceLocal {
+		return nil
+	}
+
+	// 2. Generate token opaque, hanya hash yang disimpan
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return errors.New("failed to generate reset token")
+	}
+
+	token := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(s.TokenTTL),
+	}
+
+	if err := s.ResetRepo.CreateResetToken(token); err != nil {
+		return errors.New("failed to create reset token: " + err.Error())
+	}
+
+	// 3. Enqueue email berisi link reset
+	body := fmt.Sprintf(
+		"Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda.\n"+
+			"Buka link berikut untuk membuat password baru (berlaku %d menit, hanya bisa dipakai sekali):\n\n%s?token=%s\n\n"+
+			"Jika Anda tidak meminta reset password, abaikan email ini.\n",
+		user.FullName, int(s.TokenTTL.Minutes()), s.ResetURL, raw,
+	)
+
+	return s.Mail.Enqueue(user.Email, "Reset Password", body)
+}
+
+// ResetPassword - Ganti password dengan token reset, lalu cabut semua sesi user
+func (s *PasswordResetService) ResetPassword(rawToken, newPassword string) error {
+	// 1. Cari token berdasarkan hash
+	token, err := s.ResetRepo.GetResetTokenByHash(hashToken(rawToken))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	// 2. Cek status user
+	user, err := s.AuthRepo.GetUserByID(token.UserID)
+	if err != nil || !user.IsActive {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.Passwords.CheckNewPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	// 3. Pakai token dan ganti password (atomic, token hanya berlaku sekali)
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	ok, err := s.ResetRepo.ResetPassword(token.ID, user.ID, hashedPassword)
+	if err != nil {
+		return errors.New("failed to reset password: " + err.Error())
+	}
+	if !ok {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.Passwords.RecordPassword(user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to record password history for user %s: %v", user.ID, err)
+	}
+
+	// 4. Cabut semua access token dan refresh token yang sudah terbit
+	if err := s.TokenRepo.RevokeAllUserTokens(user.ID); err != nil {
+		return errors.New("failed to revoke sessions: " + err.Error())
+	}
+
+	return nil
+}
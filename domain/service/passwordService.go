@@ -0,0 +1,92 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// PasswordService - Terapkan password policy, cek password bocor, dan riwayat password
+// untuk semua jalur yang mengganti password (buat user, update oleh admin, reset, ganti sendiri)
+type PasswordService struct {
+	Policy      PasswordPolicy
+	Breached    *BreachedPasswordChecker
+	HistoryRepo *repository.PasswordHistoryRepository
+}
+
+func NewPasswordService(policy PasswordPolicy, breached *BreachedPasswordChecker, historyRepo *repository.PasswordHistoryRepository) *PasswordService {
+	return &PasswordService{
+		Policy:      policy,
+		Breached:    breached,
+		HistoryRepo: historyRepo,
+	}
+}
+
+// CheckNewPassword - Validasi password baru untuk user. user.ID boleh uuid.Nil untuk user yang belum dibuat.
+// Return *PasswordPolicyError jika password ditolak.
+func (s *PasswordService) CheckNewPassword(user *model.Users, password string) error {
+	violations := s.Policy.violations(password, user.Username, user.Email)
+
+	breached, err := s.Breached.IsBreached(password)
+	if err != nil {
+		return errors.New("failed to check breached passwords: " + err.Error())
+	}
+	if breached {
+		violations = append(violations, "has appeared in a known data breach")
+	}
+
+	// Cek riwayat hanya jika lolos aturan lain, karena bcrypt per hash cukup mahal
+	if len(violations) == 0 && user.ID != uuid.Nil {
+		reused, err := s.isReused(user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, "must not be one of your recent passwords")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// RecordPassword - Simpan hash password yang baru dipakai ke riwayat
+func (s *PasswordService) RecordPassword(userID uuid.UUID, passwordHash string) error {
+	if s.Policy.HistorySize <= 0 {
+		return nil
+	}
+
+	if err := s.HistoryRepo.AddPasswordHistory(userID, passwordHash, s.Policy.HistorySize); err != nil {
+		return errors.New("failed to save password history: " + err.Error())
+	}
+	return nil
+}
+
+// isReused - Bandingkan dengan password saat ini dan riwayat password user
+func (s *PasswordService) isReused(user *model.Users, password string) (bool, error) {
+	if s.Policy.HistorySize <= 0 {
+		return false, nil
+	}
+
+	hashes, err := s.HistoryRepo.GetRecentPasswordHashes(user.ID, s.Policy.HistorySize)
+	if err != nil {
+		return false, errors.New("failed to load password history: " + err.Error())
+	}
+
+	// User lama belum punya riwayat, password saat ini tetap tidak boleh dipakai ulang
+	if user.PasswordHash != "" && (len(hashes) == 0 || hashes[0] != user.PasswordHash) {
+		hashes = append(hashes, user.PasswordHash)
+	}
+
+	for _, hash := range hashes {
+		if CheckPassword(hash, password) == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
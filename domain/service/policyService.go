@@ -0,0 +1,121 @@
+package service
+
+import (
+	"UAS_BACKEND/domain/middleware/policy"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrPolicyResourceNotFound = errors.New("resource not found")
+
+// PolicyService - Memuat subject (user beserta role dan profil mahasiswa/dosen) dan resource
+// dari database untuk dievaluasi dengan policy di domain/middleware/policy
+type PolicyService struct {
+	Repo        *repository.AchievementRepository
+	Delegations *repository.DelegationRepository
+	RBAC        *RBACService
+}
+
+func NewPolicyService(repo *repository.AchievementRepository, delegationRepo *repository.DelegationRepository, rbac *RBACService) *PolicyService {
+	return &PolicyService{
+		Repo:        repo,
+		Delegations: delegationRepo,
+		RBAC:        rbac,
+	}
+}
+
+// Subject - User yang meminta akses. permissions diambil dari token (personal access token bisa
+// lebih sempit dari role), role dari state RBAC terbaru.
+func (s *PolicyService) Subject(userID uuid.UUID, permissions []string) (*policy.Subject, error) {
+	state, err := s.RBAC.GetPermissionState(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &policy.Subject{
+		UserID:      userID,
+		Roles:       state.RoleNames,
+		Permissions: permissions,
+		AdminScope:  state.AdminScope,
+	}
+
+	// User tanpa profil mahasiswa/dosen tetap valid (mis. admin), kondisi owner/advisor tidak terpenuhi
+	if student, err := s.Repo.GetStudentByUserID(userID); err == nil {
+		sub.Student = student
+	}
+	if lecturer, err := s.Repo.GetLecturerByUserID(userID); err == nil {
+		sub.Lecturer = lecturer
+
+		delegations, err := s.Delegations.GetActiveDelegationsForDelegate(lecturer.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		sub.Delegations = delegations
+	}
+
+	return sub, nil
+}
+
+// StudentResource - Resource mahasiswa berdasarkan user ID-nya (param :id di /api/v1/students)
+func (s *PolicyService) StudentResource(userID uuid.UUID) (*policy.Resource, error) {
+	student, err := s.Repo.GetStudentByUserID(userID)
+	if err != nil {
+		return nil, ErrPolicyResourceNotFound
+	}
+
+	return &policy.Resource{
+		Type:    policy.ResourceStudent,
+		ID:      student.ID,
+		OwnerID: student.ID,
+		Owner:   student,
+	}, nil
+}
+
+// AchievementResource - Resource prestasi berdasarkan ID achievement reference
+func (s *PolicyService) AchievementResource(referenceID uuid.UUID) (*policy.Resource, error) {
+	reference, err := s.Repo.GetAchievementReferenceByID(referenceID)
+	if err != nil {
+		return nil, ErrPolicyResourceNotFound
+	}
+
+	owner, err := s.Repo.GetStudentByID(reference.StudentID)
+	if err != nil {
+		return nil, ErrPolicyResourceNotFound
+	}
+
+	return &policy.Resource{
+		Type:    policy.ResourceAchievement,
+		ID:      reference.ID,
+		OwnerID: owner.ID,
+		Owner:   owner,
+	}, nil
+}
+
+// UserResource - Resource user berdasarkan user ID (param :id di /api/admin/users), program studinya
+// diambil dari profil mahasiswa dan department dosen. User admin tidak diberi program studi
+// sehingga hanya bisa dikelola admin global.
+func (s *PolicyService) UserResource(userID uuid.UUID) (*policy.Resource, error) {
+	if _, err := s.Repo.GetUserByID(userID); err != nil {
+		return nil, ErrPolicyResourceNotFound
+	}
+
+	res := &policy.Resource{
+		Type: policy.ResourceUser,
+		ID:   userID,
+	}
+	if isAdmin, err := s.RBAC.UserHasRole(userID, adminRoleName); err != nil || isAdmin {
+		return res, nil
+	}
+	if student, err := s.Repo.GetStudentByUserID(userID); err == nil {
+		res.OwnerID = student.ID
+		res.Owner = student
+	}
+	if lecturer, err := s.Repo.GetLecturerByUserID(userID); err == nil {
+		res.ProgramStudies = append(res.ProgramStudies, lecturer.Department)
+	}
+
+	return res, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware/policy"
 	"UAS_BACKEND/domain/repository"
 	"errors"
 	"sync"
@@ -20,7 +21,7 @@ type RBACService struct {
 func NewRBACService(repo *repository.RBACRepository) *RBACService {
 	return &RBACService{
 		Repo:  repo,
-		Cache: NewPermissionCache(5 * time.Minute), // Cache 5 menit
+		Cache: NewPermissionCache(30 * time.Second), // Cache 30 detik, perubahan lewat API langsung di-invalidate
 	}
 }
 
@@ -29,41 +30,114 @@ func (s *RBACService) GetRoleByID(roleID uuid.UUID) (*model.Roles, error) {
 	return s.Repo.GetRoleByID(roleID)
 }
 
-// GetUserPermissions - Mendapatkan permissions user (dengan cache)
-func (s *RBACService) GetUserPermissions(userID uuid.UUID, roleID uuid.UUID) ([]string, error) {
-	// 3. Load user permissions dari cache/database
-	
+// GetPermissionState - Mendapatkan role, status aktif, versi, dan permissions user (dengan cache)
+func (s *RBACService) GetPermissionState(userID uuid.UUID) (*model.PermissionState, error) {
 	// Cek cache dulu
 	if cached, found := s.Cache.Get(userID); found {
 		return cached, nil
 	}
 
 	// Jika tidak ada di cache, ambil dari database
-	permissions, err := s.Repo.GetUserPermissions(roleID)
+	state, err := s.Repo.GetPermissionState(userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Simpan ke cache
-	s.Cache.Set(userID, permissions)
+	s.Cache.Set(userID, state)
 
-	return permissions, nil
+	return state, nil
 }
 
-// UserHasPermission - Check apakah user memiliki permission tertentu
-func (s *RBACService) UserHasPermission(userID uuid.UUID, roleID uuid.UUID, permName string) (bool, error) {
-	permissions, err := s.GetUserPermissions(userID, roleID)
+// GetUserPermissions - Mendapatkan permissions user (dengan cache)
+func (s *RBACService) GetUserPermissions(userID uuid.UUID) ([]string, error) {
+	state, err := s.GetPermissionState(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return state.Permissions, nil
+}
+
+// UserHasPermission - Check apakah user memiliki permission tertentu (termasuk wildcard dan hierarki)
+func (s *RBACService) UserHasPermission(userID uuid.UUID, permName string) (bool, error) {
+	permissions, err := s.GetUserPermissions(userID)
 	if err != nil {
 		return false, err
 	}
 
-	for _, perm := range permissions {
-		if perm == permName {
-			return true, nil
+	return policy.PermissionGranted(permissions, permName), nil
+}
+
+// AdminScope - Scope program studi admin (dengan cache). Scope kosong = admin global.
+func (s *RBACService) AdminScope(userID uuid.UUID) (*model.ProgramStudyScope, error) {
+	state, err := s.GetPermissionState(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ProgramStudyScope{ProgramStudies: state.AdminScope}, nil
+}
+
+// UserHasRole - Check apakah user memiliki salah satu role (role utama maupun tambahan)
+func (s *RBACService) UserHasRole(userID uuid.UUID, roleNames ...string) (bool, error) {
+	state, err := s.GetPermissionState(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return hasRole(state, roleNames...), nil
+}
+
+// RoleGrants - Check apakah permission role mencakup permName (termasuk wildcard dan hierarki)
+func (s *RBACService) RoleGrants(roleID uuid.UUID, permName string) (bool, error) {
+	permissions, err := s.Repo.GetRolePermissions(roleID)
+	if err != nil {
+		return false, err
+	}
+
+	names := make([]string, len(permissions))
+	for i, perm := range permissions {
+		names[i] = perm.Name
+	}
+	return policy.PermissionGranted(names, permName), nil
+}
+
+// hasRole - Check apakah salah satu role di state ada di roleNames
+func hasRole(state *model.PermissionState, roleNames ...string) bool {
+	for _, owned := range state.RoleNames {
+		for _, name := range roleNames {
+			if owned == name {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// RefreshClaims - Cocokkan versi permission di token dengan state RBAC terbaru.
+// Jika role, versi user, atau versi role berubah sejak token diterbitkan, role dan permissions di claims
+// diganti dengan hasil resolve terbaru. ErrUserInactive jika user sudah dinonaktifkan.
+func (s *RBACService) RefreshClaims(claims *model.CustomClaims) error {
+	state, err := s.GetPermissionState(claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !state.IsActive {
+		return ErrUserInactive
+	}
+
+	if claims.RoleID != state.RoleID ||
+		claims.UserPermVersion != state.UserVersion ||
+		claims.RolePermVersion != state.RoleVersion {
+		claims.RoleID = state.RoleID
+		claims.Permissions = state.Permissions
+		claims.UserPermVersion = state.UserVersion
+		claims.RolePermVersion = state.RoleVersion
+	}
 
-	return false, nil
+	return nil
 }
 
 // InvalidateCache - Hapus cache permissions untuk user tertentu
@@ -71,6 +145,11 @@ func (s *RBACService) InvalidateCache(userID uuid.UUID) {
 	s.Cache.Delete(userID)
 }
 
+// InvalidateRole - Hapus cache permissions semua user yang memiliki role tertentu
+func (s *RBACService) InvalidateRole(roleID uuid.UUID) {
+	s.Cache.DeleteRole(roleID)
+}
+
 // InvalidateAllCache - Hapus semua cache
 func (s *RBACService) InvalidateAllCache() {
 	s.Cache.Clear()
@@ -84,8 +163,8 @@ type PermissionCache struct {
 }
 
 type cacheEntry struct {
-	permissions []string
-	expiry      time.Time
+	state  *model.PermissionState
+	expiry time.Time
 }
 
 func NewPermissionCache(ttl time.Duration) *PermissionCache {
@@ -100,7 +179,7 @@ func NewPermissionCache(ttl time.Duration) *PermissionCache {
 	return cache
 }
 
-func (c *PermissionCache) Get(userID uuid.UUID) ([]string, bool) {
+func (c *PermissionCache) Get(userID uuid.UUID) (*model.PermissionState, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
@@ -114,16 +193,16 @@ func (c *PermissionCache) Get(userID uuid.UUID) ([]string, bool) {
 		return nil, false
 	}
 
-	return entry.permissions, true
+	return entry.state, true
 }
 
-func (c *PermissionCache) Set(userID uuid.UUID, permissions []string) {
+func (c *PermissionCache) Set(userID uuid.UUID, state *model.PermissionState) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	c.data[userID] = cacheEntry{
-		permissions: permissions,
-		expiry:      time.Now().Add(c.ttl),
+		state:  state,
+		expiry: time.Now().Add(c.ttl),
 	}
 }
 
@@ -134,6 +213,25 @@ func (c *PermissionCache) Delete(userID uuid.UUID) {
 	delete(c.data, userID)
 }
 
+// DeleteRole - Hapus entry semua user yang memiliki roleID (role utama maupun tambahan)
+func (c *PermissionCache) DeleteRole(roleID uuid.UUID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for userID, entry := range c.data {
+		if entry.state.RoleID == roleID {
+			delete(c.data, userID)
+			continue
+		}
+		for _, id := range entry.state.RoleIDs {
+			if id == roleID {
+				delete(c.data, userID)
+				break
+			}
+		}
+	}
+}
+
 func (c *PermissionCache) Clear() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -0,0 +1,314 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleExists         = errors.New("role name already exists")
+	ErrRoleInUse          = errors.New("role is still assigned to users")
+	ErrProtectedRole      = errors.New("the admin role cannot be renamed or deleted")
+	ErrPermissionNotFound = errors.New("permission not found")
+	ErrPermissionExists   = errors.New("permission already exists")
+	ErrPermissionNotHeld  = errors.New("role does not have this permission")
+	ErrLastAdmin          = errors.New("cannot remove the last active admin")
+	ErrPrimaryRole        = errors.New("primary role cannot be removed, assign another primary role instead")
+	ErrRoleNotAssigned    = errors.New("user does not have this role")
+)
+
+// adminRoleName - Role yang membuka /api/admin (RequireRole("admin")), tidak boleh diganti nama atau dihapus
+const adminRoleName = "admin"
+
+var (
+	roleNamePattern          = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+	permissionSegmentPattern = regexp.MustCompile(`^([a-z][a-z0-9_]{0,49}|\*)$`) // "*" = wildcard ("achievement.*")
+)
+
+// RoleService - Kelola role, permission, dan mapping role_permissions.
+// Setiap perubahan langsung meng-invalidate cache permission user yang terdampak; token yang sudah terbit
+// di-resolve ulang lewat permission_version role (dinaikkan trigger role_permissions).
+type RoleService struct {
+	Repo *repository.RBACRepository
+	RBAC *RBACService
+}
+
+func NewRoleService(repo *repository.RBACRepository, rbac *RBACService) *RoleService {
+	return &RoleService{
+		Repo: repo,
+		RBAC: rbac,
+	}
+}
+
+// GetRole - Role beserta permissions dan jumlah user
+func (s *RoleService) GetRole(roleID uuid.UUID) (*model.RoleDetail, error) {
+	role, err := s.Repo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	permissions, err := s.Repo.GetRolePermissions(roleID)
+	if err != nil {
+		return nil, errors.New("failed to get role permissions: " + err.Error())
+	}
+	if permissions == nil {
+		permissions = []model.Permission{}
+	}
+
+	userCount, err := s.Repo.CountRoleUsers(roleID)
+	if err != nil {
+		return nil, errors.New("failed to count role users: " + err.Error())
+	}
+
+	return &model.RoleDetail{
+		Roles:       *role,
+		Permissions: permissions,
+		UserCount:   userCount,
+	}, nil
+}
+
+// CreateRole - Buat role baru tanpa permission
+func (s *RoleService) CreateRole(req *model.RoleRequest) (*model.Roles, error) {
+	name := strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New("role name must be 2-50 lowercase letters, digits, '-' or '_'")
+	}
+
+	if existing, _ := s.Repo.GetRoleByName(name); existing != nil {
+		return nil, ErrRoleExists
+	}
+
+	role := &model.Roles{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+	}
+	if req.MFARequired != nil {
+		role.MFARequired = *req.MFARequired
+	}
+
+	if err := s.Repo.CreateRole(role); err != nil {
+		return nil, errors.New("failed to create role: " + err.Error())
+	}
+
+	return role, nil
+}
+
+// UpdateRole - Update name, description, dan mfa_required role
+func (s *RoleService) UpdateRole(roleID uuid.UUID, req *model.RoleRequest) (*model.Roles, error) {
+	role, err := s.Repo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" && name != role.Name {
+		if role.Name == adminRoleName {
+			return nil, ErrProtectedRole
+		}
+		if !roleNamePattern.MatchString(name) {
+			return nil, errors.New("role name must be 2-50 lowercase letters, digits, '-' or '_'")
+		}
+		if existing, _ := s.Repo.GetRoleByName(name); existing != nil {
+			return nil, ErrRoleExists
+		}
+		role.Name = name
+	}
+
+	if req.Description != "" {
+		role.Description = strings.TrimSpace(req.Description)
+	}
+	if req.MFARequired != nil {
+		role.MFARequired = *req.MFARequired
+	}
+
+	if err := s.Repo.UpdateRole(role); err != nil {
+		return nil, errors.New("failed to update role: " + err.Error())
+	}
+
+	return role, nil
+}
+
+// DeleteRole - Hapus role yang sudah tidak dipakai user mana pun
+func (s *RoleService) DeleteRole(roleID uuid.UUID) error {
+	role, err := s.Repo.GetRoleByID(roleID)
+	if err != nil {
+		return ErrRoleNotFound
+	}
+
+	if role.Name == adminRoleName {
+		return ErrProtectedRole
+	}
+
+	deleted, err := s.Repo.DeleteRole(roleID)
+	if err != nil {
+		return errors.New("failed to delete role: " + err.Error())
+	}
+	if !deleted {
+		return ErrRoleInUse
+	}
+	s.RBAC.InvalidateRole(roleID)
+
+	return nil
+}
+
+// GetAllPermissions - Semua permission
+func (s *RoleService) GetAllPermissions() ([]model.Permission, error) {
+	return s.Repo.GetAllPermissions()
+}
+
+// CreatePermission - Buat permission baru, name = "<resource>.<action>"
+func (s *RoleService) CreatePermission(req *model.PermissionRequest) (*model.Permission, error) {
+	perm, err := s.buildPermission(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, _ := s.Repo.GetPermissionByName(perm.Name); existing != nil {
+		return nil, ErrPermissionExists
+	}
+
+	if err := s.Repo.CreatePermission(perm); err != nil {
+		return nil, errors.New("failed to create permission: " + err.Error())
+	}
+
+	return perm, nil
+}
+
+// UpdatePermission - Ganti resource/action/description permission
+func (s *RoleService) UpdatePermission(permissionID uuid.UUID, req *model.PermissionRequest) (*model.Permission, error) {
+	existing, err := s.Repo.GetPermissionByID(permissionID)
+	if err != nil {
+		return nil, ErrPermissionNotFound
+	}
+
+	if req.Resource == "" {
+		req.Resource = existing.Resource
+	}
+	if req.Action == "" {
+		req.Action = existing.Action
+	}
+	if req.Description == "" {
+		req.Description = existing.Description
+	}
+
+	perm, err := s.buildPermission(req)
+	if err != nil {
+		return nil, err
+	}
+	perm.ID = existing.ID
+
+	if perm.Name != existing.Name {
+		if other, _ := s.Repo.GetPermissionByName(perm.Name); other != nil {
+			return nil, ErrPermissionExists
+		}
+	}
+
+	if err := s.Repo.UpdatePermission(perm); err != nil {
+		return nil, errors.New("failed to update permission: " + err.Error())
+	}
+	s.invalidatePermissionRoles(perm.ID)
+
+	return perm, nil
+}
+
+// DeletePermission - Hapus permission dari semua role lalu hapus permission-nya
+func (s *RoleService) DeletePermission(permissionID uuid.UUID) error {
+	if _, err := s.Repo.GetPermissionByID(permissionID); err != nil {
+		return ErrPermissionNotFound
+	}
+
+	// Role pemegang permission diambil sebelum mapping-nya ikut terhapus
+	roleIDs, err := s.Repo.GetPermissionRoleIDs(permissionID)
+	if err != nil {
+		return errors.New("failed to get permission roles: " + err.Error())
+	}
+
+	if err := s.Repo.DeletePermission(permissionID); err != nil {
+		return errors.New("failed to delete permission: " + err.Error())
+	}
+
+	for _, roleID := range roleIDs {
+		s.RBAC.InvalidateRole(roleID)
+	}
+
+	return nil
+}
+
+// AttachPermission - Tambahkan permission ke role (idempotent)
+func (s *RoleService) AttachPermission(roleID, permissionID uuid.UUID) (*model.RoleDetail, error) {
+	if _, err := s.Repo.GetRoleByID(roleID); err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if _, err := s.Repo.GetPermissionByID(permissionID); err != nil {
+		return nil, ErrPermissionNotFound
+	}
+
+	attached, err := s.Repo.AttachPermission(roleID, permissionID)
+	if err != nil {
+		return nil, errors.New("failed to attach permission: " + err.Error())
+	}
+	if attached {
+		s.RBAC.InvalidateRole(roleID)
+	}
+
+	return s.GetRole(roleID)
+}
+
+// DetachPermission - Lepas permission dari role
+func (s *RoleService) DetachPermission(roleID, permissionID uuid.UUID) (*model.RoleDetail, error) {
+	if _, err := s.Repo.GetRoleByID(roleID); err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	detached, err := s.Repo.DetachPermission(roleID, permissionID)
+	if err != nil {
+		return nil, errors.New("failed to detach permission: " + err.Error())
+	}
+	if !detached {
+		return nil, ErrPermissionNotHeld
+	}
+	s.RBAC.InvalidateRole(roleID)
+
+	return s.GetRole(roleID)
+}
+
+// buildPermission - Validasi resource/action dan bentuk nama permission
+func (s *RoleService) buildPermission(req *model.PermissionRequest) (*model.Permission, error) {
+	resource := strings.TrimSpace(req.Resource)
+	action := strings.TrimSpace(req.Action)
+
+	if !permissionSegmentPattern.MatchString(resource) {
+		return nil, errors.New("resource must be 1-50 lowercase letters, digits or '_', or '*'")
+	}
+	if !permissionSegmentPattern.MatchString(action) {
+		return nil, errors.New("action must be 1-50 lowercase letters, digits or '_', or '*'")
+	}
+
+	return &model.Permission{
+		Name:        resource + "." + action,
+		Resource:    resource,
+		Action:      action,
+		Description: strings.TrimSpace(req.Description),
+	}, nil
+}
+
+// invalidatePermissionRoles - Invalidate cache semua role yang memiliki permission
+func (s *RoleService) invalidatePermissionRoles(permissionID uuid.UUID) {
+	roleIDs, err := s.Repo.GetPermissionRoleIDs(permissionID)
+	if err != nil {
+		// Role yang terdampak tidak diketahui, kosongkan semua cache
+		log.Printf("Failed to get roles of permission %s: %v", permissionID, err)
+		s.RBAC.InvalidateAllCache()
+		return
+	}
+
+	for _, roleID := range roleIDs {
+		s.RBAC.InvalidateRole(roleID)
+	}
+}
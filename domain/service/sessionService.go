@@ -0,0 +1,111 @@
+package service
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+)
+
+// Batas panjang user agent yang disimpan, header dari client tidak dipercaya
+const maxUserAgentLength = 255
+
+type SessionService struct {
+	Repo      *repository.SessionRepository
+	TokenRepo *repository.TokenRepository
+}
+
+func NewSessionService(repo *repository.SessionRepository, tokenRepo *repository.TokenRepository) *SessionService {
+	return &SessionService{
+		Repo:      repo,
+		TokenRepo: tokenRepo,
+	}
+}
+
+// CreateSession - Catat sesi baru untuk login yang berhasil
+func (s *SessionService) CreateSession(userID uuid.UUID, client model.ClientInfo, expiresAt time.Time) (*model.UserSession, error) {
+	userAgent := client.UserAgent
+	if utf8.RuneCountInString(userAgent) > maxUserAgentLength {
+		userAgent = string([]rune(userAgent)[:maxUserAgentLength])
+	}
+
+	session := &model.UserSession{
+		ID:        uuid.New(),
+		UserID:    userID,
+		UserAgent: userAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := s.Repo.CreateSession(session); err != nil {
+		return nil, errors.New("failed to create session: " + err.Error())
+	}
+
+	return session, nil
+}
+
+// ExtendSession - Perpanjang sesi saat refresh token dirotasi, ErrSessionRevoked jika sesi sudah dicabut
+func (s *SessionService) ExtendSession(sessionID, userID uuid.UUID, expiresAt time.Time) error {
+	ok, err := s.Repo.ExtendSession(sessionID, userID, expiresAt)
+	if err != nil {
+		return errors.New("failed to extend session: " + err.Error())
+	}
+	if !ok {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// Touch - Perbarui last-seen sesi, ErrSessionRevoked jika sesi sudah dicabut
+func (s *SessionService) Touch(sessionID, userID uuid.UUID) error {
+	ok, err := s.Repo.TouchSession(sessionID, userID)
+	if err != nil {
+		return errors.New("failed to update session: " + err.Error())
+	}
+	if !ok {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// ListSessions - Daftar sesi aktif user, sesi yang sedang dipakai ditandai current
+func (s *SessionService) ListSessions(userID, currentSessionID uuid.UUID) ([]model.UserSession, error) {
+	sessions, err := s.Repo.GetActiveSessions(userID)
+	if err != nil {
+		return nil, errors.New("failed to get sessions: " + err.Error())
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession - Cabut satu sesi milik user (logout perangkat tertentu)
+func (s *SessionService) RevokeSession(userID, sessionID uuid.UUID) error {
+	ok, err := s.Repo.RevokeSession(sessionID, userID)
+	if err != nil {
+		return errors.New("failed to revoke session: " + err.Error())
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllSessions - Logout dari semua perangkat: cabut semua sesi, refresh token, dan access token user
+func (s *SessionService) RevokeAllSessions(userID uuid.UUID) error {
+	if err := s.TokenRepo.RevokeAllUserTokens(userID); err != nil {
+		return errors.New("failed to revoke sessions: " + err.Error())
+	}
+	return nil
+}
@@ -24,6 +24,8 @@ type StatisticsRequest struct {
 	EndDate   *time.Time `json:"end_date,omitempty"`
 	UserID    *uuid.UUID `json:"user_id,omitempty"`    // For student own stats
 	AdvisorID *uuid.UUID `json:"advisor_id,omitempty"` // For lecturer advisee stats
+
+	ProgramStudies []string `json:"-"` // Scope admin ter-scope (huruf kecil), diisi service
 }
 
 // AchievementTypeStats - Statistik per tipe prestasi
@@ -59,11 +61,11 @@ type CompetitionLevelStats struct {
 
 // StatisticsResponse - Response untuk statistics
 type StatisticsResponse struct {
-	TypeStats        []AchievementTypeStats    `json:"type_stats"`
-	PeriodStats      []AchievementPeriodStats  `json:"period_stats"`
-	TopStudents      []TopStudentStats         `json:"top_students"`
-	CompetitionStats []CompetitionLevelStats   `json:"competition_stats"`
-	Summary          *StatisticsSummary        `json:"summary"`
+	TypeStats        []AchievementTypeStats   `json:"type_stats"`
+	PeriodStats      []AchievementPeriodStats `json:"period_stats"`
+	TopStudents      []TopStudentStats        `json:"top_students"`
+	CompetitionStats []CompetitionLevelStats  `json:"competition_stats"`
+	Summary          *StatisticsSummary       `json:"summary"`
 }
 
 // StatisticsSummary - Summary statistics
@@ -103,8 +105,10 @@ func (s *StatisticsService) GetLecturerStatistics(ctx context.Context, userID uu
 	return s.generateStatistics(ctx, req, "lecturer")
 }
 
-// GetAdminStatistics - Get statistics for admin (all achievements)
-func (s *StatisticsService) GetAdminStatistics(ctx context.Context, req *StatisticsRequest) (*StatisticsResponse, error) {
+// GetAdminStatistics - Get statistics for admin (all achievements, atau hanya program studi admin ter-scope)
+func (s *StatisticsService) GetAdminStatistics(ctx context.Context, scope *model.ProgramStudyScope, req *StatisticsRequest) (*StatisticsResponse, error) {
+	req.ProgramStudies = scope.Lowered()
+
 	return s.generateStatistics(ctx, req, "admin")
 }
 
@@ -152,8 +156,8 @@ func (s *StatisticsService) generateStatistics(ctx context.Context, req *Statist
 	}, nil
 }
 
-// GetAchievementTrends - Get achievement trends over time
-func (s *StatisticsService) GetAchievementTrends(ctx context.Context, userID uuid.UUID, role string, months int) (*TrendResponse, error) {
+// GetAchievementTrends - Get achievement trends over time. scope hanya dipakai untuk role admin.
+func (s *StatisticsService) GetAchievementTrends(ctx context.Context, userID uuid.UUID, role string, months int, scope *model.ProgramStudyScope) (*TrendResponse, error) {
 	if months <= 0 {
 		months = 12 // Default 12 months
 	}
@@ -176,7 +180,7 @@ func (s *StatisticsService) GetAchievementTrends(ctx context.Context, userID uui
 		}
 		req = &StatisticsRequest{AdvisorID: &lecturer.ID}
 	case "admin":
-		req = &StatisticsRequest{}
+		req = &StatisticsRequest{ProgramStudies: scope.Lowered()}
 	default:
 		return nil, errors.New("invalid role")
 	}
@@ -194,15 +198,15 @@ func (s *StatisticsService) GetAchievementTrends(ctx context.Context, userID uui
 
 // TrendData - Data untuk trend
 type TrendData struct {
-	Month       string `json:"month"`       // Format: "2024-01"
-	Count       int    `json:"count"`       // Jumlah prestasi
-	Points      float64 `json:"points"`     // Total points
-	Verified    int    `json:"verified"`    // Jumlah verified
-	Submitted   int    `json:"submitted"`   // Jumlah submitted
+	Month     string  `json:"month"`     // Format: "2024-01"
+	Count     int     `json:"count"`     // Jumlah prestasi
+	Points    float64 `json:"points"`    // Total points
+	Verified  int     `json:"verified"`  // Jumlah verified
+	Submitted int     `json:"submitted"` // Jumlah submitted
 }
 
 // TrendResponse - Response untuk trends
 type TrendResponse struct {
 	Trends []TrendData `json:"trends"`
 	Period int         `json:"period"` // Jumlah bulan
-}
\ No newline at end of file
+}
@@ -4,27 +4,43 @@ import (
 	model "UAS_BACKEND/domain/Model"
 	"UAS_BACKEND/domain/repository"
 	"errors"
+	"log"
+	"strings"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrOutOfScope      = errors.New("forbidden: user is outside of your program study scope")
+	ErrScopedAdminRole = errors.New("scoped admins cannot grant the admin role")
+	ErrNotAdmin        = errors.New("program study scope can only be set for admin users")
+)
+
 type UserService struct {
-	Repo *repository.UserRepository
+	Repo      *repository.UserRepository
+	TokenRepo *repository.TokenRepository
+	Passwords *PasswordService
+	RBAC      *RBACService // Cache permission di-invalidate saat role/status user berubah
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
-	return &UserService{Repo: repo}
+func NewUserService(repo *repository.UserRepository, tokenRepo *repository.TokenRepository, passwords *PasswordService, rbac *RBACService) *UserService {
+	return &UserService{
+		Repo:      repo,
+		TokenRepo: tokenRepo,
+		Passwords: passwords,
+		RBAC:      rbac,
+	}
 }
 
 // CreateUserRequest - DTO untuk create user
 type CreateUserRequest struct {
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	Password     string    `json:"password"`
-	FullName     string    `json:"full_name"`
-	RoleID       uuid.UUID `json:"role_id"`
-	IsActive     bool      `json:"is_active"`
+	Username     string                  `json:"username"`
+	Email        string                  `json:"email"`
+	Password     string                  `json:"password"`
+	FullName     string                  `json:"full_name"`
+	RoleID       uuid.UUID               `json:"role_id"`
+	IsActive     bool                    `json:"is_active"`
 	StudentData  *StudentProfileRequest  `json:"student_data,omitempty"`
 	LecturerData *LecturerProfileRequest `json:"lecturer_data,omitempty"`
 }
@@ -55,10 +71,11 @@ type LecturerProfileRequest struct {
 
 // UserResponse - DTO untuk response
 type UserResponse struct {
-	User     model.Users              `json:"user"`
-	Student  *model.Student           `json:"student,omitempty"`
-	Lecturer *model.Lecturer          `json:"lecturer,omitempty"`
-	Role     *model.Roles             `json:"role,omitempty"`
+	User     model.Users     `json:"user"`
+	Student  *model.Student  `json:"student,omitempty"`
+	Lecturer *model.Lecturer `json:"lecturer,omitempty"`
+	Role     *model.Roles    `json:"role,omitempty"`
+	Roles    []model.Roles   `json:"roles,omitempty"` // Semua role user, termasuk role utama
 }
 
 // CreateUser - Flow FR-009: Create user
@@ -68,36 +85,51 @@ func (s *UserService) CreateUser(req *CreateUserRequest) (*UserResponse, error)
 		return nil, err
 	}
 
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	// 1. Create user (password dari admin wajib diganti user saat login pertama)
+	user := &model.Users{
+		ID:                 uuid.New(),
+		Username:           req.Username,
+		Email:              req.Email,
+		PasswordHash:       string(hashedPassword),
+		FullName:           req.FullName,
+		RoleID:             req.RoleID,
+		IsActive:           req.IsActive,
+		MustChangePassword: true,
+	}
+
+	response, err := s.createUserWithProfile(user, req.StudentData, req.LecturerData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Passwords.RecordPassword(user.ID, user.PasswordHash); err != nil {
+		log.Printf("Failed to record password history for user %s: %v", user.ID, err)
+	}
+
+	return response, nil
+}
+
+// createUserWithProfile - Simpan user beserta profil student/lecturer (dipakai create user dan undangan)
+func (s *UserService) createUserWithProfile(user *model.Users, studentData *StudentProfileRequest, lecturerData *LecturerProfileRequest) (*UserResponse, error) {
 	// Check username sudah ada
-	existingUser, _ := s.Repo.GetUserByUsername(req.Username)
+	existingUser, _ := s.Repo.GetUserByUsername(user.Username)
 	if existingUser != nil {
 		return nil, errors.New("username already exists")
 	}
 
 	// Check email sudah ada
-	existingUser, _ = s.Repo.GetUserByEmail(req.Email)
+	existingUser, _ = s.Repo.GetUserByEmail(user.Email)
 	if existingUser != nil {
 		return nil, errors.New("email already exists")
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, errors.New("failed to hash password")
-	}
-
-	// 1. Create user
-	user := &model.Users{
-		ID:           uuid.New(),
-		Username:     req.Username,
-		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
-		FullName:     req.FullName,
-		RoleID:       req.RoleID,
-		IsActive:     req.IsActive,
-	}
-
-	err = s.Repo.CreateUser(user)
+	err := s.Repo.CreateUser(user)
 	if err != nil {
 		return nil, errors.New("failed to create user: " + err.Error())
 	}
@@ -109,14 +141,14 @@ func (s *UserService) CreateUser(req *CreateUserRequest) (*UserResponse, error)
 	var lecturer *model.Lecturer
 
 	// 3. Set student/lecturer profile
-	if req.StudentData != nil {
+	if studentData != nil {
 		student = &model.Student{
 			ID:           uuid.New(),
 			UserID:       user.ID,
-			StudentID:    req.StudentData.StudentID,
-			ProgramStudy: req.StudentData.ProgramStudy,
-			AcademicYear: req.StudentData.AcademicYear,
-			AdvisorID:    req.StudentData.AdvisorID,
+			StudentID:    studentData.StudentID,
+			ProgramStudy: studentData.ProgramStudy,
+			AcademicYear: studentData.AcademicYear,
+			AdvisorID:    studentData.AdvisorID,
 		}
 		err = s.Repo.CreateStudent(student)
 		if err != nil {
@@ -124,12 +156,12 @@ func (s *UserService) CreateUser(req *CreateUserRequest) (*UserResponse, error)
 		}
 	}
 
-	if req.LecturerData != nil {
+	if lecturerData != nil {
 		lecturer = &model.Lecturer{
 			ID:         uuid.New(),
 			UserID:     user.ID,
-			LecturerID: req.LecturerData.LecturerID,
-			Department: req.LecturerData.Department,
+			LecturerID: lecturerData.LecturerID,
+			Department: lecturerData.Department,
 		}
 		err = s.Repo.CreateLecturer(lecturer)
 		if err != nil {
@@ -153,6 +185,21 @@ func (s *UserService) UpdateUser(userID uuid.UUID, req *UpdateUserRequest) (*Use
 		return nil, errors.New("user not found")
 	}
 
+	// Admin aktif terakhir tidak boleh dinonaktifkan atau kehilangan role admin
+	if req.IsActive != nil && !*req.IsActive {
+		if err := s.ensureNotLastAdmin(user, uuid.Nil); err != nil {
+			return nil, err
+		}
+	} else if req.RoleID != uuid.Nil && req.RoleID != user.RoleID {
+		if err := s.ensureNotLastAdmin(user, user.RoleID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Token lama harus dicabut jika akun dinonaktifkan atau password diganti (mis. akun dibobol)
+	wasActive := user.IsActive
+	revokeTokens := false
+
 	// Update fields if provided
 	if req.Username != "" {
 		// Check username conflict
@@ -173,11 +220,18 @@ func (s *UserService) UpdateUser(userID uuid.UUID, req *UpdateUserRequest) (*Use
 	}
 
 	if req.Password != "" {
+		// Dicek terhadap username/email yang baru dan riwayat password user
+		if err := s.Passwords.CheckNewPassword(user, req.Password); err != nil {
+			return nil, err
+		}
+
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return nil, errors.New("failed to hash password")
 		}
 		user.PasswordHash = string(hashedPassword)
+		user.MustChangePassword = true
+		revokeTokens = true
 	}
 
 	if req.FullName != "" {
@@ -190,6 +244,9 @@ func (s *UserService) UpdateUser(userID uuid.UUID, req *UpdateUserRequest) (*Use
 
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
+		if wasActive && !user.IsActive {
+			revokeTokens = true
+		}
 	}
 
 	// Update user
@@ -197,6 +254,19 @@ func (s *UserService) UpdateUser(userID uuid.UUID, req *UpdateUserRequest) (*Use
 	if err != nil {
 		return nil, errors.New("failed to update user: " + err.Error())
 	}
+	s.RBAC.InvalidateCache(userID)
+
+	if req.Password != "" {
+		if err := s.Passwords.RecordPassword(userID, user.PasswordHash); err != nil {
+			log.Printf("Failed to record password history for user %s: %v", userID, err)
+		}
+	}
+
+	if revokeTokens {
+		if err := s.TokenRepo.RevokeAllUserTokens(userID); err != nil {
+			return nil, errors.New("user updated but failed to revoke tokens: " + err.Error())
+		}
+	}
 
 	// Get role
 	role, _ := s.Repo.GetRoleByID(user.RoleID)
@@ -216,16 +286,77 @@ func (s *UserService) UpdateUser(userID uuid.UUID, req *UpdateUserRequest) (*Use
 // DeleteUser - Flow FR-009: Delete user
 func (s *UserService) DeleteUser(userID uuid.UUID) error {
 	// Check user exists
-	_, err := s.Repo.GetUserByID(userID)
+	user, err := s.Repo.GetUserByID(userID)
 	if err != nil {
 		return errors.New("user not found")
 	}
 
+	if err := s.ensureNotLastAdmin(user, uuid.Nil); err != nil {
+		return err
+	}
+
 	// Delete user (cascade will delete student/lecturer profiles)
 	err = s.Repo.DeleteUser(userID)
 	if err != nil {
 		return errors.New("failed to delete user: " + err.Error())
 	}
+	s.RBAC.InvalidateCache(userID)
+
+	return nil
+}
+
+// RevokeUserTokens - Cabut semua access token dan refresh token milik user
+func (s *UserService) RevokeUserTokens(userID uuid.UUID) error {
+	// Check user exists
+	_, err := s.Repo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	err = s.TokenRepo.RevokeAllUserTokens(userID)
+	if err != nil {
+		return errors.New("failed to revoke tokens: " + err.Error())
+	}
+
+	return nil
+}
+
+// SetAuthSource - Pilih backend autentikasi user (local/ldap), string kosong = ikut role
+func (s *UserService) SetAuthSource(userID uuid.UUID, source string) error {
+	if source != "" && !IsValidAuthSource(source) {
+		return errors.New("invalid auth source")
+	}
+
+	// Check user exists
+	_, err := s.Repo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	err = s.Repo.SetAuthSource(userID, source)
+	if err != nil {
+		return errors.New("failed to set auth source: " + err.Error())
+	}
+
+	return nil
+}
+
+// SetRoleAuthSource - Pilih backend autentikasi default untuk role (mis. semua dosen lewat LDAP)
+func (s *UserService) SetRoleAuthSource(roleID uuid.UUID, source string) error {
+	if !IsValidAuthSource(source) {
+		return errors.New("invalid auth source")
+	}
+
+	// Check role exists
+	_, err := s.Repo.GetRoleByID(roleID)
+	if err != nil {
+		return errors.New("role not found")
+	}
+
+	err = s.Repo.SetRoleAuthSource(roleID, source)
+	if err != nil {
+		return errors.New("failed to set auth source: " + err.Error())
+	}
 
 	return nil
 }
@@ -244,12 +375,19 @@ func (s *UserService) AssignRole(userID uuid.UUID, roleID uuid.UUID) (*UserRespo
 		return nil, errors.New("role not found")
 	}
 
+	if roleID != user.RoleID {
+		if err := s.ensureNotLastAdmin(user, user.RoleID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update role
 	user.RoleID = roleID
 	err = s.Repo.UpdateUser(user)
 	if err != nil {
 		return nil, errors.New("failed to assign role: " + err.Error())
 	}
+	s.RBAC.InvalidateCache(userID)
 
 	// Get student/lecturer profile if exists
 	student, _ := s.Repo.GetStudentByUserID(userID)
@@ -263,6 +401,52 @@ func (s *UserService) AssignRole(userID uuid.UUID, roleID uuid.UUID) (*UserRespo
 	}, nil
 }
 
+// AddRole - Tambah role tambahan ke user (mis. dosen yang juga koordinator prodi)
+func (s *UserService) AddRole(userID uuid.UUID, roleID uuid.UUID) (*UserResponse, error) {
+	if _, err := s.Repo.GetUserByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+	if _, err := s.Repo.GetRoleByID(roleID); err != nil {
+		return nil, errors.New("role not found")
+	}
+
+	added, err := s.Repo.AddUserRole(userID, roleID)
+	if err != nil {
+		return nil, errors.New("failed to add role: " + err.Error())
+	}
+	if added {
+		s.RBAC.InvalidateCache(userID)
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// RemoveRole - Lepas role tambahan dari user. Role utama diganti lewat AssignRole.
+func (s *UserService) RemoveRole(userID uuid.UUID, roleID uuid.UUID) (*UserResponse, error) {
+	user, err := s.Repo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if roleID == user.RoleID {
+		return nil, ErrPrimaryRole
+	}
+
+	if err := s.ensureNotLastAdmin(user, roleID); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.Repo.RemoveUserRole(userID, roleID)
+	if err != nil {
+		return nil, errors.New("failed to remove role: " + err.Error())
+	}
+	if !removed {
+		return nil, ErrRoleNotAssigned
+	}
+	s.RBAC.InvalidateCache(userID)
+
+	return s.GetUserByID(userID)
+}
+
 // SetStudentProfile - Flow FR-009: Set student profile
 func (s *UserService) SetStudentProfile(userID uuid.UUID, req *StudentProfileRequest) (*model.Student, error) {
 	// Check user exists
@@ -374,6 +558,26 @@ func (s *UserService) GetAllUsers(limit, offset int) ([]UserResponse, int, error
 		return nil, 0, err
 	}
 
+	return s.userResponses(users), total, nil
+}
+
+// GetUsersInScope - Daftar user yang boleh dikelola admin: semua user untuk admin global,
+// hanya mahasiswa/dosen di program studinya untuk admin ter-scope
+func (s *UserService) GetUsersInScope(scope *model.ProgramStudyScope, limit, offset int) ([]UserResponse, int, error) {
+	if scope.IsGlobal() {
+		return s.GetAllUsers(limit, offset)
+	}
+
+	users, total, err := s.Repo.GetUsersByProgramStudies(scope.Lowered(), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.userResponses(users), total, nil
+}
+
+// userResponses - Lengkapi daftar user dengan role dan profil mahasiswa/dosennya
+func (s *UserService) userResponses(users []model.Users) []UserResponse {
 	responses := make([]UserResponse, len(users))
 	for i, user := range users {
 		role, _ := s.Repo.GetRoleByID(user.RoleID)
@@ -388,7 +592,7 @@ func (s *UserService) GetAllUsers(limit, offset int) ([]UserResponse, int, error
 		}
 	}
 
-	return responses, total, nil
+	return responses
 }
 
 // GetUserByID - Get user by ID
@@ -399,6 +603,7 @@ func (s *UserService) GetUserByID(userID uuid.UUID) (*UserResponse, error) {
 	}
 
 	role, _ := s.Repo.GetRoleByID(user.RoleID)
+	roles, _ := s.Repo.GetUserRoles(user.ID)
 	student, _ := s.Repo.GetStudentByUserID(user.ID)
 	lecturer, _ := s.Repo.GetLecturerByUserID(user.ID)
 
@@ -407,6 +612,7 @@ func (s *UserService) GetUserByID(userID uuid.UUID) (*UserResponse, error) {
 		Student:  student,
 		Lecturer: lecturer,
 		Role:     role,
+		Roles:    roles,
 	}, nil
 }
 
@@ -415,6 +621,175 @@ func (s *UserService) GetAllRoles() ([]model.Roles, error) {
 	return s.Repo.GetAllRoles()
 }
 
+// AuthorizeRole - Admin ter-scope tidak boleh memberi role admin, termasuk role custom yang permission-nya
+// (lewat wildcard atau hierarki) mencakup admin.manage. Admin baru hanya dibuat admin global.
+func (s *UserService) AuthorizeRole(scope *model.ProgramStudyScope, roleID uuid.UUID) error {
+	if scope.IsGlobal() {
+		return nil
+	}
+
+	role, err := s.Repo.GetRoleByID(roleID)
+	if err != nil {
+		return errors.New("role not found")
+	}
+	if role.Name == adminRoleName {
+		return ErrScopedAdminRole
+	}
+
+	grantsAdmin, err := s.RBAC.RoleGrants(roleID, "admin.manage")
+	if err != nil {
+		return errors.New("failed to get role permissions: " + err.Error())
+	}
+	if grantsAdmin {
+		return ErrScopedAdminRole
+	}
+
+	return nil
+}
+
+// AuthorizeProfile - Program studi profil mahasiswa (atau department dosen) harus di dalam scope admin
+func (s *UserService) AuthorizeProfile(scope *model.ProgramStudyScope, programStudy string) error {
+	if !scope.Allows(programStudy) {
+		return ErrOutOfScope
+	}
+	return nil
+}
+
+// AuthorizeAdvisor - Dosen wali baru harus di dalam scope admin (department dosen = program studi)
+func (s *UserService) AuthorizeAdvisor(scope *model.ProgramStudyScope, advisorID uuid.UUID) error {
+	if scope.IsGlobal() {
+		return nil
+	}
+
+	advisor, err := s.Repo.GetLecturerByID(advisorID)
+	if err != nil {
+		return errors.New("advisor not found")
+	}
+
+	return s.AuthorizeProfile(scope, advisor.Department)
+}
+
+// AuthorizeNewUser - User baru dari admin ter-scope wajib punya profil mahasiswa/dosen di program studinya,
+// kalau tidak user tersebut tidak akan terlihat lagi oleh admin yang membuatnya
+func (s *UserService) AuthorizeNewUser(scope *model.ProgramStudyScope, roleID uuid.UUID, studentData *StudentProfileRequest, lecturerData *LecturerProfileRequest) error {
+	if scope.IsGlobal() {
+		return nil
+	}
+
+	if err := s.AuthorizeRole(scope, roleID); err != nil {
+		return err
+	}
+	if studentData == nil && lecturerData == nil {
+		return ErrOutOfScope
+	}
+	if studentData != nil {
+		if err := s.AuthorizeProfile(scope, studentData.ProgramStudy); err != nil {
+			return err
+		}
+	}
+	if lecturerData != nil {
+		if err := s.AuthorizeProfile(scope, lecturerData.Department); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAdminScopes - Semua scope program studi admin (admin tanpa baris = admin global)
+func (s *UserService) GetAdminScopes() ([]model.AdminScope, error) {
+	scopes, err := s.Repo.GetAllAdminScopes()
+	if err != nil {
+		return nil, errors.New("failed to get admin scopes: " + err.Error())
+	}
+	return scopes, nil
+}
+
+// SetAdminScope - Batasi admin ke program studi tertentu, daftar kosong = jadikan admin global.
+// Admin global terakhir tidak boleh di-scope.
+func (s *UserService) SetAdminScope(userID uuid.UUID, programStudies []string, actorID uuid.UUID) (*model.ProgramStudyScope, error) {
+	user, err := s.Repo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	isAdmin, err := s.RBAC.UserHasRole(userID, adminRoleName)
+	if err != nil {
+		return nil, errors.New("failed to get user roles: " + err.Error())
+	}
+	if !isAdmin {
+		return nil, ErrNotAdmin
+	}
+
+	// Normalisasi: buang spasi, nilai kosong, dan duplikat (tanpa membedakan huruf besar/kecil)
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, programStudy := range programStudies {
+		programStudy = strings.TrimSpace(programStudy)
+		key := strings.ToLower(programStudy)
+		if programStudy == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, programStudy)
+	}
+
+	if len(normalized) > 0 {
+		if err := s.ensureNotLastAdmin(user, uuid.Nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Repo.SetAdminScope(userID, normalized, actorID); err != nil {
+		return nil, errors.New("failed to set admin scope: " + err.Error())
+	}
+	s.RBAC.InvalidateCache(userID)
+
+	return &model.ProgramStudyScope{ProgramStudies: normalized}, nil
+}
+
+// ensureNotLastAdmin - Tolak perubahan jika user adalah admin aktif terakhir dan akan kehilangan role admin
+// (sistem tidak boleh tanpa admin). removedRoleID = role yang dilepas, uuid.Nil = semua role (hapus/nonaktifkan).
+func (s *UserService) ensureNotLastAdmin(user *model.Users, removedRoleID uuid.UUID) error {
+	if !user.IsActive {
+		return nil
+	}
+
+	// Admin ter-scope bukan admin global, melepasnya tidak mengurangi jumlah admin global
+	scope, err := s.RBAC.AdminScope(user.ID)
+	if err != nil {
+		return errors.New("failed to get admin scope: " + err.Error())
+	}
+	if !scope.IsGlobal() {
+		return nil
+	}
+
+	roles, err := s.Repo.GetUserRoles(user.ID)
+	if err != nil {
+		return errors.New("failed to get user roles: " + err.Error())
+	}
+
+	losesAdmin := false
+	for _, role := range roles {
+		if role.Name == adminRoleName && (removedRoleID == uuid.Nil || role.ID == removedRoleID) {
+			losesAdmin = true
+		}
+	}
+	if !losesAdmin {
+		return nil
+	}
+
+	count, err := s.Repo.CountOtherActiveAdmins(user.ID)
+	if err != nil {
+		return errors.New("failed to check remaining admins: " + err.Error())
+	}
+	if count == 0 {
+		return ErrLastAdmin
+	}
+
+	return nil
+}
+
 // validateCreateUserRequest - Validasi input
 func (s *UserService) validateCreateUserRequest(req *CreateUserRequest) error {
 	if req.Username == "" {
@@ -426,14 +801,11 @@ func (s *UserService) validateCreateUserRequest(req *CreateUserRequest) error {
 	if req.Password == "" {
 		return errors.New("password is required")
 	}
-	if len(req.Password) < 6 {
-		return errors.New("password must be at least 6 characters")
-	}
 	if req.FullName == "" {
 		return errors.New("full name is required")
 	}
 	if req.RoleID == uuid.Nil {
 		return errors.New("role ID is required")
 	}
-	return nil
+	return s.Passwords.CheckNewPassword(&model.Users{Username: req.Username, Email: req.Email}, req.Password)
 }
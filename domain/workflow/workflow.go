@@ -0,0 +1,223 @@
+// Package workflow berisi state machine status prestasi: status yang ada, transisi yang diizinkan,
+// role yang boleh memicu tiap transisi, dan guard-nya. Seperti package policy, tidak bergantung pada
+// service maupun database supaya aturannya ada di satu tempat dan bisa di-unit test langsung.
+package workflow
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownAction     = errors.New("unknown achievement action")
+	ErrRoleNotAllowed    = errors.New("forbidden: role cannot perform this achievement action")
+	ErrInvalidTransition = errors.New("invalid achievement status transition")
+)
+
+// Status prestasi bawaan (harus ada juga di tabel achievement_statuses)
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusVerified  = "verified"
+	StatusRejected  = "rejected"
+)
+
+// Aksi yang memicu transisi status, satu aksi per method AchievementService
+const (
+	ActionEdit   = "edit"
+	ActionSubmit = "submit"
+	ActionVerify = "verify"
+	ActionReject = "reject"
+	ActionDelete = "delete"
+)
+
+// Role pemicu transisi, sama dengan nama role RBAC dan actor_role di riwayat status
+const (
+	RoleStudent  = "student"
+	RoleLecturer = "lecturer"
+)
+
+// Guard - Syarat tambahan di luar status asal. Err dikembalikan jika Check gagal
+type Guard struct {
+	Name  string
+	Err   error
+	Check func(ref *model.AchievementReference) bool
+}
+
+// NotDeleted - Prestasi belum di-soft delete
+var NotDeleted = Guard{
+	Name: "not_deleted",
+	Err:  errors.New("achievement already deleted"),
+	Check: func(ref *model.AchievementReference) bool {
+		return !ref.IsDeleted
+	},
+}
+
+// Transition - Aksi boleh dijalankan dari salah satu status From oleh salah satu Roles jika semua Guards lolos.
+// To kosong = status tidak berubah (mis. edit), model.StatusDeleted = soft delete.
+type Transition struct {
+	Action string
+	From   []string
+	To     string
+	Roles  []string
+	Guards []Guard
+}
+
+// StateMachine - Status yang dikenal (Initial = status prestasi baru) dan transisi antar status
+type StateMachine struct {
+	Initial     string
+	States      []string
+	Transitions []Transition
+}
+
+// New - Buat state machine dan validasi definisinya: setiap status asal/tujuan harus dikenal,
+// setiap aksi hanya didefinisikan sekali dan punya minimal satu role.
+func New(initial string, states []string, transitions []Transition) (*StateMachine, error) {
+	machine := &StateMachine{Initial: initial, States: states, Transitions: transitions}
+
+	if !machine.HasState(initial) {
+		return nil, fmt.Errorf("initial status %q is not a known status", initial)
+	}
+
+	actions := make(map[string]bool, len(transitions))
+	for _, t := range transitions {
+		if t.Action == "" || actions[t.Action] {
+			return nil, fmt.Errorf("action %q is empty or defined more than once", t.Action)
+		}
+		actions[t.Action] = true
+
+		if len(t.From) == 0 || len(t.Roles) == 0 {
+			return nil, fmt.Errorf("action %q needs at least one source status and one role", t.Action)
+		}
+		for _, from := range t.From {
+			if !machine.HasState(from) {
+				return nil, fmt.Errorf("action %q: unknown source status %q", t.Action, from)
+			}
+		}
+		if t.To != "" && t.To != model.StatusDeleted && !machine.HasState(t.To) {
+			return nil, fmt.Errorf("action %q: unknown target status %q", t.Action, t.To)
+		}
+	}
+
+	return machine, nil
+}
+
+// mustNew - New untuk definisi bawaan, panic jika definisinya salah
+func mustNew(initial string, states []string, transitions []Transition) *StateMachine {
+	machine, err := New(initial, states, transitions)
+	if err != nil {
+		panic("workflow: " + err.Error())
+	}
+	return machine
+}
+
+// Achievement - State machine bawaan prestasi. Status baru (mis. needs_revision, under_review) cukup ditambahkan
+// di sini dan di tabel achievement_statuses, lalu dimasukkan ke From/To transisi yang relevan.
+var Achievement = mustNew(
+	StatusDraft,
+	[]string{StatusDraft, StatusSubmitted, StatusVerified, StatusRejected},
+	[]Transition{
+		{Action: ActionEdit, From: []string{StatusDraft, StatusRejected}, Roles: []string{RoleStudent}, Guards: []Guard{NotDeleted}},
+		{Action: ActionSubmit, From: []string{StatusDraft, StatusRejected}, To: StatusSubmitted, Roles: []string{RoleStudent}, Guards: []Guard{NotDeleted}},
+		{Action: ActionVerify, From: []string{StatusSubmitted}, To: StatusVerified, Roles: []string{RoleLecturer}, Guards: []Guard{NotDeleted}},
+		{Action: ActionReject, From: []string{StatusSubmitted}, To: StatusRejected, Roles: []string{RoleLecturer}, Guards: []Guard{NotDeleted}},
+		{Action: ActionDelete, From: []string{StatusDraft}, To: model.StatusDeleted, Roles: []string{RoleStudent}, Guards: []Guard{NotDeleted}},
+	},
+)
+
+// HasState - Status dikenal state machine
+func (m *StateMachine) HasState(status string) bool {
+	return contains(m.States, status)
+}
+
+// Transition - Definisi transisi untuk aksi (nil jika aksi tidak dikenal)
+func (m *StateMachine) Transition(action string) *Transition {
+	for i := range m.Transitions {
+		if m.Transitions[i].Action == action {
+			return &m.Transitions[i]
+		}
+	}
+	return nil
+}
+
+// RoleFor - Role pertama transisi yang dimiliki user (roles = semua role RBAC user). Role ini yang dipakai
+// untuk Fire dan dicatat sebagai actor_role di riwayat status.
+func (m *StateMachine) RoleFor(action string, roles []string) (string, error) {
+	t := m.Transition(action)
+	if t == nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+
+	for _, role := range t.Roles {
+		if contains(roles, role) {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s requires role %s", ErrRoleNotAllowed, action, strings.Join(t.Roles, " or "))
+}
+
+// Fire - Cek apakah role boleh menjalankan aksi pada prestasi dan kembalikan status tujuannya
+// (status sekarang jika transisi tidak mengubah status). Urutan cek: aksi, role, guard, status asal.
+func (m *StateMachine) Fire(action, role string, ref *model.AchievementReference) (string, error) {
+	t := m.Transition(action)
+	if t == nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+
+	if !contains(t.Roles, role) {
+		return "", fmt.Errorf("%w: %s cannot %s achievements", ErrRoleNotAllowed, role, action)
+	}
+
+	for _, guard := range t.Guards {
+		if !guard.Check(ref) {
+			return "", guard.Err
+		}
+	}
+
+	if !contains(t.From, ref.Status) {
+		return "", fmt.Errorf("%w: achievement must be in %s status to %s (current: '%s')",
+			ErrInvalidTransition, quoteStatuses(t.From), action, ref.Status)
+	}
+
+	if t.To == "" {
+		return ref.Status, nil
+	}
+	return t.To, nil
+}
+
+// Can - Role boleh menjalankan aksi pada prestasi saat ini
+func (m *StateMachine) Can(action, role string, ref *model.AchievementReference) bool {
+	_, err := m.Fire(action, role, ref)
+	return err == nil
+}
+
+// AvailableActions - Semua aksi yang boleh dijalankan role pada prestasi saat ini, urut sesuai definisi
+func (m *StateMachine) AvailableActions(role string, ref *model.AchievementReference) []string {
+	actions := []string{}
+	for _, t := range m.Transitions {
+		if m.Can(t.Action, role, ref) {
+			actions = append(actions, t.Action)
+		}
+	}
+	return actions
+}
+
+// quoteStatuses - 'draft' or 'rejected'
+func quoteStatuses(statuses []string) string {
+	quoted := make([]string, len(statuses))
+	for i, status := range statuses {
+		quoted[i] = "'" + status + "'"
+	}
+	return strings.Join(quoted, " or ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
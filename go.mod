@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/gofiber/fiber/v2 v2.52.0
+	github.com/go-ldap/ldap/v3 v3.4.4
 	github.com/gofiber/swagger v1.0.0
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/google/uuid v1.5.0
@@ -19,9 +20,11 @@ require (
 	github.com/stretchr/testify v1.8.4
 	github.com/golang/mock v1.6.0
 	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/go-asn1-ber/asn1-ber v1.5.4
 )
 
 require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
 	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/golang/snappy v0.0.1 // indirect
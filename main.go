@@ -8,6 +8,7 @@ import (
 	"UAS_BACKEND/domain/service"
 	"context"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -34,28 +35,157 @@ func main() {
 
 	// Initialize repositories
 	authRepo := repository.NewAuthRepository(cfg.DB)
+	tokenRepo := repository.NewTokenRepository(cfg.DB)
+	passwordResetRepo := repository.NewPasswordResetRepository(cfg.DB)
+	mailRepo := repository.NewMailRepository(cfg.DB)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(cfg.DB)
+	mfaRepo := repository.NewMFARepository(cfg.DB)
+	sessionRepo := repository.NewSessionRepository(cfg.DB)
+	passwordHistoryRepo := repository.NewPasswordHistoryRepository(cfg.DB)
+	emailChangeRepo := repository.NewEmailChangeRepository(cfg.DB)
+	accessTokenRepo := repository.NewAccessTokenRepository(cfg.DB)
+	impersonationRepo := repository.NewImpersonationRepository(cfg.DB)
+	invitationRepo := repository.NewInvitationRepository(cfg.DB)
+	oidcRepo := repository.NewOIDCRepository(cfg.DB)
 	rbacRepo := repository.NewRBACRepository(cfg.DB)
 	achievementRepo := repository.NewAchievementRepository(cfg.DB, mongoCfg.Database)
 	notificationRepo := repository.NewNotificationRepository(cfg.DB)
+	delegationRepo := repository.NewDelegationRepository(cfg.DB)
 	userRepo := repository.NewUserRepository(cfg.DB)
 	statisticsRepo := repository.NewStatisticsRepository(cfg.DB, mongoCfg.Database)
 
+	// Load JWT signing keys
+	jwtKeys, err := service.LoadKeyManager(cfg.JWTKeysDir, cfg.JWTActiveKID)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
 	// Initialize services
+	// Mailer: SMTP jika SMTP_HOST diset, selain itu email hanya ditulis ke log (body disensor kecuali MAIL_LOG_BODY=true)
+	var mailer service.Mailer
+	if cfg.SMTPHost != "" {
+		mailer = service.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		log.Println("WARNING: SMTP_HOST is not set, emails are not delivered and only written to the log")
+		if cfg.MailLogBody {
+			log.Println("WARNING: MAIL_LOG_BODY=true, password reset, invitation and email change tokens are written to the log (development only)")
+		}
+		mailer = service.LogMailer{LogBody: cfg.MailLogBody}
+	}
+	mailService := service.NewMailService(mailRepo, mailer)
+	mailService.StartOutboxWorker(10 * time.Second)
+
+	lockoutPolicy := service.DefaultLockoutPolicy()
+	lockoutPolicy.MaxAccountFailures = cfg.LoginMaxFailures
+	lockoutPolicy.MaxIPFailures = cfg.LoginMaxIPFails
+	lockoutPolicy.LockDuration = time.Duration(cfg.LoginLockMinutes) * time.Minute
+	lockoutService := service.NewLockoutService(loginAttemptRepo, notificationRepo, mailService, lockoutPolicy)
+	mfaService := service.NewMFAService(mfaRepo, authRepo, cfg.MFAIssuer)
+	sessionService := service.NewSessionService(sessionRepo, tokenRepo)
+	passwordService := service.NewPasswordService(
+		service.PasswordPolicy{
+			MinLength:      cfg.PasswordMinLen,
+			MinCharClasses: cfg.PasswordClasses,
+			HistorySize:    cfg.PasswordHistory,
+		},
+		service.NewBreachedPasswordChecker(cfg.BreachedPwdDir),
+		passwordHistoryRepo,
+	)
+
 	authService := service.NewAuthService(
-		cfg.SecretKey,
-		24*time.Hour, // Token TTL 24 jam
+		jwtKeys,
+		15*time.Minute,  // Access token TTL 15 menit
+		30*24*time.Hour, // Refresh token TTL 30 hari
+		authRepo,
+		tokenRepo,
+		lockoutService,
+		mfaService,
+		sessionService,
+		passwordService,
+	)
+	authService.StartTokenCleanup(1 * time.Hour)
+
+	// Backend LDAP untuk user/role dengan auth_source 'ldap', hanya aktif jika LDAP_URL diisi
+	if cfg.LDAPURL != "" {
+		authService.RegisterAuthenticator(service.AuthSourceLDAP, service.NewLDAPAuthenticator(service.LDAPConfig{
+			URL:            cfg.LDAPURL,
+			StartTLS:       cfg.LDAPStartTLS,
+			BindDN:         cfg.LDAPBindDN,
+			BindPassword:   cfg.LDAPBindPassword,
+			BaseDN:         cfg.LDAPBaseDN,
+			UserFilter:     cfg.LDAPUserFilter,
+			FullNameAttr:   cfg.LDAPFullNameAttr,
+			EmailAttr:      cfg.LDAPEmailAttr,
+			LecturerIDAttr: cfg.LDAPLecturerIDAttr,
+		}))
+	}
+
+	passwordResetService := service.NewPasswordResetService(
 		authRepo,
+		passwordResetRepo,
+		tokenRepo,
+		mailService,
+		passwordService,
+		30*time.Minute, // Token reset password berlaku 30 menit
+		cfg.PasswordResetURL,
 	)
 	rbacService := service.NewRBACService(rbacRepo)
-	achievementService := service.NewAchievementService(achievementRepo)
+	achievementService := service.NewAchievementService(achievementRepo, delegationRepo, rbacService)
 	notificationService := service.NewNotificationService(notificationRepo)
+	delegationService := service.NewDelegationService(delegationRepo, achievementRepo, rbacService, notificationService)
 	fileService := service.NewFileService("./uploads", 10) // Max 10MB per file
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, tokenRepo, passwordService, rbacService)
+	roleService := service.NewRoleService(rbacRepo, rbacService)
+	accountService := service.NewAccountService(
+		userRepo,
+		authRepo,
+		emailChangeRepo,
+		mailService,
+		24*time.Hour, // Link konfirmasi ganti email berlaku 24 jam
+		cfg.EmailChangeURL,
+	)
+	invitationService := service.NewInvitationService(
+		userService,
+		invitationRepo,
+		mailService,
+		passwordService,
+		time.Duration(cfg.InvitationTTLHours)*time.Hour,
+		cfg.InvitationURL,
+	)
+	accessTokenService := service.NewAccessTokenService(accessTokenRepo, authRepo)
+	impersonationService := service.NewImpersonationService(
+		authService,
+		rbacService,
+		impersonationRepo,
+		10*time.Minute, // Token impersonation berlaku 10 menit, tanpa refresh token
+	)
+
+	// Login SSO (OIDC) hanya aktif jika OIDC_ISSUER_URL diisi
+	var oidcService *service.OIDCService
+	if cfg.OIDCIssuerURL != "" {
+		oidcClient := service.NewOIDCClient(service.OIDCConfig{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       strings.Fields(cfg.OIDCScopes),
+		}, nil)
+		oidcService = service.NewOIDCService(
+			oidcClient,
+			oidcRepo,
+			userRepo,
+			authService,
+			cfg.OIDCDefaultRole,
+			cfg.OIDCProvision,
+			10*time.Minute, // Batas waktu login di halaman identity provider
+		)
+	}
 	adminAchievementService := service.NewAdminAchievementService(achievementRepo)
 	statisticsService := service.NewStatisticsService(statisticsRepo)
+	policyService := service.NewPolicyService(achievementRepo, delegationRepo, rbacService)
 
 	// Initialize middleware
-	rbacMiddleware := middleware.NewRBACMiddleware(authService, rbacService)
+	rbacMiddleware := middleware.NewRBACMiddleware(authService, rbacService, accessTokenService, impersonationService, policyService)
 
 	// Initialize handlers
 	authHandler := route.NewAuthHandler(authService)
@@ -64,7 +194,7 @@ func main() {
 	notificationHandler := route.NewNotificationHandler(notificationService, rbacMiddleware)
 	lecturerHandler := route.NewLecturerHandler(achievementService, notificationService, rbacMiddleware)
 	fileHandler := route.NewFileHandler(fileService, rbacMiddleware)
-	adminHandler := route.NewAdminHandler(userService, adminAchievementService, rbacMiddleware)
+	adminHandler := route.NewAdminHandler(userService, invitationService, roleService, adminAchievementService, lockoutService, mfaService, accessTokenService, impersonationService, rbacMiddleware)
 	statisticsHandler := route.NewStatisticsHandler(statisticsService, rbacMiddleware)
 
 	// Setup Fiber app
@@ -81,6 +211,9 @@ func main() {
 		BodyLimit: 10 * 1024 * 1024, // 10MB body limit
 	})
 
+	// Catat permission/role yang dibutuhkan setiap route, harus sebelum route pertama didaftarkan
+	rbacMiddleware.Routes.Track(app)
+
 	// Global middleware
 	app.Use(logger.New())
 	app.Use(cors.New())
@@ -99,18 +232,26 @@ func main() {
 	route.SetupV1Routes(
 		app,
 		authService,
+		passwordResetService,
+		invitationService,
+		oidcService,
+		mfaService,
+		sessionService,
+		accountService,
+		accessTokenService,
 		userService,
 		achievementService,
 		notificationService,
 		fileService,
 		statisticsService,
+		delegationService,
 		rbacMiddleware,
 	)
 
 	// Swagger documentation
 	app.Get("/swagger/*", swagger.New(swagger.Config{
-		URL:         "/swagger/doc.json",
-		DeepLinking: false,
+		URL:          "/swagger/doc.json",
+		DeepLinking:  false,
 		DocExpansion: "none",
 		OAuth: &swagger.OAuthConfig{
 			AppName:  "OAuth Provider",
@@ -133,4 +274,4 @@ func main() {
 	if err := app.Listen(port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/service"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminGroupApp - App dengan group /api/admin seperti SetupAdminRoutes. Claims diambil dari header
+// supaya tidak perlu JWT: X-Access-Token-ID diisi = request memakai personal access token.
+func newAdminGroupApp(userID uuid.UUID) *fiber.App {
+	rbacService := service.NewRBACService(nil)
+	rbacService.Cache.Set(userID, &model.PermissionState{IsActive: true, RoleNames: []string{"admin"}})
+	rbac := middleware.NewRBACMiddleware(nil, rbacService, nil, nil, nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		claims := &model.CustomClaims{UserID: userID, Permissions: []string{"achievement.read"}}
+		if tokenID, err := uuid.Parse(c.Get("X-Access-Token-ID")); err == nil {
+			claims.AccessTokenID = tokenID
+		}
+		c.Locals("user_id", claims.UserID)
+		c.Locals("permissions", claims.Permissions)
+		c.Locals("claims", claims)
+		return c.Next()
+	})
+
+	admin := app.Group("/api/admin", rbac.RequireRole("admin"))
+	admin.Get("/users", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	admin.Get("/roles", rbac.RequireGlobalAdmin(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	return app
+}
+
+func TestRequireRole_PersonalAccessToken(t *testing.T) {
+	app := newAdminGroupApp(uuid.New())
+
+	testCases := []struct {
+		name    string
+		path    string
+		tokenID string
+		status  int
+	}{
+		{name: "JWT admin", path: "/api/admin/users", status: fiber.StatusOK},
+		{name: "Admin PAT scoped to achievement.read", path: "/api/admin/users", tokenID: uuid.NewString(), status: fiber.StatusForbidden},
+		{name: "JWT global admin", path: "/api/admin/roles", status: fiber.StatusOK},
+		{name: "Admin PAT on global admin route", path: "/api/admin/roles", tokenID: uuid.NewString(), status: fiber.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.tokenID != "" {
+				req.Header.Set("X-Access-Token-ID", tc.tokenID)
+			}
+
+			// Act
+			resp, err := app.Test(req)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
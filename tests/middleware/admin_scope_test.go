@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/service"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGlobalAdminApp - App dengan route konfigurasi sistem yang hanya untuk admin global.
+// user_id diambil dari header supaya tidak perlu JWT.
+func newGlobalAdminApp() (*fiber.App, *middleware.RBACMiddleware, *service.RBACService) {
+	rbacService := service.NewRBACService(nil)
+	rbac := middleware.NewRBACMiddleware(nil, rbacService, nil, nil, nil)
+
+	app := fiber.New()
+	rbac.Routes.Track(app)
+	app.Use(func(c *fiber.Ctx) error {
+		if userID, err := uuid.Parse(c.Get("X-User-ID")); err == nil {
+			c.Locals("user_id", userID)
+		}
+		return c.Next()
+	})
+	app.Get("/api/admin/roles/:id", rbac.RequireGlobalAdmin(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	return app, rbac, rbacService
+}
+
+func TestRequireGlobalAdmin(t *testing.T) {
+	app, _, rbacService := newGlobalAdminApp()
+	globalAdmin, scopedAdmin := uuid.New(), uuid.New()
+	rbacService.Cache.Set(globalAdmin, &model.PermissionState{IsActive: true, RoleNames: []string{"admin"}})
+	rbacService.Cache.Set(scopedAdmin, &model.PermissionState{IsActive: true, RoleNames: []string{"admin"}, AdminScope: []string{"Teknik Informatika"}})
+
+	testCases := []struct {
+		name   string
+		userID uuid.UUID
+		status int
+	}{
+		{name: "Global admin", userID: globalAdmin, status: fiber.StatusOK},
+		{name: "Scoped admin", userID: scopedAdmin, status: fiber.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest("GET", "/api/admin/roles/"+uuid.NewString(), nil)
+			req.Header.Set("X-User-ID", tc.userID.String())
+
+			// Act
+			resp, err := app.Test(req)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestRBACMiddleware_Explain_ScopedAdmin(t *testing.T) {
+	// Arrange
+	_, rbac, rbacService := newGlobalAdminApp()
+	userID := uuid.New()
+	rbacService.Cache.Set(userID, &model.PermissionState{IsActive: true, RoleNames: []string{"admin"}, AdminScope: []string{"Sistem Informasi", "Teknik Informatika"}})
+
+	// Act
+	explanation, err := rbac.Explain(userID, fiber.MethodGet, "/api/admin/roles/"+uuid.NewString())
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, explanation.Allowed)
+	require.Len(t, explanation.Checks, 1)
+	assert.Equal(t, middleware.RequirementGlobalAdmin, explanation.Checks[0].Type)
+	assert.Contains(t, explanation.Checks[0].Reason, "Sistem Informasi, Teknik Informatika")
+}
@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"UAS_BACKEND/domain/middleware/policy"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionGranted(t *testing.T) {
+	testCases := []struct {
+		name     string
+		granted  []string
+		required string
+		expected bool
+	}{
+		{name: "Exact match", granted: []string{"achievement.read"}, required: "achievement.read", expected: true},
+		{name: "No match", granted: []string{"achievement.read"}, required: "achievement.write", expected: false},
+		{name: "Resource wildcard", granted: []string{"achievement.*"}, required: "achievement.verify", expected: true},
+		{name: "Resource wildcard other resource", granted: []string{"achievement.*"}, required: "user.read", expected: false},
+		{name: "Action wildcard", granted: []string{"*.read"}, required: "student.read", expected: true},
+		{name: "Action wildcard other action", granted: []string{"*.read"}, required: "student.write", expected: false},
+		{name: "Global wildcard", granted: []string{"*.*"}, required: "report.export", expected: true},
+		{name: "Implied by verify", granted: []string{"achievement.verify"}, required: "achievement.read", expected: true},
+		{name: "Implication is one-way", granted: []string{"achievement.read"}, required: "achievement.verify", expected: false},
+		{name: "Implied by delete", granted: []string{"user.delete"}, required: "user.read", expected: true},
+		{name: "Wildcard required covered by same wildcard", granted: []string{"achievement.*"}, required: "achievement.*", expected: true},
+		{name: "Wildcard required not covered by single action", granted: []string{"achievement.read"}, required: "achievement.*", expected: false},
+		{name: "Wildcard required covered by global wildcard", granted: []string{"*.*"}, required: "achievement.*", expected: true},
+		{name: "Name without dot", granted: []string{"*.*"}, required: "admin", expected: false},
+		{name: "No permissions", granted: nil, required: "achievement.read", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, policy.PermissionGranted(tc.granted, tc.required))
+		})
+	}
+}
+
+func TestExpandPermissions_Transitive(t *testing.T) {
+	// Arrange: rantai sementara a.write -> a.verify -> a.read
+	original := policy.ImpliedPermissions
+	policy.ImpliedPermissions = map[string][]string{
+		"a.write":  {"a.verify"},
+		"a.verify": {"a.read"},
+	}
+	defer func() { policy.ImpliedPermissions = original }()
+
+	// Act
+	expanded := policy.ExpandPermissions([]string{"a.write", "a.read"})
+
+	// Assert: tidak ada duplikat
+	assert.ElementsMatch(t, []string{"a.write", "a.verify", "a.read"}, expanded)
+}
+
+func TestMissingPermissions(t *testing.T) {
+	granted := []string{"achievement.*", "student.write"}
+
+	missing := policy.MissingPermissions(granted, []string{"achievement.verify", "student.read", "user.read"})
+
+	assert.Equal(t, []string{"user.read"}, missing)
+}
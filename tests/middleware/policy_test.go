@@ -0,0 +1,183 @@
+package middleware_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware/policy"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// policyFixture - Mahasiswa dengan dosen wali, dosen lain satu prodi, dan dosen prodi lain
+type policyFixture struct {
+	owner, otherStudent                 *model.Student
+	advisor, colleague, otherDepartment *model.Lecturer
+	resource                            *policy.Resource
+}
+
+func newPolicyFixture() policyFixture {
+	advisor := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), Department: "Teknik Informatika"}
+	owner := &model.Student{ID: uuid.New(), UserID: uuid.New(), ProgramStudy: "Teknik Informatika", AdvisorID: advisor.ID}
+
+	return policyFixture{
+		owner:           owner,
+		otherStudent:    &model.Student{ID: uuid.New(), UserID: uuid.New(), ProgramStudy: "Teknik Informatika"},
+		advisor:         advisor,
+		colleague:       &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), Department: "teknik informatika"},
+		otherDepartment: &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), Department: "Sistem Informasi"},
+		resource: &policy.Resource{
+			Type:    policy.ResourceAchievement,
+			ID:      uuid.New(),
+			OwnerID: owner.ID,
+			Owner:   owner,
+		},
+	}
+}
+
+func TestPolicy_StudentRecord(t *testing.T) {
+	f := newPolicyFixture()
+
+	testCases := []struct {
+		name    string
+		subject *policy.Subject
+		allowed bool
+		matched string
+	}{
+		{name: "Owner", subject: &policy.Subject{Student: f.owner}, allowed: true, matched: "owner"},
+		{name: "Advisor", subject: &policy.Subject{Lecturer: f.advisor}, allowed: true, matched: "advisor"},
+		{name: "Lecturer in same program study", subject: &policy.Subject{Lecturer: f.colleague}, allowed: true, matched: "same_program_study"},
+		{name: "Admin", subject: &policy.Subject{Roles: []string{"admin"}}, allowed: true, matched: "admin_scope"},
+		{name: "Lecturer in other department", subject: &policy.Subject{Lecturer: f.otherDepartment}, allowed: false},
+		{name: "Other student in same program study", subject: &policy.Subject{Student: f.otherStudent}, allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			decision := policy.StudentRecord.Evaluate(tc.subject, f.resource)
+
+			// Assert
+			assert.Equal(t, tc.allowed, decision.Allowed)
+			assert.Equal(t, tc.matched, decision.Matched)
+			assert.Equal(t, "student.record", decision.Policy)
+		})
+	}
+}
+
+func TestPolicy_AchievementOwner_OnlyOwner(t *testing.T) {
+	// Arrange: resource tanpa data mahasiswa, cukup ID pemilik
+	f := newPolicyFixture()
+	res := &policy.Resource{Type: policy.ResourceAchievement, ID: uuid.New(), OwnerID: f.owner.ID}
+
+	// Act & Assert
+	assert.NoError(t, policy.AchievementOwner.Authorize(&policy.Subject{Student: f.owner}, res))
+	assert.ErrorIs(t, policy.AchievementOwner.Authorize(&policy.Subject{Student: f.otherStudent}, res), policy.ErrDenied)
+	assert.ErrorIs(t, policy.AchievementOwner.Authorize(&policy.Subject{Lecturer: f.advisor}, res), policy.ErrDenied)
+	assert.ErrorIs(t, policy.AchievementOwner.Authorize(&policy.Subject{Roles: []string{"admin"}}, res), policy.ErrDenied)
+}
+
+func TestPolicy_AchievementVerifier_OnlyAdvisor(t *testing.T) {
+	f := newPolicyFixture()
+
+	// Act & Assert: dosen satu prodi yang bukan dosen wali tidak boleh verifikasi
+	assert.NoError(t, policy.AchievementVerifier.Authorize(&policy.Subject{Lecturer: f.advisor}, f.resource))
+	assert.ErrorIs(t, policy.AchievementVerifier.Authorize(&policy.Subject{Lecturer: f.colleague}, f.resource), policy.ErrDenied)
+	assert.ErrorIs(t, policy.AchievementVerifier.Authorize(&policy.Subject{Student: f.owner}, f.resource), policy.ErrDenied)
+}
+
+func TestPolicy_AchievementVerifier_Delegate(t *testing.T) {
+	// Arrange: dosen prodi lain menerima delegasi dari dosen wali
+	f := newPolicyFixture()
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+	delegation := func(modify func(d *model.VerificationDelegation)) *policy.Subject {
+		d := model.VerificationDelegation{
+			ID:          uuid.New(),
+			DelegatorID: f.advisor.ID,
+			DelegateID:  f.otherDepartment.ID,
+			StartsAt:    now.Add(-time.Hour),
+			EndsAt:      now.Add(time.Hour),
+		}
+		if modify != nil {
+			modify(&d)
+		}
+		return &policy.Subject{Lecturer: f.otherDepartment, Delegations: []model.VerificationDelegation{d}}
+	}
+
+	testCases := []struct {
+		name    string
+		subject *policy.Subject
+		allowed bool
+	}{
+		{name: "Active delegation for all advisees", subject: delegation(nil), allowed: true},
+		{name: "Student in delegated subset", subject: delegation(func(d *model.VerificationDelegation) { d.StudentIDs = []uuid.UUID{f.owner.ID} }), allowed: true},
+		{name: "Student outside delegated subset", subject: delegation(func(d *model.VerificationDelegation) { d.StudentIDs = []uuid.UUID{f.otherStudent.ID} }), allowed: false},
+		{name: "Expired delegation", subject: delegation(func(d *model.VerificationDelegation) { d.EndsAt = now.Add(-time.Minute) }), allowed: false},
+		{name: "Revoked delegation", subject: delegation(func(d *model.VerificationDelegation) { d.RevokedAt = &revokedAt }), allowed: false},
+		{name: "Delegation from another advisor", subject: delegation(func(d *model.VerificationDelegation) { d.DelegatorID = f.colleague.ID }), allowed: false},
+		{name: "Without delegation", subject: &policy.Subject{Lecturer: f.otherDepartment}, allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			decision := policy.AchievementVerifier.Evaluate(tc.subject, f.resource)
+
+			// Assert
+			assert.Equal(t, tc.allowed, decision.Allowed)
+			if tc.allowed {
+				assert.Equal(t, "delegate", decision.Matched)
+				assert.NotNil(t, policy.ActiveDelegation(tc.subject, f.resource, now))
+			}
+		})
+	}
+}
+
+func TestPolicy_AdminScope(t *testing.T) {
+	// Arrange: resource mahasiswa Teknik Informatika dan user dosen Sistem Informasi
+	f := newPolicyFixture()
+	lecturerUser := &policy.Resource{Type: policy.ResourceUser, ID: f.otherDepartment.UserID, ProgramStudies: []string{f.otherDepartment.Department}}
+	scoped := func(programStudies ...string) *policy.Subject {
+		return &policy.Subject{Roles: []string{"admin"}, AdminScope: programStudies}
+	}
+
+	testCases := []struct {
+		name     string
+		policy   policy.Policy
+		subject  *policy.Subject
+		resource *policy.Resource
+		allowed  bool
+	}{
+		{name: "Global admin", policy: policy.StudentRecord, subject: scoped(), resource: f.resource, allowed: true},
+		{name: "Scoped admin, student in scope", policy: policy.StudentRecord, subject: scoped("teknik informatika"), resource: f.resource, allowed: true},
+		{name: "Scoped admin, student outside scope", policy: policy.StudentRecord, subject: scoped("Sistem Informasi"), resource: f.resource, allowed: false},
+		{name: "Scoped admin, lecturer department in scope", policy: policy.AdminUser, subject: scoped("Manajemen", "Sistem Informasi"), resource: lecturerUser, allowed: true},
+		{name: "Scoped admin, lecturer department outside scope", policy: policy.AdminUser, subject: scoped("Teknik Informatika"), resource: lecturerUser, allowed: false},
+		{name: "Scoped admin, user without program study", policy: policy.AdminUser, subject: scoped("Teknik Informatika"), resource: &policy.Resource{Type: policy.ResourceUser, ID: uuid.New()}, allowed: false},
+		{name: "Admin by permission", policy: policy.AdminUser, subject: &policy.Subject{Permissions: []string{"admin.manage"}}, resource: lecturerUser, allowed: true},
+		{name: "Not an admin", policy: policy.AdminUser, subject: &policy.Subject{Lecturer: f.otherDepartment}, resource: lecturerUser, allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			decision := tc.policy.Evaluate(tc.subject, tc.resource)
+
+			// Assert
+			assert.Equal(t, tc.allowed, decision.Allowed)
+			if tc.allowed {
+				assert.Equal(t, "admin_scope", decision.Matched)
+			}
+		})
+	}
+}
+
+func TestPolicy_NilResourceDenied(t *testing.T) {
+	f := newPolicyFixture()
+
+	decision := policy.StudentRecord.Evaluate(&policy.Subject{Student: f.owner}, nil)
+
+	assert.False(t, decision.Allowed)
+}
@@ -0,0 +1,116 @@
+package middleware_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/middleware"
+	"UAS_BACKEND/domain/middleware/policy"
+	"UAS_BACKEND/domain/service"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noop(c *fiber.Ctx) error { return nil }
+
+// newRegistryApp - App dengan grup terautentikasi, route permission, route role, dan route policy
+func newRegistryApp() (*middleware.RBACMiddleware, *service.RBACService) {
+	rbacService := service.NewRBACService(nil)
+	rbac := middleware.NewRBACMiddleware(nil, rbacService, nil, nil, nil)
+
+	app := fiber.New()
+	rbac.Routes.Track(app)
+
+	app.Get("/health", noop)
+
+	students := app.Group("/api/v1/students")
+	students.Use(rbac.RequireAuth())
+	students.Get("/", rbac.RequireAnyPermission("student.read", "admin.manage"), noop)
+	students.Get("/:id", rbac.RequirePermission("student.read"), rbac.RequirePolicy(policy.StudentRecord, rbac.StudentParam("id")), noop)
+	students.Put("/:id/advisor", rbac.RequireAllPermissions("student.write", "lecturer.read"), noop)
+
+	admin := app.Group("/api/admin", rbac.Authenticate(), rbac.RequireRole("admin"))
+	admin.Get("/users", noop)
+
+	return rbac, rbacService
+}
+
+func TestRouteRegistry_Routes(t *testing.T) {
+	// Arrange
+	rbac, _ := newRegistryApp()
+
+	// Act
+	routes := rbac.Routes.Routes()
+
+	// Assert: middleware grup tidak muncul sebagai route, HEAD otomatis dilewati
+	byRoute := map[string]middleware.RouteInfo{}
+	for _, route := range routes {
+		byRoute[route.Method+" "+route.Path] = route
+	}
+	assert.Len(t, byRoute, 5)
+	assert.Empty(t, byRoute["GET /health"].Requirements)
+
+	detail := byRoute["GET /api/v1/students/:id"].Requirements
+	require.Len(t, detail, 3)
+	assert.Equal(t, middleware.RequirementAuth, detail[0].Type)
+	assert.Equal(t, "USE /api/v1/students", detail[0].Source)
+	assert.Equal(t, middleware.RequirementPermission, detail[1].Type)
+	assert.Equal(t, []string{"student.read"}, detail[1].Names)
+	assert.Equal(t, middleware.RequirementPolicy, detail[2].Type)
+	assert.Equal(t, []string{"student.record"}, detail[2].Names)
+	assert.Equal(t, "GET /api/v1/students/:id", detail[2].Source)
+
+	users := byRoute["GET /api/admin/users"].Requirements
+	require.Len(t, users, 2)
+	assert.Equal(t, middleware.RequirementRole, users[1].Type)
+	assert.Equal(t, []string{"admin"}, users[1].Names)
+}
+
+func TestRBACMiddleware_Explain(t *testing.T) {
+	rbac, rbacService := newRegistryApp()
+	userID := uuid.New()
+	rbacService.Cache.Set(userID, &model.PermissionState{
+		IsActive:    true,
+		RoleNames:   []string{"lecturer"},
+		Permissions: []string{"student.*"},
+	})
+
+	t.Run("Granted by wildcard permission", func(t *testing.T) {
+		// Act
+		explanation, err := rbac.Explain(userID, "get", "/api/v1/students/")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, explanation.Allowed)
+		assert.Equal(t, "/api/v1/students/", explanation.Route)
+		require.Len(t, explanation.Checks, 2)
+		assert.Equal(t, "student.read", explanation.Checks[1].Matched)
+		assert.Equal(t, "student.*", explanation.Checks[1].GrantedBy)
+	})
+
+	t.Run("Missing one of all permissions", func(t *testing.T) {
+		explanation, err := rbac.Explain(userID, fiber.MethodPut, "/api/v1/students/"+uuid.NewString()+"/advisor")
+
+		require.NoError(t, err)
+		assert.False(t, explanation.Allowed)
+		assert.Equal(t, []string{"lecturer.read"}, explanation.Checks[1].Missing)
+	})
+
+	t.Run("Missing role", func(t *testing.T) {
+		explanation, err := rbac.Explain(userID, fiber.MethodGet, "/api/admin/users")
+
+		require.NoError(t, err)
+		assert.False(t, explanation.Allowed)
+		assert.True(t, explanation.Checks[0].Passed)
+		assert.False(t, explanation.Checks[1].Passed)
+		assert.Equal(t, []string{"admin"}, explanation.Checks[1].Missing)
+	})
+
+	t.Run("Unknown route", func(t *testing.T) {
+		_, err := rbac.Explain(userID, fiber.MethodDelete, "/api/v1/students")
+
+		assert.ErrorIs(t, err, middleware.ErrRouteNotMatched)
+	})
+}
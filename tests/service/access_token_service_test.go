@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPersonalAccessToken(t *testing.T) {
+	// Act & Assert
+	assert.True(t, service.IsPersonalAccessToken("uas_pat_Xk3bQ9"))
+	assert.False(t, service.IsPersonalAccessToken("eyJhbGciOiJSUzI1NiIsImtpZCI6IjEifQ.e30.sig"))
+	assert.False(t, service.IsPersonalAccessToken(""))
+}
+
+func TestAccessTokenService_CreateToken_ValidatesRequest(t *testing.T) {
+	// Arrange: validasi berjalan sebelum repository dipanggil
+	accessTokenService := service.NewAccessTokenService(nil, nil)
+
+	testCases := []struct {
+		name        string
+		req         model.CreateAccessTokenRequest
+		expectedErr string
+	}{
+		{
+			name:        "Missing label",
+			req:         model.CreateAccessTokenRequest{Label: "  ", Permissions: []string{"achievement.read"}},
+			expectedErr: "label is required",
+		},
+		{
+			name:        "Label too long",
+			req:         model.CreateAccessTokenRequest{Label: strings.Repeat("a", 101), Permissions: []string{"achievement.read"}},
+			expectedErr: "at most 100 characters",
+		},
+		{
+			name:        "Expiry too long",
+			req:         model.CreateAccessTokenRequest{Label: "script", Permissions: []string{"achievement.read"}, ExpiresInDays: 366},
+			expectedErr: "expires_in_days must be between 1 and 365",
+		},
+		{
+			name:        "Negative expiry",
+			req:         model.CreateAccessTokenRequest{Label: "script", Permissions: []string{"achievement.read"}, ExpiresInDays: -1},
+			expectedErr: "expires_in_days must be between 1 and 365",
+		},
+		{
+			name:        "No permissions",
+			req:         model.CreateAccessTokenRequest{Label: "script"},
+			expectedErr: "at least one permission is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			result, err := accessTokenService.CreateToken(uuid.New(), &tc.req)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountService_UpdateProfile_ValidatesFullName(t *testing.T) {
+	// Arrange: validasi berjalan sebelum repository dipanggil
+	accountService := service.NewAccountService(nil, nil, nil, nil, 24*time.Hour, "http://localhost:3000/confirm-email")
+
+	testCases := []struct {
+		name        string
+		fullName    string
+		expectedErr string
+	}{
+		{name: "Empty full name", fullName: "", expectedErr: "full name is required"},
+		{name: "Whitespace only", fullName: "   ", expectedErr: "full name is required"},
+		{name: "Too long", fullName: strings.Repeat("a", 101), expectedErr: "at most 100 characters"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			user, err := accountService.UpdateProfile(uuid.New(), &model.UpdateProfileRequest{FullName: tc.fullName})
+
+			// Assert
+			assert.Nil(t, user)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+// newLDAPAccountService - AccountService untuk user yang login lewat LDAP. Hanya query auth source yang
+// di-mock: perubahan harus ditolak sebelum data user dibaca atau password lokal dicek.
+func newLDAPAccountService(t *testing.T, userID uuid.UUID) (*service.AccountService, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery("SELECT COALESCE\\(NULLIF\\(u.auth_source").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"source"}).AddRow("ldap"))
+
+	return service.NewAccountService(repository.NewUserRepository(db), repository.NewAuthRepository(db), nil, nil, 24*time.Hour, "http://localhost:3000/confirm-email"), mock
+}
+
+func TestAccountService_UpdateProfile_RejectsExternalUser(t *testing.T) {
+	// Arrange
+	userID := uuid.New()
+	accountService, mock := newLDAPAccountService(t, userID)
+
+	// Act
+	user, err := accountService.UpdateProfile(userID, &model.UpdateProfileRequest{FullName: "Dr. Siti Aminah"})
+
+	// Assert
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, service.ErrExternalPassword)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountService_RequestEmailChange_RejectsExternalUser(t *testing.T) {
+	// Arrange
+	userID := uuid.New()
+	accountService, mock := newLDAPAccountService(t, userID)
+
+	// Act
+	err := accountService.RequestEmailChange(userID, &model.EmailChangeRequest{NewEmail: "siti@uas.test", CurrentPassword: "stale-local-password"})
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrExternalPassword)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
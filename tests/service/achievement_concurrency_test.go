@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/workflow"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mongoWrites - Perintah tulis (update) yang dikirim ke MongoDB
+func mongoWrites(f *achievementFixture) int {
+	writes := 0
+	for _, evt := range f.mt.GetAllStartedEvents() {
+		if evt.CommandName == "update" {
+			writes++
+		}
+	}
+	return writes
+}
+
+func TestAchievementService_VerifyAchievement_StatusChangedConcurrently(t *testing.T) {
+	runAchievementTest(t, "already decided by another request", workflow.StatusSubmitted, func(t *testing.T, f *achievementFixture) {
+		// Arrange: status masih 'submitted' saat dibaca, sudah 'rejected' saat baris dikunci
+		f.expectLecturer(f.advisor)
+		f.expectReference()
+		f.expectStudentByID()
+		f.expectNoDelegations(f.advisor)
+		f.mockAchievement()
+		f.mock.ExpectBegin()
+		f.expectLock(workflow.StatusRejected, false)
+		f.mock.ExpectRollback()
+
+		// Act
+		resp, err := f.service.VerifyAchievement(context.Background(), f.advisor.UserID, f.reference.ID, true, "", nil)
+
+		// Assert: tidak ada UPDATE maupun riwayat yang ditulis
+		assert.Nil(t, resp)
+		assert.ErrorIs(t, err, workflow.ErrInvalidTransition)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_SubmitForVerification_StatusChangedConcurrently(t *testing.T) {
+	runAchievementTest(t, "already submitted by another request", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement()
+		f.mock.ExpectBegin()
+		f.expectLock(workflow.StatusSubmitted, false)
+		f.mock.ExpectRollback()
+
+		// Act
+		_, err := f.service.SubmitForVerification(context.Background(), f.student.UserID, f.reference.ID, nil)
+
+		// Assert
+		assert.ErrorIs(t, err, workflow.ErrInvalidTransition)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_DeleteAchievement_StatusChangedConcurrently(t *testing.T) {
+	runAchievementTest(t, "submitted before delete", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange: prestasi diajukan di antara pembacaan dan penghapusan
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mock.ExpectBegin()
+		f.expectLock(workflow.StatusSubmitted, false)
+		f.mock.ExpectRollback()
+
+		// Act
+		_, err := f.service.DeleteAchievement(context.Background(), f.student.UserID, f.reference.ID)
+
+		// Assert: dokumen MongoDB tidak ikut di-soft delete
+		assert.ErrorIs(t, err, workflow.ErrInvalidTransition)
+		assert.Zero(t, mongoWrites(f))
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_UpdateAchievement_StatusChangedConcurrently(t *testing.T) {
+	runAchievementTest(t, "deleted before edit is written", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement()
+		f.mock.ExpectBegin()
+		f.expectLock(workflow.StatusDraft, true)
+		f.mock.ExpectRollback()
+
+		// Act
+		_, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, validEditRequest())
+
+		// Assert: dokumen MongoDB tidak ditulis ulang
+		assert.ErrorIs(t, err, workflow.ErrInvalidTransition)
+		assert.Zero(t, mongoWrites(f))
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/workflow"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAchievementService_DeletedAchievement(t *testing.T) {
+	testCases := []struct {
+		name string
+		act  func(f *achievementFixture) error
+	}{
+		{name: "Edit", act: func(f *achievementFixture) error {
+			_, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, validEditRequest())
+			return err
+		}},
+		{name: "Submit", act: func(f *achievementFixture) error {
+			_, err := f.service.SubmitForVerification(context.Background(), f.student.UserID, f.reference.ID, nil)
+			return err
+		}},
+		{name: "Delete", act: func(f *achievementFixture) error {
+			_, err := f.service.DeleteAchievement(context.Background(), f.student.UserID, f.reference.ID)
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		runAchievementTest(t, tc.name, workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+			// Arrange: draft yang sudah di-soft delete
+			f.reference.IsDeleted = true
+			f.expectStudentByUserID()
+			f.expectReference()
+
+			// Act
+			err := tc.act(f)
+
+			// Assert: ditolak guard workflow sebelum MongoDB maupun transaksi status disentuh
+			assert.ErrorIs(t, err, workflow.NotDeleted.Err)
+			assert.Empty(t, f.mt.GetAllStartedEvents())
+			assert.NoError(t, f.mock.ExpectationsWereMet())
+		})
+	}
+}
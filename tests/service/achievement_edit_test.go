@@ -0,0 +1,203 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/service"
+	"UAS_BACKEND/domain/workflow"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// validEditRequest - Request edit prestasi yang lolos validasi
+func validEditRequest() *service.SubmitAchievementRequest {
+	return &service.SubmitAchievementRequest{
+		AchievementType: "competition",
+		Title:           "Juara 1 Hackathon Nasional",
+		Details:         map[string]interface{}{"competitionLevel": "national"},
+		Points:          100,
+	}
+}
+
+// updatedAttachments - Field attachments di $set perintah update yang dikirim ke MongoDB
+func updatedAttachments(t *testing.T, f *achievementFixture) bson.RawValue {
+	for _, evt := range f.mt.GetAllStartedEvents() {
+		if evt.CommandName == "update" {
+			return evt.Command.Lookup("updates", "0", "u", "$set", "attachments")
+		}
+	}
+	require.Fail(t, "no update command sent to MongoDB")
+	return bson.RawValue{}
+}
+
+func TestAchievementService_UpdateAchievement_NotOwner(t *testing.T) {
+	runAchievementTest(t, "rejected before MongoDB is touched", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange: prestasi milik mahasiswa lain
+		f.reference.StudentID = uuid.New()
+		f.expectStudentByUserID()
+		f.expectReference()
+
+		// Act
+		resp, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, validEditRequest())
+
+		// Assert
+		assert.Nil(t, resp)
+		assert.EqualError(t, err, "unauthorized: achievement does not belong to you")
+		assert.Empty(t, f.mt.GetAllStartedEvents())
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_UpdateAchievement_NotEditable(t *testing.T) {
+	runAchievementTest(t, "submitted achievement", workflow.StatusSubmitted, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.expectStudentByUserID()
+		f.expectReference()
+
+		// Act
+		_, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, validEditRequest())
+
+		// Assert
+		assert.ErrorIs(t, err, workflow.ErrInvalidTransition)
+		assert.Empty(t, f.mt.GetAllStartedEvents())
+	})
+}
+
+func TestAchievementService_UpdateAchievement_ValidatesRequest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(req *service.SubmitAchievementRequest)
+		message string
+	}{
+		{name: "Missing title", modify: func(req *service.SubmitAchievementRequest) { req.Title = "" }, message: "title is required"},
+		{name: "Missing type", modify: func(req *service.SubmitAchievementRequest) { req.AchievementType = "" }, message: "achievement type is required"},
+		{name: "Unknown type", modify: func(req *service.SubmitAchievementRequest) { req.AchievementType = "sports" }, message: "invalid achievement type"},
+	}
+
+	for _, tc := range testCases {
+		runAchievementTest(t, tc.name, workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+			// Arrange
+			f.expectStudentByUserID()
+			f.expectReference()
+			req := validEditRequest()
+			tc.modify(req)
+
+			// Act
+			_, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, req)
+
+			// Assert: dokumen MongoDB tidak dibaca maupun ditulis
+			assert.EqualError(t, err, tc.message)
+			assert.Empty(t, f.mt.GetAllStartedEvents())
+		})
+	}
+}
+
+func TestAchievementService_UpdateAchievement_Attachments(t *testing.T) {
+	oldAttachment := bson.D{
+		{Key: "fileName", Value: "sertifikat.pdf"},
+		{Key: "fileUrl", Value: "/uploads/sertifikat.pdf"},
+		{Key: "fileType", Value: "application/pdf"},
+		{Key: "uploadedAt", Value: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	runAchievementTest(t, "kept when omitted", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange: request tanpa field attachments
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement(bson.E{Key: "attachments", Value: bson.A{oldAttachment}})
+		f.expectEdit()
+		f.mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		// Act
+		resp, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, validEditRequest())
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, resp.Achievement.Attachments, 1)
+		assert.Equal(t, "sertifikat.pdf", resp.Achievement.Attachments[0].FileName)
+		assert.Equal(t, "Juara 1 Hackathon Nasional", resp.Achievement.Title)
+
+		written, ok := updatedAttachments(t, f).ArrayOK()
+		require.True(t, ok)
+		values, err := written.Values()
+		require.NoError(t, err)
+		require.Len(t, values, 1)
+		assert.Equal(t, "sertifikat.pdf", values[0].Document().Lookup("fileName").StringValue())
+	})
+
+	runAchievementTest(t, "replaced when sent", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange: attachments kosong = hapus semua lampiran
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement(bson.E{Key: "attachments", Value: bson.A{oldAttachment}})
+		f.expectEdit()
+		f.mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		req := validEditRequest()
+		req.Attachments = []service.AttachmentRequest{}
+
+		// Act
+		resp, err := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, resp.Achievement.Attachments)
+		written, ok := updatedAttachments(t, f).ArrayOK()
+		require.True(t, ok)
+		values, err := written.Values()
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+}
+
+func TestAchievementService_EditRejectedAndResubmit_KeepsRejectionNote(t *testing.T) {
+	runAchievementTest(t, "rejected to submitted", workflow.StatusRejected, func(t *testing.T, f *achievementFixture) {
+		// Arrange: prestasi ditolak dengan catatan dosen wali
+		rejectionNote := "Sertifikat tidak terbaca"
+		f.reference.RejectionNote = &rejectionNote
+
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement()
+		f.expectEdit()
+		f.mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		// Act: perbaiki
+		edited, editErr := f.service.UpdateAchievement(context.Background(), f.student.UserID, f.reference.ID, validEditRequest())
+
+		// Assert: status tetap 'rejected' dan catatan penolakan ikut dikembalikan
+		require.NoError(t, editErr)
+		assert.Equal(t, workflow.StatusRejected, edited.Status)
+		require.NotNil(t, edited.RejectionNote)
+		assert.Equal(t, rejectionNote, *edited.RejectionNote)
+
+		// Arrange: ajukan ulang, UPDATE reference harus tetap menulis rejection_note lama
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement()
+		f.mock.ExpectBegin()
+		f.expectLock(workflow.StatusRejected, false)
+		f.mock.ExpectExec("UPDATE achievement_references").
+			WithArgs(workflow.StatusSubmitted, nil, nil, rejectionNote, sqlmock.AnyArg(), nil, f.reference.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		f.mock.ExpectExec("INSERT INTO achievement_status_history").
+			WithArgs(sqlmock.AnyArg(), f.reference.ID, workflow.StatusRejected, workflow.StatusSubmitted, f.student.UserID, workflow.RoleStudent, nil, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		f.mock.ExpectCommit()
+
+		// Act: ajukan ulang
+		submitted, submitErr := f.service.SubmitForVerification(context.Background(), f.student.UserID, f.reference.ID, nil)
+
+		// Assert
+		require.NoError(t, submitErr)
+		assert.Equal(t, workflow.StatusSubmitted, submitted.Status)
+		require.NotNil(t, submitted.PreviousRejectionNote)
+		assert.Equal(t, rejectionNote, *submitted.PreviousRejectionNote)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
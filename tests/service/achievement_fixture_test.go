@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/service"
+	"UAS_BACKEND/domain/workflow"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// achievementFixture - AchievementService dengan PostgreSQL (sqlmock) dan MongoDB (mtest mock) palsu,
+// satu mahasiswa beserta dosen walinya, dan satu prestasi milik mahasiswa tersebut
+type achievementFixture struct {
+	mt        *mtest.T
+	mock      sqlmock.Sqlmock
+	service   *service.AchievementService
+	rbac      *service.RBACService
+	student   *model.Student
+	advisor   *model.Lecturer
+	reference *model.AchievementReference
+	mongoID   primitive.ObjectID
+}
+
+// runAchievementTest - Jalankan test dengan fixture baru, status = status awal prestasi
+func runAchievementTest(t *testing.T, name, status string, test func(t *testing.T, f *achievementFixture)) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run(name, func(mt *mtest.T) {
+		db, mock := newMockDB(mt.T)
+		advisor := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), LecturerID: "D001", Department: "Teknik Informatika"}
+		student := &model.Student{ID: uuid.New(), UserID: uuid.New(), StudentID: "M001", ProgramStudy: "Teknik Informatika", AdvisorID: advisor.ID}
+		mongoID := primitive.NewObjectID()
+
+		achievementRepo := repository.NewAchievementRepository(db, mt.DB)
+		rbac := &service.RBACService{Cache: service.NewPermissionCache(time.Minute)}
+		f := &achievementFixture{
+			mt:      mt,
+			mock:    mock,
+			service: service.NewAchievementService(achievementRepo, repository.NewDelegationRepository(db), rbac),
+			rbac:    rbac,
+			student: student,
+			advisor: advisor,
+			reference: &model.AchievementReference{
+				ID: uuid.New(), StudentID: student.ID, MongoAchievementID: mongoID.Hex(), Status: status,
+			},
+			mongoID: mongoID,
+		}
+		f.grantRoles(student.UserID, workflow.RoleStudent)
+		f.grantRoles(advisor.UserID, workflow.RoleLecturer)
+		test(mt.T, f)
+	})
+}
+
+// grantRoles - Role RBAC user (disimpan di cache permission, tanpa query ke database)
+func (f *achievementFixture) grantRoles(userID uuid.UUID, roles ...string) {
+	f.rbac.Cache.Set(userID, &model.PermissionState{IsActive: true, RoleNames: roles})
+}
+
+// expectLecturer - Query lecturers berdasarkan user_id
+func (f *achievementFixture) expectLecturer(lecturer *model.Lecturer) {
+	f.mock.ExpectQuery("FROM lecturers\\s+WHERE user_id = \\$1").WithArgs(lecturer.UserID).WillReturnRows(lecturerRow(lecturer))
+}
+
+// expectStudentByUserID - Query students berdasarkan user_id
+func (f *achievementFixture) expectStudentByUserID() {
+	f.mock.ExpectQuery("FROM students\\s+WHERE user_id = \\$1").WithArgs(f.student.UserID).WillReturnRows(studentRow(f.student))
+}
+
+// expectStudentByID - Query students berdasarkan id
+func (f *achievementFixture) expectStudentByID() {
+	f.mock.ExpectQuery("FROM students\\s+WHERE id = \\$1").WithArgs(f.student.ID).WillReturnRows(studentRow(f.student))
+}
+
+// expectReference - Query achievement_references berdasarkan id
+func (f *achievementFixture) expectReference() {
+	ref := f.reference
+	rows := sqlmock.NewRows([]string{"id", "student_id", "mongo_achievement_id", "status", "submitted_at", "verified_at", "verified_by", "rejection_note", "delegation_id", "is_deleted", "deleted_at", "created_at", "updated_at"}).
+		AddRow(ref.ID, ref.StudentID, ref.MongoAchievementID, ref.Status, ref.SubmittedAt, ref.VerifiedAt, ref.VerifiedBy, ref.RejectionNote, ref.DelegationID, ref.IsDeleted, ref.DeletedAt, time.Now(), time.Now())
+	f.mock.ExpectQuery("FROM achievement_references\\s+WHERE id = \\$1").WithArgs(ref.ID).WillReturnRows(rows)
+}
+
+// expectNoDelegations - Dosen tidak sedang menerima delegasi
+func (f *achievementFixture) expectNoDelegations(lecturer *model.Lecturer) {
+	f.mock.ExpectQuery("WHERE d.delegate_id = \\$1").WithArgs(lecturer.ID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "delegator_id", "delegate_id", "starts_at", "ends_at", "student_ids", "reason", "created_by", "revoked_at", "created_at"}))
+}
+
+// mockAchievement - Dokumen achievement yang dikembalikan MongoDB untuk FindOne
+func (f *achievementFixture) mockAchievement(fields ...bson.E) {
+	doc := bson.D{{Key: "_id", Value: f.mongoID}, {Key: "title", Value: "Juara 1 Hackathon"}, {Key: "achievementType", Value: "competition"}}
+	f.mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.achievements", mtest.FirstBatch, append(doc, fields...)))
+}
+
+// expectLock - Baris reference dikunci di awal transaksi, status/isDeleted = isi baris saat dikunci
+func (f *achievementFixture) expectLock(status string, isDeleted bool) {
+	f.mock.ExpectQuery("SELECT status, is_deleted FROM achievement_references WHERE id = \\$1 FOR UPDATE").WithArgs(f.reference.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "is_deleted"}).AddRow(status, isDeleted))
+}
+
+// expectEdit - Transaksi edit: kunci baris, (update dokumen MongoDB), UPDATE updated_at reference
+func (f *achievementFixture) expectEdit() {
+	f.mock.ExpectBegin()
+	f.expectLock(f.reference.Status, false)
+	f.mock.ExpectExec("UPDATE achievement_references SET updated_at = \\$1 WHERE id = \\$2").
+		WithArgs(sqlmock.AnyArg(), f.reference.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	f.mock.ExpectCommit()
+}
+
+// expectStatusChange - Transaksi perubahan status: kunci baris, UPDATE reference, INSERT riwayat.
+// historyDelegation = nilai delegation_id yang harus ditulis ke riwayat.
+func (f *achievementFixture) expectStatusChange(toStatus string, actorID uuid.UUID, actorRole string, historyDelegation driver.Value) {
+	f.mock.ExpectBegin()
+	f.expectLock(f.reference.Status, false)
+	f.mock.ExpectExec("UPDATE achievement_references").WillReturnResult(sqlmock.NewResult(0, 1))
+	f.mock.ExpectExec("INSERT INTO achievement_status_history").
+		WithArgs(sqlmock.AnyArg(), f.reference.ID, f.reference.Status, toStatus, actorID, actorRole, sqlmock.AnyArg(), historyDelegation, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	f.mock.ExpectCommit()
+}
+
+// studentRow - Baris students (id, user_id, student_id, program_study, academic_year, advisor_id, created_at)
+func studentRow(student *model.Student) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "user_id", "student_id", "program_study", "academic_year", "advisor_id", "created_at"}).
+		AddRow(student.ID, student.UserID, student.StudentID, student.ProgramStudy, student.AcademicYear, student.AdvisorID, time.Now())
+}
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/service"
+	"UAS_BACKEND/domain/workflow"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// historyRows - Kolom hasil query GetAchievementStatusHistory
+func historyRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "achievement_ref_id", "from_status", "to_status", "actor_user_id", "full_name", "actor_role", "note", "delegation_id", "created_at"})
+}
+
+func TestAchievementService_GetAchievementHistory(t *testing.T) {
+	runAchievementTest(t, "oldest first with creation and deletion", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange: dibuat (from_status NULL), diajukan, lalu dihapus (to_status 'deleted')
+		start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+		rows := historyRows().
+			AddRow(uuid.New(), f.reference.ID, nil, "draft", f.student.UserID, "Budi Santoso", "student", nil, nil, start).
+			AddRow(uuid.New(), f.reference.ID, "draft", "submitted", f.student.UserID, "Budi Santoso", "student", nil, nil, start.Add(time.Hour)).
+			AddRow(uuid.New(), f.reference.ID, "submitted", model.StatusDeleted, nil, "", "system", "Dihapus admin", nil, start.Add(2*time.Hour))
+
+		f.expectReference()
+		f.mock.ExpectQuery("FROM achievement_status_history h\\s+LEFT JOIN users u ON u.id = h.actor_user_id\\s+WHERE h.achievement_ref_id = \\$1\\s+ORDER BY h.created_at, h.id").
+			WithArgs(f.reference.ID).WillReturnRows(rows)
+
+		// Act
+		history, err := f.service.GetAchievementHistory(f.reference.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Nil(t, history[0].FromStatus)
+		assert.Equal(t, "draft", history[0].ToStatus)
+		assert.Equal(t, "Budi Santoso", history[0].ActorName)
+		assert.Equal(t, "draft", *history[1].FromStatus)
+		assert.Equal(t, "submitted", history[1].ToStatus)
+		assert.Equal(t, model.StatusDeleted, history[2].ToStatus)
+		assert.Nil(t, history[2].ActorUserID)
+		assert.True(t, history[0].CreatedAt.Before(history[2].CreatedAt))
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_GetAchievementHistory_Errors(t *testing.T) {
+	runAchievementTest(t, "unknown achievement", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.mock.ExpectQuery("FROM achievement_references\\s+WHERE id = \\$1").WithArgs(f.reference.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		// Act
+		_, err := f.service.GetAchievementHistory(f.reference.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, service.ErrAchievementNotFound)
+	})
+
+	runAchievementTest(t, "database error is not reported as not found", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.mock.ExpectQuery("FROM achievement_references\\s+WHERE id = \\$1").WithArgs(f.reference.ID).
+			WillReturnError(errors.New("connection refused"))
+
+		// Act
+		_, err := f.service.GetAchievementHistory(f.reference.ID)
+
+		// Assert
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, service.ErrAchievementNotFound)
+	})
+}
+
+func TestAchievementRepository_CreateAchievementReference_WritesInitialHistory(t *testing.T) {
+	// Arrange
+	db, mock := newMockDB(t)
+	repo := repository.NewAchievementRepository(db, nil)
+	ref := &model.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: "65f000000000000000000001", Status: workflow.StatusDraft}
+	actor := model.StatusActor{UserID: uuid.New(), Role: workflow.RoleStudent}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO achievement_references").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO achievement_status_history").
+		WithArgs(sqlmock.AnyArg(), ref.ID, nil, workflow.StatusDraft, actor.UserID, workflow.RoleStudent, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Act
+	err := repo.CreateAchievementReference(ref, actor)
+
+	// Assert: from_status NULL ditulis dalam transaksi yang sama dengan reference
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_StatusChange_RollsBackWithoutHistory(t *testing.T) {
+	// Arrange: INSERT riwayat gagal
+	db, mock := newMockDB(t)
+	repo := repository.NewAchievementRepository(db, nil)
+	refID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status, is_deleted FROM achievement_references WHERE id = \\$1 FOR UPDATE").WithArgs(refID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "is_deleted"}).AddRow(workflow.StatusDraft, false))
+	mock.ExpectExec("UPDATE achievement_references").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO achievement_status_history").WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+
+	// Act
+	err := repo.UpdateAchievementReferenceStatus(refID, workflow.StatusDraft, workflow.StatusSubmitted, nil, nil, model.StatusActor{UserID: uuid.New(), Role: workflow.RoleStudent}, nil)
+
+	// Assert: perubahan status ikut dibatalkan, tidak ada commit
+	require.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementService_DeleteAchievement_RecordsDeletedHistory(t *testing.T) {
+	runAchievementTest(t, "draft soft deleted", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		f.mock.ExpectBegin()
+		f.expectLock(workflow.StatusDraft, false)
+		f.mock.ExpectExec("UPDATE achievement_references\\s+SET is_deleted = true").WillReturnResult(sqlmock.NewResult(0, 1))
+		f.mock.ExpectExec("INSERT INTO achievement_status_history").
+			WithArgs(sqlmock.AnyArg(), f.reference.ID, workflow.StatusDraft, model.StatusDeleted, f.student.UserID, workflow.RoleStudent, nil, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		f.mock.ExpectCommit()
+
+		// Act
+		_, err := f.service.DeleteAchievement(context.Background(), f.student.UserID, f.reference.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
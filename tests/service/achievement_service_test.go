@@ -243,7 +243,7 @@ func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 	mockRepo.On("GetUserByID", userID).Return(studentUser, nil)
 	mockRepo.On("GetLecturerByID", advisorID).Return(advisorInfo, nil)
 
-	mockNotificationService.On("CreateAchievementSubmittedNotification", 
+	mockNotificationService.On("CreateAchievementSubmittedNotification",
 		advisorInfo.UserID, studentUser.FullName, achievement.Title, referenceID).Return(nil)
 
 	// Act
@@ -297,7 +297,7 @@ func TestAchievementService_SubmitForVerification_WrongStatus(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "achievement must be in 'draft' status")
+	assert.Contains(t, err.Error(), "achievement must be in 'draft' or 'rejected' status to submit")
 
 	mockRepo.AssertExpectations(t)
 }
@@ -387,7 +387,7 @@ func TestAchievementService_DeleteAchievement_WrongStatus(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "only draft achievements can be deleted")
+	assert.Contains(t, err.Error(), "achievement must be in 'draft' status to delete")
 
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/workflow"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAchievementService_VerifyAchievement_ByDelegate(t *testing.T) {
+	runAchievementTest(t, "delegation recorded with the decision", workflow.StatusSubmitted, func(t *testing.T, f *achievementFixture) {
+		// Arrange: dosen pengganti memegang delegasi aktif dari dosen wali mahasiswa
+		delegate := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), LecturerID: "D002", Department: "Teknik Informatika"}
+		delegationID := uuid.New()
+		f.grantRoles(delegate.UserID, workflow.RoleLecturer)
+
+		f.expectLecturer(delegate)
+		f.expectReference()
+		f.expectStudentByID()
+		f.mock.ExpectQuery("WHERE d.delegate_id = \\$1").WithArgs(delegate.ID, sqlmock.AnyArg()).
+			WillReturnRows(delegationRow(delegationID, f.advisor.ID, delegate.ID))
+		f.mockAchievement()
+		f.expectStatusChange(workflow.StatusVerified, delegate.UserID, workflow.RoleLecturer, delegationID)
+
+		// Act
+		resp, err := f.service.VerifyAchievement(context.Background(), delegate.UserID, f.reference.ID, true, "", nil)
+
+		// Assert: delegation_id ditulis ke riwayat di dalam transaksi perubahan status
+		require.NoError(t, err)
+		require.NotNil(t, resp.DelegationID)
+		assert.Equal(t, delegationID, *resp.DelegationID)
+		assert.Equal(t, f.advisor.ID, *resp.OnBehalfOf)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_VerifyAchievement_ByAdvisor(t *testing.T) {
+	runAchievementTest(t, "no delegation recorded", workflow.StatusSubmitted, func(t *testing.T, f *achievementFixture) {
+		// Arrange
+		f.expectLecturer(f.advisor)
+		f.expectReference()
+		f.expectStudentByID()
+		f.expectNoDelegations(f.advisor)
+		f.mockAchievement()
+		f.expectStatusChange(workflow.StatusRejected, f.advisor.UserID, workflow.RoleLecturer, nil)
+
+		// Act
+		resp, err := f.service.VerifyAchievement(context.Background(), f.advisor.UserID, f.reference.ID, false, "Sertifikat tidak terbaca", nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, workflow.StatusRejected, resp.Status)
+		assert.Nil(t, resp.DelegationID)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_VerifyAchievement_RequiresLecturerRole(t *testing.T) {
+	runAchievementTest(t, "lecturer profile without lecturer role", workflow.StatusSubmitted, func(t *testing.T, f *achievementFixture) {
+		// Arrange: dosen wali yang role RBAC-nya sudah diganti menjadi admin
+		f.grantRoles(f.advisor.UserID, "admin")
+		f.expectLecturer(f.advisor)
+		f.expectReference()
+
+		// Act
+		resp, err := f.service.VerifyAchievement(context.Background(), f.advisor.UserID, f.reference.ID, true, "", nil)
+
+		// Assert: ditolak sebelum status dikunci maupun diubah
+		assert.Nil(t, resp)
+		assert.ErrorIs(t, err, workflow.ErrRoleNotAllowed)
+		assert.Empty(t, f.mt.GetAllStartedEvents())
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
+
+func TestAchievementService_SubmitForVerification_RecordsRBACRole(t *testing.T) {
+	runAchievementTest(t, "student with several roles", workflow.StatusDraft, func(t *testing.T, f *achievementFixture) {
+		// Arrange: role tambahan di luar transisi tidak dipakai sebagai actor_role
+		f.grantRoles(f.student.UserID, "admin", workflow.RoleStudent)
+		f.expectStudentByUserID()
+		f.expectReference()
+		f.mockAchievement()
+		f.expectStatusChange(workflow.StatusSubmitted, f.student.UserID, workflow.RoleStudent, nil)
+
+		// Act
+		_, err := f.service.SubmitForVerification(context.Background(), f.student.UserID, f.reference.ID, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoError(t, f.mock.ExpectationsWereMet())
+	})
+}
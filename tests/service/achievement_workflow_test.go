@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/workflow"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Fire(t *testing.T) {
+	testCases := []struct {
+		name   string
+		action string
+		role   string
+		status string
+		to     string
+		err    error
+	}{
+		{name: "Submit draft", action: workflow.ActionSubmit, role: workflow.RoleStudent, status: workflow.StatusDraft, to: workflow.StatusSubmitted},
+		{name: "Resubmit rejected", action: workflow.ActionSubmit, role: workflow.RoleStudent, status: workflow.StatusRejected, to: workflow.StatusSubmitted},
+		{name: "Verify submitted", action: workflow.ActionVerify, role: workflow.RoleLecturer, status: workflow.StatusSubmitted, to: workflow.StatusVerified},
+		{name: "Reject submitted", action: workflow.ActionReject, role: workflow.RoleLecturer, status: workflow.StatusSubmitted, to: workflow.StatusRejected},
+		{name: "Delete draft", action: workflow.ActionDelete, role: workflow.RoleStudent, status: workflow.StatusDraft, to: model.StatusDeleted},
+		{name: "Edit keeps status", action: workflow.ActionEdit, role: workflow.RoleStudent, status: workflow.StatusRejected, to: workflow.StatusRejected},
+		{name: "Verify draft", action: workflow.ActionVerify, role: workflow.RoleLecturer, status: workflow.StatusDraft, err: workflow.ErrInvalidTransition},
+		{name: "Delete submitted", action: workflow.ActionDelete, role: workflow.RoleStudent, status: workflow.StatusSubmitted, err: workflow.ErrInvalidTransition},
+		{name: "Student verifies", action: workflow.ActionVerify, role: workflow.RoleStudent, status: workflow.StatusSubmitted, err: workflow.ErrRoleNotAllowed},
+		{name: "Unknown action", action: "archive", role: workflow.RoleStudent, status: workflow.StatusDraft, err: workflow.ErrUnknownAction},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ref := &model.AchievementReference{Status: tc.status}
+
+			// Act
+			to, err := workflow.Achievement.Fire(tc.action, tc.role, ref)
+
+			// Assert
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.to, to)
+		})
+	}
+}
+
+func TestWorkflow_Fire_DeletedGuard(t *testing.T) {
+	// Arrange
+	ref := &model.AchievementReference{Status: workflow.StatusDraft, IsDeleted: true}
+
+	// Act
+	_, err := workflow.Achievement.Fire(workflow.ActionDelete, workflow.RoleStudent, ref)
+
+	// Assert
+	assert.ErrorIs(t, err, workflow.NotDeleted.Err)
+}
+
+func TestWorkflow_RoleFor(t *testing.T) {
+	// Act & Assert: role dicocokkan dengan semua role RBAC user, bukan role yang ditebak pemanggil
+	role, err := workflow.Achievement.RoleFor(workflow.ActionVerify, []string{"admin", workflow.RoleLecturer})
+	require.NoError(t, err)
+	assert.Equal(t, workflow.RoleLecturer, role)
+
+	_, err = workflow.Achievement.RoleFor(workflow.ActionVerify, []string{workflow.RoleStudent})
+	assert.ErrorIs(t, err, workflow.ErrRoleNotAllowed)
+
+	_, err = workflow.Achievement.RoleFor("archive", []string{workflow.RoleStudent})
+	assert.ErrorIs(t, err, workflow.ErrUnknownAction)
+}
+
+func TestWorkflow_AvailableActions(t *testing.T) {
+	// Arrange
+	draft := &model.AchievementReference{Status: workflow.StatusDraft}
+	submitted := &model.AchievementReference{Status: workflow.StatusSubmitted}
+
+	// Act & Assert
+	assert.Equal(t, []string{workflow.ActionEdit, workflow.ActionSubmit, workflow.ActionDelete}, workflow.Achievement.AvailableActions(workflow.RoleStudent, draft))
+	assert.Equal(t, []string{workflow.ActionVerify, workflow.ActionReject}, workflow.Achievement.AvailableActions(workflow.RoleLecturer, submitted))
+	assert.Empty(t, workflow.Achievement.AvailableActions(workflow.RoleStudent, submitted))
+}
+
+func TestWorkflow_New_CustomState(t *testing.T) {
+	// Arrange: status baru needs_revision tanpa mengubah service
+	const needsRevision = "needs_revision"
+	states := []string{workflow.StatusDraft, workflow.StatusSubmitted, needsRevision}
+	transitions := []workflow.Transition{
+		{Action: "request_revision", From: []string{workflow.StatusSubmitted}, To: needsRevision, Roles: []string{workflow.RoleLecturer}},
+		{Action: workflow.ActionSubmit, From: []string{workflow.StatusDraft, needsRevision}, To: workflow.StatusSubmitted, Roles: []string{workflow.RoleStudent}},
+	}
+
+	// Act
+	machine, err := workflow.New(workflow.StatusDraft, states, transitions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, machine.Can(workflow.ActionSubmit, workflow.RoleStudent, &model.AchievementReference{Status: needsRevision}))
+	assert.False(t, machine.Can(workflow.ActionVerify, workflow.RoleLecturer, &model.AchievementReference{Status: workflow.StatusSubmitted}))
+}
+
+func TestWorkflow_New_Invalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		initial     string
+		transitions []workflow.Transition
+	}{
+		{name: "Unknown initial", initial: "archived"},
+		{name: "Unknown target", initial: workflow.StatusDraft, transitions: []workflow.Transition{
+			{Action: workflow.ActionSubmit, From: []string{workflow.StatusDraft}, To: "under_review", Roles: []string{workflow.RoleStudent}},
+		}},
+		{name: "Duplicate action", initial: workflow.StatusDraft, transitions: []workflow.Transition{
+			{Action: workflow.ActionEdit, From: []string{workflow.StatusDraft}, Roles: []string{workflow.RoleStudent}},
+			{Action: workflow.ActionEdit, From: []string{workflow.StatusDraft}, Roles: []string{workflow.RoleStudent}},
+		}},
+		{name: "No role", initial: workflow.StatusDraft, transitions: []workflow.Transition{
+			{Action: workflow.ActionEdit, From: []string{workflow.StatusDraft}},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := workflow.New(tc.initial, []string{workflow.StatusDraft}, tc.transitions)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/service"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgramStudyScope_Global(t *testing.T) {
+	// Arrange: nil dan scope tanpa program studi sama-sama admin global
+	var nilScope *model.ProgramStudyScope
+	empty := &model.ProgramStudyScope{}
+
+	// Act & Assert
+	assert.True(t, nilScope.IsGlobal())
+	assert.True(t, empty.IsGlobal())
+	assert.True(t, nilScope.Allows("Teknik Informatika"))
+	assert.True(t, empty.Allows(""))
+	assert.Nil(t, nilScope.Lowered())
+}
+
+func TestProgramStudyScope_Scoped(t *testing.T) {
+	// Arrange
+	scope := &model.ProgramStudyScope{ProgramStudies: []string{"Teknik Informatika", " Sistem Informasi "}}
+
+	// Act & Assert: pencocokan tanpa membedakan huruf besar/kecil dan spasi di tepi
+	assert.False(t, scope.IsGlobal())
+	assert.True(t, scope.Allows("teknik informatika"))
+	assert.True(t, scope.Allows("Sistem Informasi"))
+	assert.False(t, scope.Allows("Manajemen"))
+	assert.False(t, scope.Allows(""))
+	assert.Equal(t, []string{"teknik informatika", "sistem informasi"}, scope.Lowered())
+}
+
+func TestUserService_AuthorizeProfile(t *testing.T) {
+	// Arrange
+	userService := &service.UserService{}
+	scope := &model.ProgramStudyScope{ProgramStudies: []string{"Teknik Informatika"}}
+
+	// Act & Assert
+	assert.NoError(t, userService.AuthorizeProfile(scope, "TEKNIK INFORMATIKA"))
+	assert.ErrorIs(t, userService.AuthorizeProfile(scope, "Manajemen"), service.ErrOutOfScope)
+	assert.NoError(t, userService.AuthorizeProfile(nil, "Manajemen"))
+}
+
+func TestUserService_AuthorizeNewUser_GlobalAdmin(t *testing.T) {
+	// Arrange: admin global boleh membuat user apa pun, termasuk tanpa profil
+	userService := &service.UserService{}
+
+	// Act
+	err := userService.AuthorizeNewUser(&model.ProgramStudyScope{}, uuid.New(), nil, nil)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestRBACService_AdminScope(t *testing.T) {
+	// Arrange
+	userID := uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		IsActive:   true,
+		RoleNames:  []string{"admin"},
+		AdminScope: []string{"Teknik Informatika"},
+	})
+
+	// Act
+	scope, err := rbac.AdminScope(userID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, scope.IsGlobal())
+	assert.Equal(t, []string{"Teknik Informatika"}, scope.ProgramStudies)
+}
+
+// lecturerRow - Baris lecturers (id, user_id, lecturer_id, department, created_at)
+func lecturerRow(lecturer *model.Lecturer) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "user_id", "lecturer_id", "department", "created_at"}).
+		AddRow(lecturer.ID, lecturer.UserID, lecturer.LecturerID, lecturer.Department, time.Now())
+}
+
+func TestUserService_AuthorizeAdvisor(t *testing.T) {
+	// Arrange
+	advisor := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), LecturerID: "D001", Department: "Manajemen"}
+	db, mock := newMockDB(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), nil, nil, nil)
+	scope := &model.ProgramStudyScope{ProgramStudies: []string{"Teknik Informatika"}}
+
+	mock.ExpectQuery("FROM lecturers\\s+WHERE id = \\$1").WithArgs(advisor.ID).WillReturnRows(lecturerRow(advisor))
+
+	// Act
+	globalErr := userService.AuthorizeAdvisor(&model.ProgramStudyScope{}, advisor.ID)
+	scopedErr := userService.AuthorizeAdvisor(scope, advisor.ID)
+
+	// Assert: admin global tidak perlu query, admin ter-scope ditolak untuk dosen di luar program studinya
+	assert.NoError(t, globalErr)
+	assert.ErrorIs(t, scopedErr, service.ErrOutOfScope)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserService_AuthorizeRole_CustomAdminRole(t *testing.T) {
+	testCases := []struct {
+		name       string
+		permission string
+		err        error
+	}{
+		{name: "Direct admin.manage", permission: "admin.manage", err: service.ErrScopedAdminRole},
+		{name: "Wildcard", permission: "*.*", err: service.ErrScopedAdminRole},
+		{name: "Non-admin permission", permission: "achievement.*"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange: role custom bernama selain "admin"
+			db, mock := newMockDB(t)
+			rbac := service.NewRBACService(repository.NewRBACRepository(db))
+			userService := service.NewUserService(repository.NewUserRepository(db), nil, nil, rbac)
+			scope := &model.ProgramStudyScope{ProgramStudies: []string{"Teknik Informatika"}}
+			role := model.Roles{ID: uuid.New(), Name: "kaprodi"}
+
+			mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(role.ID).WillReturnRows(roleRows(role))
+			mock.ExpectQuery("INNER JOIN role_permissions").WithArgs(role.ID).
+				WillReturnRows(permissionRows(model.Permission{ID: uuid.New(), Name: tc.permission}))
+
+			// Act
+			err := userService.AuthorizeRole(scope, role.ID)
+
+			// Assert
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// newScopedDelegationService - DelegationService dengan admin ter-scope Teknik Informatika yang bukan dosen
+func newScopedDelegationService(t *testing.T) (*service.DelegationService, sqlmock.Sqlmock, uuid.UUID) {
+	adminID := uuid.New()
+	db, mock := newMockDB(t)
+	rbac := newCachedRBACService(adminID, &model.PermissionState{
+		IsActive: true, RoleNames: []string{"admin"}, Permissions: []string{"admin.manage"},
+		AdminScope: []string{"Teknik Informatika"},
+	})
+
+	mock.ExpectQuery("FROM lecturers\\s+WHERE user_id = \\$1").WithArgs(adminID).WillReturnError(sql.ErrNoRows)
+
+	delegationService := service.NewDelegationService(repository.NewDelegationRepository(db), repository.NewAchievementRepository(db, nil), rbac, nil)
+	return delegationService, mock, adminID
+}
+
+func TestDelegationService_CreateDelegation_ScopedAdminOutsideScope(t *testing.T) {
+	// Arrange: dosen wali di luar program studi admin
+	delegationService, mock, adminID := newScopedDelegationService(t)
+	delegator := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), LecturerID: "D001", Department: "Manajemen"}
+	delegate := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), LecturerID: "D002", Department: "Manajemen"}
+
+	mock.ExpectQuery("FROM lecturers\\s+WHERE id = \\$1").WithArgs(delegator.ID).WillReturnRows(lecturerRow(delegator))
+	mock.ExpectQuery("FROM lecturers\\s+WHERE id = \\$1").WithArgs(delegate.ID).WillReturnRows(lecturerRow(delegate))
+
+	// Act
+	_, err := delegationService.CreateDelegation(adminID, &model.CreateDelegationRequest{
+		DelegatorID: &delegator.ID,
+		DelegateID:  delegate.ID,
+		EndsAt:      time.Now().Add(24 * time.Hour),
+	})
+
+	// Assert: ditolak sebelum delegasi disimpan
+	assert.ErrorIs(t, err, service.ErrOutOfScope)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationService_RevokeDelegation_ScopedAdminOutsideScope(t *testing.T) {
+	// Arrange
+	delegationService, mock, adminID := newScopedDelegationService(t)
+	delegator := &model.Lecturer{ID: uuid.New(), UserID: uuid.New(), LecturerID: "D001", Department: "Manajemen"}
+	delegationID := uuid.New()
+
+	mock.ExpectQuery("FROM verification_delegations d\\s+WHERE d.id = \\$1").WithArgs(delegationID).
+		WillReturnRows(delegationRow(delegationID, delegator.ID, uuid.New()))
+	mock.ExpectQuery("FROM lecturers\\s+WHERE id = \\$1").WithArgs(delegator.ID).WillReturnRows(lecturerRow(delegator))
+
+	// Act
+	err := delegationService.RevokeDelegation(adminID, delegationID)
+
+	// Assert: UPDATE revoked_at tidak dijalankan
+	assert.ErrorIs(t, err, service.ErrOutOfScope)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// delegationRow - Baris verification_delegations sesuai delegationColumns, tanpa subset mahasiswa
+func delegationRow(id, delegatorID, delegateID uuid.UUID) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "delegator_id", "delegate_id", "starts_at", "ends_at", "student_ids", "reason", "created_by", "revoked_at", "created_at"}).
+		AddRow(id, delegatorID, delegateID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "{}", "", nil, nil, time.Now())
+}
@@ -0,0 +1,32 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonationAllows_ReadOnly(t *testing.T) {
+	// Membaca data user yang di-impersonate boleh
+	assert.True(t, service.ImpersonationAllows("GET", "/api/v1/achievements"))
+	assert.True(t, service.ImpersonationAllows("GET", "/api/v1/me"))
+	assert.True(t, service.ImpersonationAllows("HEAD", "/api/v1/achievements/123"))
+
+	// Aksi yang mengubah data ditolak
+	assert.False(t, service.ImpersonationAllows("POST", "/api/v1/achievements"))
+	assert.False(t, service.ImpersonationAllows("PUT", "/api/v1/me/password"))
+	assert.False(t, service.ImpersonationAllows("DELETE", "/api/v1/auth/sessions"))
+	assert.False(t, service.ImpersonationAllows("POST", "/api/v1/me/tokens"))
+}
+
+func TestImpersonationAllows_AdminAreaBlocked(t *testing.T) {
+	assert.False(t, service.ImpersonationAllows("GET", "/api/admin"))
+	assert.False(t, service.ImpersonationAllows("GET", "/api/admin/users"))
+	assert.False(t, service.ImpersonationAllows("GET", "/api/admin/impersonation-logs"))
+	assert.True(t, service.ImpersonationAllows("GET", "/api/administrasi"))
+}
+
+func TestImpersonationAllows_LogoutEndsImpersonation(t *testing.T) {
+	assert.True(t, service.ImpersonationAllows("POST", "/api/v1/auth/logout"))
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvitationService_InviteUser_ValidatesRequest(t *testing.T) {
+	// Arrange: validasi berjalan sebelum repository dipanggil
+	invitationService := service.NewInvitationService(nil, nil, nil, nil, 72*time.Hour, "http://localhost:3000/accept-invite")
+	valid := service.InviteUserRequest{
+		Username: "jane",
+		Email:    "jane@student.example.ac.id",
+		FullName: "Jane Smith",
+		RoleID:   uuid.New(),
+	}
+
+	testCases := []struct {
+		name        string
+		modify      func(req *service.InviteUserRequest)
+		expectedErr string
+	}{
+		{name: "Missing username", modify: func(req *service.InviteUserRequest) { req.Username = "" }, expectedErr: "username is required"},
+		{name: "Missing email", modify: func(req *service.InviteUserRequest) { req.Email = "" }, expectedErr: "email is required"},
+		{name: "Missing full name", modify: func(req *service.InviteUserRequest) { req.FullName = "" }, expectedErr: "full name is required"},
+		{name: "Missing role", modify: func(req *service.InviteUserRequest) { req.RoleID = uuid.Nil }, expectedErr: "role ID is required"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid
+			tc.modify(&req)
+
+			// Act
+			response, err := invitationService.InviteUser(&req, uuid.New())
+
+			// Assert
+			assert.Nil(t, response)
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
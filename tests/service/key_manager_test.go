@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/service"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEd25519Key(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600))
+
+	return priv
+}
+
+func writeRSAPublicKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pub.pem"), data, 0600))
+
+	return priv
+}
+
+func TestKeyManager_SignAndVerify_ActiveKey(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2025-01")
+	writeEd25519Key(t, dir, "2025-07")
+
+	km, err := service.LoadKeyManager(dir, "")
+	require.NoError(t, err)
+
+	// Act
+	signed, err := km.Sign(jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))})
+	require.NoError(t, err)
+
+	token, err := jwt.Parse(signed, km.Keyfunc, jwt.WithValidMethods(km.ValidMethods()))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, "2025-07", km.ActiveKID())
+	assert.Equal(t, "2025-07", token.Header["kid"])
+	assert.Equal(t, "EdDSA", token.Header["alg"])
+}
+
+func TestKeyManager_RetiredPublicKey_StillVerifies(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2025-07")
+	retired := writeRSAPublicKey(t, dir, "2025-01")
+
+	km, err := service.LoadKeyManager(dir, "")
+	require.NoError(t, err)
+
+	old := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))})
+	old.Header["kid"] = "2025-01"
+	signed, err := old.SignedString(retired)
+	require.NoError(t, err)
+
+	// Act
+	token, err := jwt.Parse(signed, km.Keyfunc, jwt.WithValidMethods(km.ValidMethods()))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, "2025-07", km.ActiveKID())
+}
+
+func TestKeyManager_RejectsUnknownKidAndHS256(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2025-01")
+
+	km, err := service.LoadKeyManager(dir, "")
+	require.NoError(t, err)
+
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{})
+	hmac.Header["kid"] = "2025-01"
+	hmacSigned, _ := hmac.SignedString([]byte("your-secret-key-change-in-production"))
+
+	_, foreign, _ := ed25519.GenerateKey(rand.Reader)
+	unknown := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{})
+	unknown.Header["kid"] = "someone-else"
+	unknownSigned, _ := unknown.SignedString(foreign)
+
+	// Act
+	_, hmacErr := jwt.Parse(hmacSigned, km.Keyfunc, jwt.WithValidMethods(km.ValidMethods()))
+	_, unknownErr := jwt.Parse(unknownSigned, km.Keyfunc, jwt.WithValidMethods(km.ValidMethods()))
+
+	// Assert
+	assert.Error(t, hmacErr)
+	assert.Error(t, unknownErr)
+}
+
+func TestKeyManager_JWKS_ContainsAllPublicKeys(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2025-07")
+	writeRSAPublicKey(t, dir, "2025-01")
+
+	km, err := service.LoadKeyManager(dir, "")
+	require.NoError(t, err)
+
+	// Act
+	jwks := km.JWKS()
+
+	// Assert
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2025-01", jwks.Keys[0].KID)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.Equal(t, "2025-07", jwks.Keys[1].KID)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.NotEmpty(t, jwks.Keys[1].X)
+}
+
+func TestKeyManager_ActiveKidWithoutPrivateKey_Fails(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2025-07")
+	writeRSAPublicKey(t, dir, "2025-01")
+
+	// Act
+	km, err := service.LoadKeyManager(dir, "2025-01")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, km)
+}
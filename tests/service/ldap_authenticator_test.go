@@ -0,0 +1,259 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"net"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	ldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockLDAPBaseDN       = "ou=lecturers,dc=example,dc=ac,dc=id"
+	mockLDAPBindDN       = "cn=reader,dc=example,dc=ac,dc=id"
+	mockLDAPBindPassword = "reader-secret"
+)
+
+// mockLDAPEntry - Satu user di direktori mock
+type mockLDAPEntry struct {
+	dn       string
+	uid      string
+	password string
+	attrs    map[string]string
+}
+
+// mockLDAPServer - Server LDAP minimal untuk test: simple bind, search berdasarkan (uid=...), dan unbind
+type mockLDAPServer struct {
+	listener net.Listener
+	entries  []mockLDAPEntry
+}
+
+func newMockLDAPServer(t *testing.T, entries ...mockLDAPEntry) *mockLDAPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &mockLDAPServer{listener: listener, entries: entries}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *mockLDAPServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *mockLDAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			s.reply(conn, messageID, ldap.ApplicationBindResponse, s.bind(dn, password))
+		case ldap.ApplicationSearchRequest:
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, entry := range s.entries {
+				if filter == "(uid="+entry.uid+")" {
+					s.writeEntry(conn, messageID, entry)
+				}
+			}
+			s.reply(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *mockLDAPServer) bind(dn, password string) uint16 {
+	if dn == mockLDAPBindDN && password == mockLDAPBindPassword {
+		return ldap.LDAPResultSuccess
+	}
+	for _, entry := range s.entries {
+		if entry.dn == dn && entry.password == password {
+			return ldap.LDAPResultSuccess
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials
+}
+
+func (s *mockLDAPServer) reply(conn net.Conn, messageID int64, tag ber.Tag, resultCode uint16) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	s.write(conn, messageID, op)
+}
+
+func (s *mockLDAPServer) writeEntry(conn net.Conn, messageID int64, entry mockLDAPEntry) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, ""))
+
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for name, value := range entry.attrs {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		attribute.AppendChild(values)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+
+	s.write(conn, messageID, op)
+}
+
+func (s *mockLDAPServer) write(conn net.Conn, messageID int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, ""))
+	packet.AppendChild(op)
+	conn.Write(packet.Bytes())
+}
+
+func newTestLDAPAuthenticator(url string) *service.LDAPAuthenticator {
+	return service.NewLDAPAuthenticator(service.LDAPConfig{
+		URL:          url,
+		BindDN:       mockLDAPBindDN,
+		BindPassword: mockLDAPBindPassword,
+		BaseDN:       mockLDAPBaseDN,
+	})
+}
+
+var mockLecturer = mockLDAPEntry{
+	dn:       "uid=budi,ou=lecturers,dc=example,dc=ac,dc=id",
+	uid:      "budi",
+	password: "directory-password",
+	attrs: map[string]string{
+		"cn":             "Budi Santoso",
+		"mail":           "budi@example.ac.id",
+		"employeeNumber": "198001012005011001",
+	},
+}
+
+func TestLDAPAuthenticator_Success_MapsAttributes(t *testing.T) {
+	// Arrange
+	server := newMockLDAPServer(t, mockLecturer)
+	authenticator := newTestLDAPAuthenticator(server.URL())
+
+	// Act
+	profile, err := authenticator.Authenticate(&model.Users{Username: "budi"}, "directory-password")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Budi Santoso", profile.FullName)
+	assert.Equal(t, "budi@example.ac.id", profile.Email)
+	assert.Equal(t, "198001012005011001", profile.LecturerID)
+}
+
+func TestLDAPAuthenticator_WrongPassword(t *testing.T) {
+	// Arrange
+	server := newMockLDAPServer(t, mockLecturer)
+	authenticator := newTestLDAPAuthenticator(server.URL())
+
+	// Act
+	profile, err := authenticator.Authenticate(&model.Users{Username: "budi"}, "wrong-password")
+
+	// Assert
+	assert.Nil(t, profile)
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestLDAPAuthenticator_UnknownUser(t *testing.T) {
+	// Arrange
+	server := newMockLDAPServer(t, mockLecturer)
+	authenticator := newTestLDAPAuthenticator(server.URL())
+
+	// Act
+	profile, err := authenticator.Authenticate(&model.Users{Username: "siti"}, "directory-password")
+
+	// Assert
+	assert.Nil(t, profile)
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestLDAPAuthenticator_EscapesUsernameInFilter(t *testing.T) {
+	// Arrange
+	server := newMockLDAPServer(t, mockLecturer)
+	authenticator := newTestLDAPAuthenticator(server.URL())
+
+	// Act: username berisi karakter filter tidak boleh mengubah filter pencarian
+	profile, err := authenticator.Authenticate(&model.Users{Username: "*"}, "directory-password")
+
+	// Assert
+	assert.Nil(t, profile)
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestLDAPAuthenticator_EmptyPasswordRejected(t *testing.T) {
+	// Arrange
+	server := newMockLDAPServer(t, mockLecturer)
+	authenticator := newTestLDAPAuthenticator(server.URL())
+
+	// Act
+	profile, err := authenticator.Authenticate(&model.Users{Username: "budi"}, "")
+
+	// Assert
+	assert.Nil(t, profile)
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestLDAPAuthenticator_ServerUnavailable(t *testing.T) {
+	// Arrange: alamat yang sudah tidak listen
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "ldap://" + listener.Addr().String()
+	listener.Close()
+	authenticator := newTestLDAPAuthenticator(url)
+
+	// Act
+	profile, err := authenticator.Authenticate(&model.Users{Username: "budi"}, "directory-password")
+
+	// Assert
+	assert.Nil(t, profile)
+	assert.ErrorIs(t, err, service.ErrAuthBackendUnavailable)
+	assert.NotErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestPasswordAuthenticator(t *testing.T) {
+	// Arrange
+	hash, err := service.HashPassword("local-password")
+	require.NoError(t, err)
+	user := &model.Users{Username: "admin", PasswordHash: hash}
+
+	// Act
+	profile, okErr := service.PasswordAuthenticator{}.Authenticate(user, "local-password")
+	_, badErr := service.PasswordAuthenticator{}.Authenticate(user, "wrong-password")
+
+	// Assert
+	assert.NoError(t, okErr)
+	assert.Nil(t, profile)
+	assert.ErrorIs(t, badErr, service.ErrInvalidCredentials)
+}
+
+func TestIsValidAuthSource(t *testing.T) {
+	assert.True(t, service.IsValidAuthSource(service.AuthSourceLocal))
+	assert.True(t, service.IsValidAuthSource(service.AuthSourceLDAP))
+	assert.False(t, service.IsValidAuthSource("kerberos"))
+	assert.False(t, service.IsValidAuthSource(""))
+}
@@ -0,0 +1,53 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutPolicy_Delay_Progressive(t *testing.T) {
+	// Arrange
+	policy := service.DefaultLockoutPolicy()
+
+	// Act & Assert
+	assert.Equal(t, time.Duration(0), policy.Delay(1))
+	assert.Equal(t, time.Duration(0), policy.Delay(2))
+	assert.Equal(t, 1*time.Second, policy.Delay(3))
+	assert.Equal(t, 2*time.Second, policy.Delay(4))
+	assert.Equal(t, 4*time.Second, policy.Delay(5))
+	assert.Equal(t, 32*time.Second, policy.Delay(8))
+	assert.Equal(t, policy.MaxDelay, policy.Delay(9))
+	assert.Equal(t, policy.MaxDelay, policy.Delay(1000))
+}
+
+func TestLoginThrottledError_RetryAfterRoundsUp(t *testing.T) {
+	// Arrange
+	err := &service.LoginThrottledError{RetryAfter: 1500 * time.Millisecond, Locked: true}
+
+	// Act
+	seconds := err.RetryAfterSeconds()
+
+	// Assert
+	assert.Equal(t, 2, seconds)
+	assert.Contains(t, err.Error(), "locked")
+}
+
+func TestAccountKey_UsernameAndEmailShareCounter(t *testing.T) {
+	// Arrange
+	user := &model.Users{ID: uuid.New(), Username: "student1", Email: "student1@uas.test"}
+
+	// Act
+	byUser := service.AccountKey(user, "student1")
+	byEmail := service.AccountKey(user, "STUDENT1@uas.test")
+	unknown := service.AccountKey(nil, "  Ghost@uas.test ")
+
+	// Assert
+	assert.Equal(t, byUser, byEmail)
+	assert.Equal(t, user.ID.String(), byUser)
+	assert.Equal(t, "identifier:ghost@uas.test", unknown)
+}
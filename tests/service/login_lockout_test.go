@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/service"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loginFixture - AuthService dengan backend lokal dan lockout di atas sqlmock
+func loginFixture(t *testing.T) (*service.AuthService, sqlmock.Sqlmock) {
+	db, mock := newMockDB(t)
+	lockout := service.NewLockoutService(repository.NewLoginAttemptRepository(db), nil, nil, service.DefaultLockoutPolicy())
+	return service.NewAuthService(nil, time.Minute, time.Hour, repository.NewAuthRepository(db), nil, lockout, nil, nil, nil), mock
+}
+
+func TestAuthService_Login_UnknownIdentifierLikeWrongPassword(t *testing.T) {
+	// Arrange: satu akun lokal dengan password lain
+	hash, err := service.HashPassword("Password-Benar-123")
+	require.NoError(t, err)
+	user := &model.Users{ID: uuid.New(), Username: "mahasiswa1", Email: "mhs1@example.com", PasswordHash: hash, RoleID: uuid.New(), IsActive: true}
+
+	login := func(identifier string, found bool) (time.Duration, error) {
+		authService, mock := loginFixture(t)
+		userQuery := mock.ExpectQuery("FROM users\\s+WHERE \\(username = \\$1 OR email = \\$1\\)").WithArgs(identifier)
+		key := "identifier:" + identifier
+		if found {
+			key = user.ID.String()
+			userQuery.WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "password_hash", "full_name", "role_id", "is_active", "must_change_password", "created_at", "updated_at"}).
+				AddRow(user.ID, user.Username, user.Email, user.PasswordHash, "Mahasiswa", user.RoleID, true, false, time.Now(), time.Now()))
+		} else {
+			userQuery.WillReturnError(sql.ErrNoRows)
+		}
+		mock.ExpectQuery("FROM login_attempts").WithArgs("account", key).WillReturnError(sql.ErrNoRows)
+		if found {
+			mock.ExpectQuery("auth_source").WithArgs(user.ID).WillReturnRows(sqlmock.NewRows([]string{"source"}).AddRow(service.AuthSourceLocal))
+		}
+		mock.ExpectQuery("INSERT INTO login_attempts").WithArgs("account", key, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"failed_count"}).AddRow(1))
+		mock.ExpectExec("UPDATE login_attempts").WithArgs(sqlmock.AnyArg(), nil, "account", key).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		start := time.Now()
+		_, err := authService.Login(identifier, "password-salah", model.ClientInfo{})
+		elapsed := time.Since(start)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+		return elapsed, err
+	}
+
+	// Act
+	knownElapsed, knownErr := login(user.Username, true)
+	unknownElapsed, unknownErr := login("tidak-ada", false)
+
+	// Assert: error dan counter gagal sama, identifier tidak dikenal tetap membayar biaya bcrypt
+	assert.ErrorIs(t, knownErr, service.ErrInvalidCredentials)
+	assert.ErrorIs(t, unknownErr, service.ErrInvalidCredentials)
+	assert.Greater(t, unknownElapsed, knownElapsed/4)
+}
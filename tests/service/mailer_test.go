@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"bufio"
+	"bytes"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// catchAllSMTP - Server SMTP minimal yang menerima semua email (seperti MailHog)
+type catchAllSMTP struct {
+	listener net.Listener
+	received chan string
+	rcpt     chan string
+}
+
+func startCatchAllSMTP(t *testing.T) *catchAllSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	s := &catchAllSMTP{listener: l, received: make(chan string, 1), rcpt: make(chan string, 1)}
+	go s.serve()
+	return s
+}
+
+func (s *catchAllSMTP) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	write("220 localhost ESMTP catch-all")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpt <- strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+			write("250 OK")
+		case cmd == "DATA":
+			write("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.received <- data.String()
+			write("250 OK")
+		case cmd == "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
+
+func TestSMTPMailer_Send_CatchAllServer(t *testing.T) {
+	// Arrange
+	server := startCatchAllSMTP(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	mailer := service.NewSMTPMailer(host, port, "", "", "no-reply@uas.test")
+
+	msg := &model.MailMessage{
+		To:      "student@uas.test",
+		Subject: "Reset Password",
+		Body:    "Halo,\nlink: http://localhost/reset?token=abc\n.\nselesai",
+	}
+
+	// Act
+	err := mailer.Send(msg)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "student@uas.test", <-server.rcpt)
+
+	data := <-server.received
+	assert.Contains(t, data, "From: no-reply@uas.test\r\n")
+	assert.Contains(t, data, "To: student@uas.test\r\n")
+	assert.Contains(t, data, "Subject: Reset Password\r\n")
+	assert.Contains(t, data, "Content-Type: text/plain; charset=UTF-8\r\n")
+	assert.Contains(t, data, "link: http://localhost/reset?token=abc\r\n")
+	assert.Contains(t, data, "\r\n..\r\nselesai")
+}
+
+func TestSMTPMailer_Send_RejectsHeaderInjection(t *testing.T) {
+	// Arrange
+	mailer := service.NewSMTPMailer("127.0.0.1", "1", "", "", "no-reply@uas.test")
+	msg := &model.MailMessage{
+		To:      "student@uas.test",
+		Subject: "Reset\r\nBcc: attacker@evil.test",
+		Body:    "body",
+	}
+
+	// Act
+	err := mailer.Send(msg)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line break")
+}
+
+func TestLogMailer_Send_RedactsBodyByDefault(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mailer   service.LogMailer
+		hasToken bool
+	}{
+		{name: "Default", mailer: service.LogMailer{}, hasToken: false},
+		{name: "MAIL_LOG_BODY", mailer: service.LogMailer{LogBody: true}, hasToken: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+			msg := &model.MailMessage{To: "student@uas.test", Subject: "Reset Password", Body: "link: http://localhost/reset?token=secret-token"}
+
+			// Act
+			err := tc.mailer.Send(msg)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Contains(t, buf.String(), "student@uas.test")
+			assert.Equal(t, tc.hasToken, strings.Contains(buf.String(), "secret-token"))
+		})
+	}
+}
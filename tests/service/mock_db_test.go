@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockDB - Koneksi PostgreSQL palsu untuk service yang langsung memakai repository.
+// Query dicocokkan dengan regex, jadi cukup potongan unik dari query-nya.
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+// userRow - Baris users seperti yang di-scan UserRepository.GetUserByID
+func userRow(user *model.Users) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "username", "email", "password_hash", "full_name", "role_id", "is_active", "must_change_password", "created_at", "updated_at"}).
+		AddRow(user.ID, user.Username, user.Email, user.PasswordHash, user.FullName, user.RoleID, user.IsActive, user.MustChangePassword, time.Now(), time.Now())
+}
+
+// roleRows - Baris roles (id, name, description, mfa_required, created_at)
+func roleRows(roles ...model.Roles) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "mfa_required", "created_at"})
+	for _, role := range roles {
+		rows.AddRow(role.ID, role.Name, role.Description, role.MFARequired, time.Now())
+	}
+	return rows
+}
+
+// permissionRows - Baris permissions (id, name, resource, action, description)
+func permissionRows(permissions ...model.Permission) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "name", "resource", "action", "description"})
+	for _, perm := range permissions {
+		rows.AddRow(perm.ID, perm.Name, perm.Resource, perm.Action, perm.Description)
+	}
+	return rows
+}
+
+// countRow - Satu baris hasil COUNT
+func countRow(count int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"count"}).AddRow(count)
+}
+
+// idRows - Baris berisi satu kolom UUID
+func idRows(ids ...uuid.UUID) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		rows.AddRow(id)
+	}
+	return rows
+}
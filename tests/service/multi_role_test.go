@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHasRole_MatchesSecondaryRole(t *testing.T) {
+	// Arrange: dosen yang juga koordinator prodi
+	userID, lecturerRole, coordinatorRole := uuid.New(), uuid.New(), uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		RoleID:    lecturerRole,
+		RoleIDs:   []uuid.UUID{coordinatorRole, lecturerRole},
+		RoleNames: []string{"coordinator", "lecturer"},
+		IsActive:  true,
+	})
+
+	// Act
+	isLecturer, err := rbac.UserHasRole(userID, "lecturer")
+	require.NoError(t, err)
+	isCoordinator, _ := rbac.UserHasRole(userID, "coordinator")
+	isAnyAdmin, _ := rbac.UserHasRole(userID, "admin", "coordinator")
+	isAdmin, _ := rbac.UserHasRole(userID, "admin")
+
+	// Assert
+	assert.True(t, isLecturer)
+	assert.True(t, isCoordinator)
+	assert.True(t, isAnyAdmin)
+	assert.False(t, isAdmin)
+}
+
+func TestPermissionCache_DeleteRole_SecondaryRole(t *testing.T) {
+	// Arrange: mahasiswa yang juga asisten dosen
+	cache := service.NewPermissionCache(time.Minute)
+	studentRole, assistantRole := uuid.New(), uuid.New()
+	assistant, student := uuid.New(), uuid.New()
+	cache.Set(assistant, &model.PermissionState{RoleID: studentRole, RoleIDs: []uuid.UUID{studentRole, assistantRole}})
+	cache.Set(student, &model.PermissionState{RoleID: studentRole, RoleIDs: []uuid.UUID{studentRole}})
+
+	// Act: permission role asisten berubah
+	cache.DeleteRole(assistantRole)
+
+	// Assert
+	_, assistantCached := cache.Get(assistant)
+	_, studentCached := cache.Get(student)
+	assert.False(t, assistantCached)
+	assert.True(t, studentCached)
+}
+
+func TestRefreshClaims_SecondaryRoleAddedMergesPermissions(t *testing.T) {
+	// Arrange: role asisten ditambahkan setelah token diterbitkan (versi user naik)
+	userID, studentRole, assistantRole := uuid.New(), uuid.New(), uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		RoleID: studentRole, RoleIDs: []uuid.UUID{studentRole, assistantRole},
+		RoleNames: []string{"assistant", "student"}, IsActive: true, UserVersion: 2, RoleVersion: 5,
+		Permissions: []string{"achievement.create", "achievement.read", "student.read"},
+	})
+	claims := &model.CustomClaims{
+		UserID: userID, RoleID: studentRole, UserPermVersion: 1, RolePermVersion: 3,
+		Permissions: []string{"achievement.create", "achievement.read"},
+	}
+
+	// Act
+	err := rbac.RefreshClaims(claims)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, studentRole, claims.RoleID)
+	assert.Equal(t, []string{"achievement.create", "achievement.read", "student.read"}, claims.Permissions)
+}
@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockClientID    = "uas-backend"
+	mockRedirectURL = "http://localhost:8080/api/v1/auth/oidc/callback"
+	mockCode        = "mock-authorization-code"
+)
+
+// mockOIDCProvider - Identity provider lokal untuk test: discovery, JWKS, dan token endpoint
+type mockOIDCProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string // code_challenge dari URL authorize
+	nonce     string // nonce yang dimasukkan ke ID token
+	audience  string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{key: key, audience: mockClientID}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(model.JWKS{Keys: []model.JWK{{
+			Kty: "RSA",
+			KID: "mock-1",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Form.Get("code") != mockCode || service.PKCEChallenge(r.Form.Get("code_verifier")) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            p.server.URL,
+			"sub":            "campus-12345",
+			"aud":            p.audience,
+			"exp":            time.Now().Add(5 * time.Minute).Unix(),
+			"iat":            time.Now().Unix(),
+			"nonce":          p.nonce,
+			"email":          "jane@student.example.ac.id",
+			"email_verified": true,
+			"name":           "Jane Smith",
+		})
+		token.Header["kid"] = "mock-1"
+		signed, _ := token.SignedString(key)
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize - Simulasi browser membuka URL authorize: provider menyimpan code_challenge dan nonce
+func (p *mockOIDCProvider) authorize(t *testing.T, client *service.OIDCClient, state, nonce, verifier string) url.Values {
+	authURL, err := client.AuthCodeURL(state, nonce, verifier)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	query := parsed.Query()
+	p.challenge = query.Get("code_challenge")
+	p.nonce = query.Get("nonce")
+	return query
+}
+
+func newTestOIDCClient(p *mockOIDCProvider) *service.OIDCClient {
+	return service.NewOIDCClient(service.OIDCConfig{
+		IssuerURL:   p.server.URL,
+		ClientID:    mockClientID,
+		RedirectURL: mockRedirectURL,
+	}, p.server.Client())
+}
+
+func TestPKCEChallenge_RFC7636Vector(t *testing.T) {
+	// Act: contoh dari RFC 7636 Appendix B
+	challenge := service.PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+
+	// Assert
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", challenge)
+}
+
+func TestOIDCClient_AuthCodeURL_UsesPKCE(t *testing.T) {
+	// Arrange
+	provider := newMockOIDCProvider(t)
+	client := newTestOIDCClient(provider)
+
+	// Act
+	query := provider.authorize(t, client, "state-1", "nonce-1", "verifier-0123456789-0123456789-0123456789")
+
+	// Assert
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, mockClientID, query.Get("client_id"))
+	assert.Equal(t, mockRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, service.PKCEChallenge("verifier-0123456789-0123456789-0123456789"), query.Get("code_challenge"))
+}
+
+func TestOIDCClient_Exchange_ReturnsVerifiedIdentity(t *testing.T) {
+	// Arrange
+	provider := newMockOIDCProvider(t)
+	client := newTestOIDCClient(provider)
+	verifier := "verifier-0123456789-0123456789-0123456789"
+	provider.authorize(t, client, "state-1", "nonce-1", verifier)
+
+	// Act
+	identity, err := client.Exchange(mockCode, verifier, "nonce-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, provider.server.URL, identity.Issuer)
+	assert.Equal(t, "campus-12345", identity.Subject)
+	assert.Equal(t, "jane@student.example.ac.id", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Jane Smith", identity.Name)
+}
+
+func TestOIDCClient_Exchange_RejectsWrongCodeVerifier(t *testing.T) {
+	// Arrange
+	provider := newMockOIDCProvider(t)
+	client := newTestOIDCClient(provider)
+	provider.authorize(t, client, "state-1", "nonce-1", "verifier-0123456789-0123456789-0123456789")
+
+	// Act
+	identity, err := client.Exchange(mockCode, "another-verifier-0123456789-0123456789", "nonce-1")
+
+	// Assert
+	assert.Nil(t, identity)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestOIDCClient_Exchange_RejectsNonceMismatch(t *testing.T) {
+	// Arrange
+	provider := newMockOIDCProvider(t)
+	client := newTestOIDCClient(provider)
+	verifier := "verifier-0123456789-0123456789-0123456789"
+	provider.authorize(t, client, "state-1", "nonce-from-another-login", verifier)
+
+	// Act
+	identity, err := client.Exchange(mockCode, verifier, "nonce-1")
+
+	// Assert
+	assert.Nil(t, identity)
+	assert.ErrorIs(t, err, service.ErrOIDCTokenInvalid)
+}
+
+func TestOIDCClient_Exchange_RejectsWrongAudience(t *testing.T) {
+	// Arrange
+	provider := newMockOIDCProvider(t)
+	provider.audience = "another-client"
+	client := newTestOIDCClient(provider)
+	verifier := "verifier-0123456789-0123456789-0123456789"
+	provider.authorize(t, client, "state-1", "nonce-1", verifier)
+
+	// Act
+	identity, err := client.Exchange(mockCode, verifier, "nonce-1")
+
+	// Assert
+	assert.Nil(t, identity)
+	assert.ErrorIs(t, err, service.ErrOIDCTokenInvalid)
+}
+
+func TestOIDCService_Disabled(t *testing.T) {
+	// Arrange: OIDC_ISSUER_URL kosong, service tidak dibuat
+	var oidcService *service.OIDCService
+
+	// Act
+	_, err := oidcService.BeginLogin()
+
+	// Assert
+	assert.False(t, oidcService.Enabled())
+	assert.ErrorIs(t, err, service.ErrOIDCDisabled)
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Validate_AcceptsStrongPassword(t *testing.T) {
+	// Arrange
+	policy := service.DefaultPasswordPolicy()
+
+	// Act
+	err := policy.Validate("Kopi-Susu-Pagi7", "student1", "student@example.com")
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestPasswordPolicy_Validate_ReportsAllViolations(t *testing.T) {
+	// Arrange
+	policy := service.DefaultPasswordPolicy()
+
+	// Act
+	err := policy.Validate("kampus", "student1", "student@example.com")
+
+	// Assert
+	var policyErr *service.PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr))
+	assert.Len(t, policyErr.Violations, 2)
+	assert.Contains(t, policyErr.Violations[0], "at least 10 characters")
+	assert.Contains(t, policyErr.Violations[1], "at least 3 of")
+}
+
+func TestPasswordPolicy_Validate_RejectsUsernameAndEmail(t *testing.T) {
+	// Arrange
+	policy := service.DefaultPasswordPolicy()
+
+	// Act
+	usernameErr := policy.Validate("Lecturer1-Rahasia", "LECTURER1", "dosen@example.com")
+	emailErr := policy.Validate("Xx-Dosen.Kampus-9", "lecturer1", "dosen.kampus@example.com")
+	shortNameErr := policy.Validate("Al-Gorithm-2024", "al", "al@example.com")
+
+	// Assert
+	assert.ErrorContains(t, usernameErr, "must not contain the username")
+	assert.ErrorContains(t, emailErr, "must not contain the email address")
+	assert.NoError(t, shortNameErr)
+}
+
+func TestBreachedPasswordChecker_MatchesPrefixFile(t *testing.T) {
+	// Arrange: file prefix dengan format "SUFFIX:COUNT" seperti range API HIBP
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("Correct-Horse-9"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	content := "0000000000000000000000000000000000A:3\n" + hash[5:] + ":42\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o644))
+
+	checker := service.NewBreachedPasswordChecker(dir)
+
+	// Act
+	breached, err := checker.IsBreached("Correct-Horse-9")
+	require.NoError(t, err)
+	notBreached, err := checker.IsBreached("Correct-Horse-10")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, breached)
+	assert.False(t, notBreached)
+}
+
+func TestBreachedPasswordChecker_BundledListContainsSeedPassword(t *testing.T) {
+	// Arrange
+	checker := service.NewBreachedPasswordChecker(filepath.Join("..", "..", "data", "breached-passwords"))
+
+	// Act
+	breached, err := checker.IsBreached("password123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, breached)
+}
+
+func TestBreachedPasswordChecker_DisabledWithoutDir(t *testing.T) {
+	// Arrange
+	checker := service.NewBreachedPasswordChecker("")
+
+	// Act
+	breached, err := checker.IsBreached("password123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestPasswordService_CheckNewPassword_RejectsBreachedPassword(t *testing.T) {
+	// Arrange: user baru (tanpa ID) tidak butuh riwayat password dari database
+	passwords := service.NewPasswordService(
+		service.DefaultPasswordPolicy(),
+		service.NewBreachedPasswordChecker(filepath.Join("..", "..", "data", "breached-passwords")),
+		nil,
+	)
+	user := &model.Users{Username: "student1", Email: "student@example.com"}
+
+	// Act
+	err := passwords.CheckNewPassword(user, "P@ssw0rd123")
+
+	// Assert
+	assert.ErrorContains(t, err, "known data breach")
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCachedRBACService - RBACService tanpa database, state RBAC diisi langsung ke cache
+func newCachedRBACService(userID uuid.UUID, state *model.PermissionState) *service.RBACService {
+	rbac := &service.RBACService{Cache: service.NewPermissionCache(time.Minute)}
+	rbac.Cache.Set(userID, state)
+	return rbac
+}
+
+func TestRefreshClaims_SameVersionKeepsTokenPermissions(t *testing.T) {
+	// Arrange
+	userID, roleID := uuid.New(), uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		RoleID: roleID, IsActive: true, UserVersion: 3, RoleVersion: 7,
+		Permissions: []string{"achievement.read", "achievement.verify"},
+	})
+	claims := &model.CustomClaims{
+		UserID: userID, RoleID: roleID, UserPermVersion: 3, RolePermVersion: 7,
+		Permissions: []string{"achievement.read", "achievement.verify"},
+	}
+
+	// Act
+	err := rbac.RefreshClaims(claims)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"achievement.read", "achievement.verify"}, claims.Permissions)
+}
+
+func TestRefreshClaims_RoleVersionChangedReResolvesPermissions(t *testing.T) {
+	// Arrange: achievement.verify dicabut dari role setelah token diterbitkan
+	userID, roleID := uuid.New(), uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		RoleID: roleID, IsActive: true, UserVersion: 3, RoleVersion: 8,
+		Permissions: []string{"achievement.read"},
+	})
+	claims := &model.CustomClaims{
+		UserID: userID, RoleID: roleID, UserPermVersion: 3, RolePermVersion: 7,
+		Permissions: []string{"achievement.read", "achievement.verify"},
+	}
+
+	// Act
+	err := rbac.RefreshClaims(claims)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"achievement.read"}, claims.Permissions)
+	assert.Equal(t, 8, claims.RolePermVersion)
+}
+
+func TestRefreshClaims_RoleChangedUsesNewRole(t *testing.T) {
+	// Arrange: admin memindahkan user ke role lain
+	userID, oldRoleID, newRoleID := uuid.New(), uuid.New(), uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		RoleID: newRoleID, IsActive: true, UserVersion: 4, RoleVersion: 1,
+		Permissions: []string{"achievement.create"},
+	})
+	claims := &model.CustomClaims{
+		UserID: userID, RoleID: oldRoleID, UserPermVersion: 3, RolePermVersion: 7,
+		Permissions: []string{"user.manage"},
+	}
+
+	// Act
+	err := rbac.RefreshClaims(claims)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, newRoleID, claims.RoleID)
+	assert.Equal(t, []string{"achievement.create"}, claims.Permissions)
+}
+
+func TestRefreshClaims_InactiveUserRejected(t *testing.T) {
+	// Arrange
+	userID, roleID := uuid.New(), uuid.New()
+	rbac := newCachedRBACService(userID, &model.PermissionState{
+		RoleID: roleID, IsActive: false, UserVersion: 4, RoleVersion: 7,
+	})
+	claims := &model.CustomClaims{UserID: userID, RoleID: roleID, UserPermVersion: 3, RolePermVersion: 7}
+
+	// Act
+	err := rbac.RefreshClaims(claims)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrUserInactive)
+}
+
+func TestPermissionCache_DeleteRole(t *testing.T) {
+	// Arrange
+	cache := service.NewPermissionCache(time.Minute)
+	lecturerRole, studentRole := uuid.New(), uuid.New()
+	lecturer, student := uuid.New(), uuid.New()
+	cache.Set(lecturer, &model.PermissionState{RoleID: lecturerRole})
+	cache.Set(student, &model.PermissionState{RoleID: studentRole})
+
+	// Act
+	cache.DeleteRole(lecturerRole)
+
+	// Assert
+	_, lecturerCached := cache.Get(lecturer)
+	_, studentCached := cache.Get(student)
+	assert.False(t, lecturerCached)
+	assert.True(t, studentCached)
+}
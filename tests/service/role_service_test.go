@@ -0,0 +1,328 @@
+package service_test
+
+import (
+	model "UAS_BACKEND/domain/Model"
+	"UAS_BACKEND/domain/repository"
+	"UAS_BACKEND/domain/service"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleService_CreateRole_ValidatesName(t *testing.T) {
+	// Arrange: validasi berjalan sebelum repository dipanggil
+	roleService := service.NewRoleService(nil, nil)
+
+	testCases := []struct {
+		name     string
+		roleName string
+	}{
+		{name: "Empty", roleName: ""},
+		{name: "Single character", roleName: "a"},
+		{name: "Uppercase", roleName: "Reviewer"},
+		{name: "Whitespace inside", roleName: "program admin"},
+		{name: "Starts with digit", roleName: "1reviewer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			role, err := roleService.CreateRole(&model.RoleRequest{Name: tc.roleName})
+
+			// Assert
+			assert.Nil(t, role)
+			assert.ErrorContains(t, err, "role name must be")
+		})
+	}
+}
+
+func TestRoleService_CreatePermission_ValidatesSegments(t *testing.T) {
+	// Arrange
+	roleService := service.NewRoleService(nil, nil)
+
+	testCases := []struct {
+		name        string
+		req         model.PermissionRequest
+		expectedErr string
+	}{
+		{name: "Missing resource", req: model.PermissionRequest{Action: "read"}, expectedErr: "resource must be"},
+		{name: "Missing action", req: model.PermissionRequest{Resource: "report"}, expectedErr: "action must be"},
+		{name: "Dot in resource", req: model.PermissionRequest{Resource: "report.export", Action: "read"}, expectedErr: "resource must be"},
+		{name: "Uppercase action", req: model.PermissionRequest{Resource: "report", Action: "Export"}, expectedErr: "action must be"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			permission, err := roleService.CreatePermission(&tc.req)
+
+			// Assert
+			assert.Nil(t, permission)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestUserService_RefusesToRemoveLastAdmin(t *testing.T) {
+	adminRole := model.Roles{ID: uuid.New(), Name: "admin"}
+	lecturerRole := model.Roles{ID: uuid.New(), Name: "lecturer"}
+	inactive := false
+
+	testCases := []struct {
+		name        string
+		primaryRole model.Roles
+		assignsRole bool
+		act         func(s *service.UserService, userID uuid.UUID) error
+	}{
+		{
+			name:        "Deactivated",
+			primaryRole: adminRole,
+			act: func(s *service.UserService, userID uuid.UUID) error {
+				_, err := s.UpdateUser(userID, &service.UpdateUserRequest{IsActive: &inactive})
+				return err
+			},
+		},
+		{
+			name:        "Deleted",
+			primaryRole: adminRole,
+			act: func(s *service.UserService, userID uuid.UUID) error {
+				return s.DeleteUser(userID)
+			},
+		},
+		{
+			name:        "Demoted",
+			primaryRole: adminRole,
+			assignsRole: true,
+			act: func(s *service.UserService, userID uuid.UUID) error {
+				_, err := s.AssignRole(userID, lecturerRole.ID)
+				return err
+			},
+		},
+		{
+			name:        "Secondary admin role removed",
+			primaryRole: lecturerRole,
+			act: func(s *service.UserService, userID uuid.UUID) error {
+				_, err := s.RemoveRole(userID, adminRole.ID)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange: admin global aktif satu-satunya, UPDATE/DELETE tidak boleh sampai dijalankan
+			user := &model.Users{ID: uuid.New(), Username: "admin", Email: "admin@uas.test", IsActive: true, RoleID: tc.primaryRole.ID}
+			db, mock := newMockDB(t)
+			rbac := newCachedRBACService(user.ID, &model.PermissionState{IsActive: true, RoleNames: []string{"admin", "lecturer"}})
+			userService := service.NewUserService(repository.NewUserRepository(db), nil, nil, rbac)
+
+			mock.ExpectQuery("FROM users\\s+WHERE id = \\$1").WithArgs(user.ID).WillReturnRows(userRow(user))
+			if tc.assignsRole {
+				mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(lecturerRole.ID).WillReturnRows(roleRows(lecturerRole))
+			}
+			mock.ExpectQuery("INNER JOIN user_roles").WithArgs(user.ID).WillReturnRows(roleRows(adminRole, lecturerRole))
+			mock.ExpectQuery("SELECT COUNT\\(DISTINCT u.id\\)").WithArgs(user.ID).WillReturnRows(countRow(0))
+
+			// Act
+			err := tc.act(userService, user.ID)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrLastAdmin)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserService_DeleteUser_AllowsAdminWhenAnotherAdminRemains(t *testing.T) {
+	// Arrange
+	adminRole := model.Roles{ID: uuid.New(), Name: "admin"}
+	user := &model.Users{ID: uuid.New(), Username: "admin", Email: "admin@uas.test", IsActive: true, RoleID: adminRole.ID}
+	db, mock := newMockDB(t)
+	rbac := newCachedRBACService(user.ID, &model.PermissionState{IsActive: true, RoleNames: []string{"admin"}})
+	userService := service.NewUserService(repository.NewUserRepository(db), nil, nil, rbac)
+
+	mock.ExpectQuery("FROM users\\s+WHERE id = \\$1").WithArgs(user.ID).WillReturnRows(userRow(user))
+	mock.ExpectQuery("INNER JOIN user_roles").WithArgs(user.ID).WillReturnRows(roleRows(adminRole))
+	mock.ExpectQuery("SELECT COUNT\\(DISTINCT u.id\\)").WithArgs(user.ID).WillReturnRows(countRow(1))
+	mock.ExpectExec("DELETE FROM users").WithArgs(user.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	err := userService.DeleteUser(user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	_, cached := rbac.Cache.Get(user.ID)
+	assert.False(t, cached, "deleted user must be evicted from the permission cache")
+}
+
+func TestUserService_RemoveRole_ScopedAdminIsNotCounted(t *testing.T) {
+	// Arrange: admin ter-scope bukan admin global, jumlah admin global tidak perlu dicek
+	adminRole := model.Roles{ID: uuid.New(), Name: "admin"}
+	lecturerRole := model.Roles{ID: uuid.New(), Name: "lecturer"}
+	user := &model.Users{ID: uuid.New(), Username: "kaprodi", Email: "kaprodi@uas.test", IsActive: true, RoleID: lecturerRole.ID}
+	db, mock := newMockDB(t)
+	rbac := newCachedRBACService(user.ID, &model.PermissionState{
+		IsActive: true, RoleNames: []string{"lecturer", "admin"}, AdminScope: []string{"Informatika"},
+	})
+	userService := service.NewUserService(repository.NewUserRepository(db), nil, nil, rbac)
+
+	mock.ExpectQuery("FROM users\\s+WHERE id = \\$1").WithArgs(user.ID).WillReturnRows(userRow(user))
+	mock.ExpectExec("DELETE FROM user_roles").WithArgs(user.ID, adminRole.ID).WillReturnError(assert.AnError)
+
+	// Act
+	_, err := userService.RemoveRole(user.ID, adminRole.ID)
+
+	// Assert: guard dilewati, yang gagal adalah DELETE-nya
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, service.ErrLastAdmin)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// roleCacheFixture - Cache berisi pemegang role utama, pemegang role tambahan, dan user lain
+type roleCacheFixture struct {
+	roleID, primaryHolder, secondaryHolder, unrelated uuid.UUID
+	rbac                                              *service.RBACService
+}
+
+func newRoleCacheFixture() roleCacheFixture {
+	f := roleCacheFixture{roleID: uuid.New(), primaryHolder: uuid.New(), secondaryHolder: uuid.New(), unrelated: uuid.New()}
+	otherRoleID := uuid.New()
+
+	f.rbac = &service.RBACService{Cache: service.NewPermissionCache(time.Minute)}
+	f.rbac.Cache.Set(f.primaryHolder, &model.PermissionState{RoleID: f.roleID, RoleIDs: []uuid.UUID{f.roleID}, IsActive: true})
+	f.rbac.Cache.Set(f.secondaryHolder, &model.PermissionState{RoleID: otherRoleID, RoleIDs: []uuid.UUID{otherRoleID, f.roleID}, IsActive: true})
+	f.rbac.Cache.Set(f.unrelated, &model.PermissionState{RoleID: otherRoleID, RoleIDs: []uuid.UUID{otherRoleID}, IsActive: true})
+	return f
+}
+
+// assertRoleEvicted - Semua pemegang role (utama maupun tambahan) dikeluarkan dari cache, user lain tetap
+func (f roleCacheFixture) assertRoleEvicted(t *testing.T) {
+	_, cached := f.rbac.Cache.Get(f.primaryHolder)
+	assert.False(t, cached, "primary role holder must be evicted")
+	_, cached = f.rbac.Cache.Get(f.secondaryHolder)
+	assert.False(t, cached, "secondary role holder must be evicted")
+	_, cached = f.rbac.Cache.Get(f.unrelated)
+	assert.True(t, cached, "users without the role must stay cached")
+}
+
+// expectGetRole - Query RoleService.GetRole setelah attach/detach
+func expectGetRole(mock sqlmock.Sqlmock, role model.Roles) {
+	mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(role.ID).WillReturnRows(roleRows(role))
+	mock.ExpectQuery("INNER JOIN role_permissions").WithArgs(role.ID).WillReturnRows(permissionRows())
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM user_roles").WithArgs(role.ID).WillReturnRows(countRow(2))
+}
+
+func TestRoleService_InvalidatesPermissionCache(t *testing.T) {
+	perm := model.Permission{ID: uuid.New(), Name: "report.export", Resource: "report", Action: "export"}
+
+	testCases := []struct {
+		name string
+		act  func(s *service.RoleService, mock sqlmock.Sqlmock, role model.Roles) error
+	}{
+		{
+			name: "Attach permission",
+			act: func(s *service.RoleService, mock sqlmock.Sqlmock, role model.Roles) error {
+				mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(role.ID).WillReturnRows(roleRows(role))
+				mock.ExpectQuery("FROM permissions\\s+WHERE id = \\$1").WithArgs(perm.ID).WillReturnRows(permissionRows(perm))
+				mock.ExpectExec("INSERT INTO role_permissions").WithArgs(role.ID, perm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectGetRole(mock, role)
+
+				_, err := s.AttachPermission(role.ID, perm.ID)
+				return err
+			},
+		},
+		{
+			name: "Detach permission",
+			act: func(s *service.RoleService, mock sqlmock.Sqlmock, role model.Roles) error {
+				mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(role.ID).WillReturnRows(roleRows(role))
+				mock.ExpectExec("DELETE FROM role_permissions").WithArgs(role.ID, perm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectGetRole(mock, role)
+
+				_, err := s.DetachPermission(role.ID, perm.ID)
+				return err
+			},
+		},
+		{
+			name: "Update permission",
+			act: func(s *service.RoleService, mock sqlmock.Sqlmock, role model.Roles) error {
+				mock.ExpectQuery("FROM permissions\\s+WHERE id = \\$1").WithArgs(perm.ID).WillReturnRows(permissionRows(perm))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE permissions").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE roles\\s+SET permission_version").WithArgs(perm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT role_id FROM role_permissions").WithArgs(perm.ID).WillReturnRows(idRows(role.ID))
+
+				_, err := s.UpdatePermission(perm.ID, &model.PermissionRequest{Description: "Export laporan"})
+				return err
+			},
+		},
+		{
+			name: "Delete role",
+			act: func(s *service.RoleService, mock sqlmock.Sqlmock, role model.Roles) error {
+				mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(role.ID).WillReturnRows(roleRows(role))
+				mock.ExpectExec("DELETE FROM roles").WithArgs(role.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+				return s.DeleteRole(role.ID)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			f := newRoleCacheFixture()
+			role := model.Roles{ID: f.roleID, Name: "reviewer"}
+			db, mock := newMockDB(t)
+			roleService := service.NewRoleService(repository.NewRBACRepository(db), f.rbac)
+
+			// Act
+			err := tc.act(roleService, mock, role)
+
+			// Assert
+			require.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			f.assertRoleEvicted(t)
+		})
+	}
+}
+
+func TestRoleService_DeleteRole_InUse(t *testing.T) {
+	// Arrange: DELETE tidak mengenai baris karena role masih dipakai user
+	f := newRoleCacheFixture()
+	role := model.Roles{ID: f.roleID, Name: "reviewer"}
+	db, mock := newMockDB(t)
+	roleService := service.NewRoleService(repository.NewRBACRepository(db), f.rbac)
+
+	mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(role.ID).WillReturnRows(roleRows(role))
+	mock.ExpectExec("DELETE FROM roles").WithArgs(role.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Act
+	err := roleService.DeleteRole(role.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrRoleInUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	_, cached := f.rbac.Cache.Get(f.primaryHolder)
+	assert.True(t, cached, "cache must be kept when the role was not deleted")
+}
+
+func TestRoleService_DeleteRole_ProtectsAdmin(t *testing.T) {
+	// Arrange
+	adminRole := model.Roles{ID: uuid.New(), Name: "admin"}
+	db, mock := newMockDB(t)
+	roleService := service.NewRoleService(repository.NewRBACRepository(db), service.NewRBACService(nil))
+
+	mock.ExpectQuery("FROM roles\\s+WHERE id = \\$1").WithArgs(adminRole.ID).WillReturnRows(roleRows(adminRole))
+
+	// Act
+	err := roleService.DeleteRole(adminRole.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrProtectedRole)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
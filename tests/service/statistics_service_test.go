@@ -273,7 +273,7 @@ func TestStatisticsService_GetAdminStatistics_Success(t *testing.T) {
 	mockRepo.On("GetStatisticsSummary", req).Return(summary, nil)
 
 	// Act
-	result, err := statsService.GetAdminStatistics(context.Background(), req)
+	result, err := statsService.GetAdminStatistics(context.Background(), nil, req)
 
 	// Assert
 	assert.NoError(t, err)
@@ -328,7 +328,7 @@ func TestStatisticsService_GetAchievementTrends_Student_Success(t *testing.T) {
 	mockRepo.On("GetAchievementTrends", mock.AnythingOfType("*service.StatisticsRequest"), 12).Return(trends, nil)
 
 	// Act
-	result, err := statsService.GetAchievementTrends(context.Background(), userID, "student", 12)
+	result, err := statsService.GetAchievementTrends(context.Background(), userID, "student", 12, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -378,7 +378,7 @@ func TestStatisticsService_GetAchievementTrends_Lecturer_Success(t *testing.T) {
 	mockRepo.On("GetAchievementTrends", mock.AnythingOfType("*service.StatisticsRequest"), 6).Return(trends, nil)
 
 	// Act
-	result, err := statsService.GetAchievementTrends(context.Background(), userID, "lecturer", 6)
+	result, err := statsService.GetAchievementTrends(context.Background(), userID, "lecturer", 6, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -400,7 +400,7 @@ func TestStatisticsService_GetAchievementTrends_InvalidRole(t *testing.T) {
 	userID := uuid.New()
 
 	// Act
-	result, err := statsService.GetAchievementTrends(context.Background(), userID, "invalid", 12)
+	result, err := statsService.GetAchievementTrends(context.Background(), userID, "invalid", 12, nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -432,7 +432,7 @@ func TestStatisticsService_GetAchievementTrends_DefaultMonths(t *testing.T) {
 	mockRepo.On("GetAchievementTrends", mock.AnythingOfType("*service.StatisticsRequest"), 12).Return(trends, nil)
 
 	// Act - Test with 0 months (should default to 12)
-	result, err := statsService.GetAchievementTrends(context.Background(), userID, "student", 0)
+	result, err := statsService.GetAchievementTrends(context.Background(), userID, "student", 0, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -464,7 +464,7 @@ func TestStatisticsService_GetAchievementTrends_MaxMonths(t *testing.T) {
 	mockRepo.On("GetAchievementTrends", mock.AnythingOfType("*service.StatisticsRequest"), 24).Return(trends, nil)
 
 	// Act - Test with 30 months (should cap at 24)
-	result, err := statsService.GetAchievementTrends(context.Background(), userID, "student", 30)
+	result, err := statsService.GetAchievementTrends(context.Background(), userID, "student", 30, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -472,4 +472,4 @@ func TestStatisticsService_GetAchievementTrends_MaxMonths(t *testing.T) {
 	assert.Equal(t, 24, result.Period)
 
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"UAS_BACKEND/domain/service"
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Secret dari test vector RFC 6238 Appendix B ("12345678901234567890", SHA1)
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// Arrange: 6 digit terakhir dari kode 8 digit di RFC
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		// Act
+		code, err := service.TOTPCode(rfcSecret, service.TOTPStep(time.Unix(unix, 0)))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateTOTP_AcceptsAdjacentStepOnly(t *testing.T) {
+	// Arrange
+	now := time.Unix(1234567890, 0)
+	step := service.TOTPStep(now)
+	previous, _ := service.TOTPCode(rfcSecret, step-1)
+	tooOld, _ := service.TOTPCode(rfcSecret, step-2)
+
+	// Act
+	matched, ok := service.ValidateTOTP(rfcSecret, previous, now)
+	_, oldOK := service.ValidateTOTP(rfcSecret, tooOld, now)
+	_, badOK := service.ValidateTOTP(rfcSecret, "12345", now)
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, step-1, matched)
+	assert.False(t, oldOK)
+	assert.False(t, badOK)
+}
+
+func TestGenerateTOTPSecret_RoundTrip(t *testing.T) {
+	// Arrange
+	secret, err := service.GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Now()
+
+	// Act
+	code, err := service.TOTPCode(secret, service.TOTPStep(now))
+	require.NoError(t, err)
+	_, ok := service.ValidateTOTP(secret, code, now)
+
+	// Assert
+	assert.Len(t, secret, 32)
+	assert.True(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	// Act
+	uri := service.TOTPProvisioningURI("UAS Backend", "admin@example.com", rfcSecret)
+
+	// Assert
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/UAS%20Backend:admin@example.com?"))
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	assert.Equal(t, "UAS Backend", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+	assert.Equal(t, "30", parsed.Query().Get("period"))
+}
@@ -123,7 +123,7 @@ func TestUserService_CreateUser_ValidationErrors(t *testing.T) {
 				RoleID:   uuid.New(),
 				IsActive: true,
 			},
-			expectedErr: "password must be at least 6 characters",
+			expectedErr: "must be at least 10 characters",
 		},
 		{
 			name: "Empty full name",
@@ -475,4 +475,4 @@ func TestUserService_GetAllUsers_Success(t *testing.T) {
 	assert.Equal(t, "user2", result[1].User.Username)
 
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}